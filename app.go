@@ -11,16 +11,22 @@ package fiber
 
 import (
 	"bufio"
+	"context"
+	"encoding/xml"
 	"fmt"
 	"net"
 	"net/http"
+	"net/http/cookiejar"
 	"net/http/httputil"
+	"net/url"
 	"os"
 	"reflect"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gofiber/fiber/v2/internal/colorable"
@@ -70,6 +76,35 @@ type ErrorHandler = func(*Ctx, error) error
 type Error struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
+	// Problem is rendered as application/problem+json by the DefaultErrorHandler
+	Problem *ProblemDetails `json:"problem,omitempty"`
+}
+
+// ProblemDetails represents an error response body as defined in RFC 7807.
+type ProblemDetails struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Status   int    `json:"status,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+}
+
+// ValidationError maps the invalid fields of a request to their error messages.
+// The DefaultErrorHandler renders it as 422 Unprocessable Entity with the
+// fields as JSON, e.g. {"errors":{"name":"failed on the 'required' tag"}}.
+type ValidationError map[string]string
+
+// Error returns the invalid fields and their messages sorted by field.
+func (e ValidationError) Error() string {
+	fields := make([]string, 0, len(e))
+	for field := range e {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for i, field := range fields {
+		fields[i] = field + ": " + e[field]
+	}
+	return "validation failed: " + strings.Join(fields, ", ")
 }
 
 // App denotes the Fiber application.
@@ -83,17 +118,31 @@ type App struct {
 	routesCount int
 	// Amount of registered handlers
 	handlerCount int
+	// Routes of the latest registration, used to set metadata
+	latestRoutes []*Route
 	// Ctx pool
 	pool sync.Pool
 	// Fasthttp server
 	server *fasthttp.Server
+	// Amount of requests being handled, used atomically
+	inFlight int32
+	// Set to 1 when the server is shutting down, used atomically
+	draining int32
+	// Child processes of the prefork master, protected by mutex
+	childs []*os.Process
 	// App config
 	config Config
+	// Parent app and prefix, if the app is mounted as sub-app
+	parent      *App
+	mountPrefix string
+	// Sub-apps mounted on the app
+	mounts []*App
 }
 
 // Config is a struct holding the server settings.
 type Config struct {
 	// When set to true, this will spawn multiple Go processes listening on the same port.
+	// A random port like ":0" can't be used, every child process would listen on another port.
 	//
 	// Default: false
 	Prefork bool `json:"prefork"`
@@ -112,6 +161,7 @@ type Config struct {
 	// When set to true, enables case sensitive routing.
 	// E.g. "/FoO" and "/foo" are treated as different routes.
 	// By default this is disabled and both "/FoO" and "/foo" will execute the same handler.
+	// Route parameters always keep the casing of the request path.
 	//
 	// Default: false
 	CaseSensitive bool `json:"case_sensitive"`
@@ -141,6 +191,31 @@ type Config struct {
 	// Default: 4 * 1024 * 1024
 	BodyLimit int `json:"body_limit"`
 
+	// Max size of a request body after decoding its Content-Encoding in BodyParser,
+	// exceeding it returns ErrRequestEntityTooLarge.
+	//
+	// Default: BodyLimit
+	DecompressedBodyLimit int `json:"decompressed_body_limit"`
+
+	// EnableRequestDecompression decodes request bodies with a deflate, br or zstd
+	// Content-Encoding in BodyParser, gzip bodies are always decoded.
+	//
+	// Default: false
+	EnableRequestDecompression bool `json:"enable_request_decompression"`
+
+	// Maximum number of files in a multipart form, exceeding it returns ErrRequestEntityTooLarge
+	// from c.MultipartForm, c.FormFile, c.SaveFile and c.BodyParser before the form is parsed.
+	//
+	// Default: 0 (unlimited)
+	MultipartFilesLimit int `json:"multipart_files_limit"`
+
+	// Maximum total size in bytes of the files in a multipart form, exceeding it returns
+	// ErrRequestEntityTooLarge from c.MultipartForm, c.FormFile, c.SaveFile and c.BodyParser.
+	// The size of the whole request is limited by BodyLimit.
+	//
+	// Default: 0 (unlimited)
+	MultipartSizeLimit int `json:"multipart_size_limit"`
+
 	// Maximum number of concurrent connections.
 	//
 	// Default: 256 * 1024
@@ -174,6 +249,8 @@ type Config struct {
 	// This also limits the maximum header size.
 	// Increase this buffer if your clients send multi-KB RequestURIs
 	// and/or multi-KB headers (for example, BIG cookies).
+	// Larger headers are passed to the ErrorHandler as ErrRequestHeaderFieldsTooLarge,
+	// the DefaultErrorHandler responds with 431 Request Header Fields Too Large.
 	//
 	// Default: 4096
 	ReadBufferSize int `json:"read_buffer_size"`
@@ -197,6 +274,22 @@ type Config struct {
 	// Default: ""
 	ProxyHeader string `json:"proxy_header"`
 
+	// When set to true, c.Hostname(), c.BaseURL() and c.Subdomains() use the first host
+	// of the X-Forwarded-Host header if present, instead of the Host header.
+	// NOTE: only enable it behind a trusted proxy that sets the header, clients can spoof it.
+	//
+	// Default: false
+	EnableForwardedHost bool `json:"enable_forwarded_host"`
+
+	// EnableProxyProtocol decodes PROXY protocol v1 and v2 headers sent by load
+	// balancers like HAProxy or AWS NLB, so c.IP() returns the real client address.
+	// Connections without a PROXY header are served as usual.
+	// NOTE: only enable it if the server is exclusively reachable through the proxy.
+	// app.Listener refuses tls listeners with it, pass a plain TCP listener instead.
+	//
+	// Default: false
+	EnableProxyProtocol bool `json:"enable_proxy_protocol"`
+
 	// GETOnly rejects all non-GET requests if set to true.
 	// This option is useful as anti-DoS protection for servers
 	// accepting only GET requests. The request size is limited
@@ -210,6 +303,17 @@ type Config struct {
 	// Default: DefaultErrorHandler
 	ErrorHandler ErrorHandler `json:"-"`
 
+	// NotFoundHandler is executed when no route matches the request,
+	// after the matching middleware. Returned errors are passed to the ErrorHandler.
+	//
+	// Default: responds with 404 "Cannot <method> <path>"
+	NotFoundHandler Handler `json:"-"`
+
+	// XMLEncoder is used by c.XML to marshal the response body.
+	//
+	// Default: xml.Marshal
+	XMLEncoder func(v interface{}) ([]byte, error) `json:"-"`
+
 	// When set to true, disables keep-alive connections.
 	// The server will close incoming connections after sending the first response to client.
 	//
@@ -226,6 +330,12 @@ type Config struct {
 	// Default: false
 	DisableDefaultContentType bool `json:"disable_default_content_type"`
 
+	// DefaultContentType is used for responses of handlers without Content-Type,
+	// e.g. "application/json" for APIs. Error responses stay plain text.
+	//
+	// Default: "text/plain; charset=utf-8"
+	DefaultContentType string `json:"default_content_type"`
+
 	// When set to true, disables header normalization.
 	// By default all header names are normalized: conteNT-tYPE -> Content-Type.
 	//
@@ -237,6 +347,35 @@ type Config struct {
 	// Default: false
 	DisableStartupMessage bool `json:"disable_startup_message"`
 
+	// OnListen is called with the address of the listener of Listen and Listener
+	// before serving requests, e.g. to learn the port chosen for ":0".
+	// It is not called with Prefork.
+	//
+	// Default: nil
+	OnListen func(addr net.Addr) `json:"-"`
+
+	// When set to true, GET routes are not registered for HEAD requests.
+	// By default a HEAD request is answered by the matching GET handler,
+	// the headers are kept and the body is discarded.
+	//
+	// Default: false
+	DisableHeadAutoRegister bool `json:"disable_head_auto_register"`
+
+	// When set to true, panics in handlers are not recovered by the router.
+	// By default a panic is passed as error to the ErrorHandler, even
+	// if the recover middleware is not used.
+	//
+	// Default: false
+	DisablePanicRecovery bool `json:"disable_panic_recovery"`
+
+	// When set to true, the user context is not canceled when the client
+	// closes the connection. Disconnects are detected for plain TCP connections
+	// on unix systems, the connection of a request is checked every 100 milliseconds
+	// in a goroutine started by the first call of c.UserContext() or c.SetUserContext().
+	//
+	// Default: false
+	DisableClientDisconnectCancel bool `json:"disable_client_disconnect_cancel"`
+
 	// Aggressively reduces memory usage at the cost of higher CPU usage
 	// if set to true.
 	//
@@ -295,8 +434,17 @@ const (
 var DefaultErrorHandler = func(c *Ctx, err error) error {
 	code := StatusInternalServerError
 	if e, ok := err.(*Error); ok {
+		if e.Problem != nil {
+			return c.Problem(e.Code, *e.Problem)
+		}
 		code = e.Code
 	}
+	if e, ok := err.(ValidationError); ok {
+		code = StatusUnprocessableEntity
+		if c.Accepts(MIMEApplicationJSON, MIMETextPlain) == MIMEApplicationJSON {
+			return c.Status(code).JSON(Map{"errors": e})
+		}
+	}
 	c.Set(HeaderContentType, MIMETextPlainCharsetUTF8)
 	return c.Status(code).SendString(err.Error())
 }
@@ -334,10 +482,16 @@ func New(config ...Config) *App {
 		}
 	}
 
+	// Zero values are replaced by the defaults below, negative values are invalid
+	validateConfig(app.config)
+
 	// Override default values
 	if app.config.BodyLimit <= 0 {
 		app.config.BodyLimit = DefaultBodyLimit
 	}
+	if app.config.DecompressedBodyLimit <= 0 {
+		app.config.DecompressedBodyLimit = app.config.BodyLimit
+	}
 	if app.config.Concurrency <= 0 {
 		app.config.Concurrency = DefaultConcurrency
 	}
@@ -356,26 +510,73 @@ func New(config ...Config) *App {
 	if app.config.ErrorHandler == nil {
 		app.config.ErrorHandler = DefaultErrorHandler
 	}
+	if app.config.XMLEncoder == nil {
+		app.config.XMLEncoder = xml.Marshal
+	}
 	// Init app
 	app.init()
 	// Return app
 	return app
 }
 
-// Mount attaches another app instance as a subrouter along a routing path.
+// validateConfig panics with a descriptive message if a size, limit
+// or timeout of the config is negative
+func validateConfig(config Config) {
+	sizes := []struct {
+		name  string
+		value int
+	}{
+		{"BodyLimit", config.BodyLimit},
+		{"DecompressedBodyLimit", config.DecompressedBodyLimit},
+		{"Concurrency", config.Concurrency},
+		{"ReadBufferSize", config.ReadBufferSize},
+		{"WriteBufferSize", config.WriteBufferSize},
+		{"MultipartFilesLimit", config.MultipartFilesLimit},
+		{"MultipartSizeLimit", config.MultipartSizeLimit},
+	}
+	for _, size := range sizes {
+		if size.value < 0 {
+			panic(fmt.Sprintf("config: %s must not be negative, got %d", size.name, size.value))
+		}
+	}
+	timeouts := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"ReadTimeout", config.ReadTimeout},
+		{"WriteTimeout", config.WriteTimeout},
+		{"IdleTimeout", config.IdleTimeout},
+	}
+	for _, timeout := range timeouts {
+		if timeout.value < 0 {
+			panic(fmt.Sprintf("config: %s must not be negative, got %v", timeout.name, timeout.value))
+		}
+	}
+}
+
+// Mount attaches another app instance as a sub-app along a routing path.
 // It's very useful to split up a large API as many independent routers and
 // compose them as a single service using Mount.
+//
+// Requests matching the prefix are forwarded to the sub-app, which handles them
+// with its own middleware and error handler. Inside the sub-app c.Path() returns
+// the path without the prefix, while c.OriginalURL() contains the full URL.
+// Requests without a matching route in the sub-app continue in the routes
+// registered after Mount, unless the sub-app has a NotFoundHandler.
 func (app *App) Mount(prefix string, fiber *App) Router {
-	stack := fiber.Stack()
-	for m := range stack {
-		for r := range stack[m] {
-			route := app.copyRoute(stack[m][r])
-			app.addRoute(route.Method, app.addPrefixToRoute(prefix, route))
-		}
-	}
+	app.register(methodUse, prefix, app.mount(prefix, fiber))
 	return app
 }
 
+// MountPath returns the path pattern the app was mounted on, including the
+// prefixes of all parent apps. It returns an empty string for the main app.
+func (app *App) MountPath() string {
+	if app.parent == nil {
+		return ""
+	}
+	return getGroupPath(app.parent.MountPath(), app.mountPrefix)
+}
+
 // Use registers a middleware route that will match requests
 // with the provided prefix (which is optional and defaults to "/").
 //
@@ -388,30 +589,40 @@ func (app *App) Mount(prefix string, fiber *App) Router {
 //  app.Use("/api", handler, func(c *fiber.Ctx) error {
 //       return c.Next()
 //  })
+//  app.Use([]string{"/api", "/admin"}, func(c *fiber.Ctx) error {
+//       return c.Next()
+//  })
 //
 // This method will match all HTTP verbs: GET, POST, PUT, HEAD etc...
 func (app *App) Use(args ...interface{}) Router {
-	var prefix string
+	var prefixes = []string{""}
 	var handlers []Handler
 
 	for i := 0; i < len(args); i++ {
 		switch arg := args[i].(type) {
 		case string:
-			prefix = arg
+			prefixes = []string{arg}
+		case []string:
+			prefixes = arg
 		case Handler:
 			handlers = append(handlers, arg)
 		default:
 			panic(fmt.Sprintf("use: invalid handler %v\n", reflect.TypeOf(arg)))
 		}
 	}
-	app.register(methodUse, prefix, handlers...)
+	var routes []*Route
+	for _, prefix := range prefixes {
+		app.register(methodUse, prefix, handlers...)
+		routes = append(routes, app.latestRoutes...)
+	}
+	app.latestRoutes = routes
 	return app
 }
 
 // Get registers a route for GET methods that requests a representation
 // of the specified resource. Requests using GET should only retrieve data.
 func (app *App) Get(path string, handlers ...Handler) Router {
-	return app.Add(MethodHead, path, handlers...).Add(MethodGet, path, handlers...)
+	return app.Add(MethodGet, path, handlers...)
 }
 
 // Head registers a route for HEAD methods that asks for a response identical
@@ -473,9 +684,12 @@ func (app *App) Static(prefix, root string, config ...Static) Router {
 
 // All will register the handler on all HTTP methods
 func (app *App) All(path string, handlers ...Handler) Router {
+	var routes []*Route
 	for _, method := range intMethod {
 		_ = app.Add(method, path, handlers...)
+		routes = append(routes, app.latestRoutes...)
 	}
+	app.latestRoutes = routes
 	return app
 }
 
@@ -489,6 +703,130 @@ func (app *App) Group(prefix string, handlers ...Handler) Router {
 	return &Group{prefix: prefix, app: app}
 }
 
+// ErrorHandler sets the error handler of the app, groups can override it
+// for their routes with their own ErrorHandler.
+func (app *App) ErrorHandler(handler ErrorHandler) Router {
+	app.config.ErrorHandler = handler
+	return app
+}
+
+// Set stores metadata on the routes of the latest registration,
+// the metadata is available in handlers with c.Route().Meta.
+//  app.Get("/admin", handler).Set("scope", "admin")
+func (app *App) Set(key string, value interface{}) Router {
+	for _, route := range app.latestRoutes {
+		if route.Meta == nil {
+			route.Meta = make(map[string]interface{})
+		}
+		route.Meta[key] = value
+	}
+	return app
+}
+
+// Name sets the name of the routes of the latest registration.
+//  app.Get("/users/:id", handler).Name("user")
+func (app *App) Name(name string) Router {
+	for _, route := range app.latestRoutes {
+		route.Name = name
+	}
+	return app
+}
+
+// Timeout limits the routes of the latest registration to the timeout,
+// the user context of the request is canceled when it passes and
+// ErrRequestTimeout is returned if the handlers return after it.
+//  app.Get("/report", handler).Timeout(2 * time.Second)
+func (app *App) Timeout(timeout time.Duration) Router {
+	app.wrapLatestRoutes(func(c *Ctx) error {
+		parent := c.UserContext()
+		c.SetDeadline(time.Now().Add(timeout))
+		ctx := c.userContext
+		err := c.Next()
+		// Handlers outside of the route are not limited by the timeout
+		c.userContext = parent
+		if ctx.Err() == context.DeadlineExceeded {
+			return ErrRequestTimeout
+		}
+		return err
+	})
+	return app
+}
+
+// Limit restricts the routes of the latest registration to max requests per
+// duration of every client IP, independent of the limiter middleware.
+// ErrTooManyRequests is returned with a Retry-After header if a client exceeds it.
+//  app.Post("/login", handler).Limit(10, time.Minute)
+func (app *App) Limit(max int, duration time.Duration) Router {
+	app.wrapLatestRoutes(newRouteLimiter(max, duration).handler)
+	return app
+}
+
+// wrapLatestRoutes runs the handler before the handlers of the latest registration,
+// the handlers of earlier registrations merged into the same route are not wrapped
+func (app *App) wrapLatestRoutes(handler Handler) {
+	app.mutex.Lock()
+	for _, route := range app.latestRoutes {
+		handlers := make([]Handler, 0, len(route.Handlers)+1)
+		handlers = append(handlers, route.Handlers[:route.latest]...)
+		handlers = append(handlers, handler)
+		route.Handlers = append(handlers, route.Handlers[route.latest:]...)
+	}
+	app.handlerCount++
+	app.mutex.Unlock()
+}
+
+// routeLimiter counts the requests of every client IP in a fixed window, see App.Limit
+type routeLimiter struct {
+	mutex    sync.Mutex
+	max      int
+	duration time.Duration
+	windows  map[string]*routeWindow
+	gc       time.Time
+}
+
+type routeWindow struct {
+	hits  int
+	reset time.Time
+}
+
+func newRouteLimiter(max int, duration time.Duration) *routeLimiter {
+	return &routeLimiter{
+		max:      max,
+		duration: duration,
+		windows:  make(map[string]*routeWindow),
+	}
+}
+
+func (l *routeLimiter) handler(c *Ctx) error {
+	now := time.Now()
+	l.mutex.Lock()
+	// Delete the windows that passed once per duration
+	if now.After(l.gc) {
+		for ip, window := range l.windows {
+			if now.After(window.reset) {
+				delete(l.windows, ip)
+			}
+		}
+		l.gc = now.Add(l.duration)
+	}
+	ip := c.IP()
+	window := l.windows[ip]
+	if window == nil || now.After(window.reset) {
+		window = &routeWindow{reset: now.Add(l.duration)}
+		l.windows[utils.ImmutableString(ip)] = window
+	}
+	window.hits++
+	hits, reset := window.hits, window.reset
+	l.mutex.Unlock()
+
+	if hits > l.max {
+		retryAfter := (reset.Sub(now) + time.Second - 1) / time.Second
+		c.Set(HeaderRetryAfter, strconv.FormatInt(int64(retryAfter), 10))
+		return ErrTooManyRequests
+	}
+	return c.Next()
+}
+
 // Error makes it compatible with the `error` interface.
 func (e *Error) Error() string {
 	return e.Message
@@ -507,24 +845,96 @@ func NewError(code int, message ...string) *Error {
 	return e
 }
 
+// NewProblem creates a new Error instance that is rendered as problem details
+//  return fiber.NewProblem(fiber.StatusNotFound, fiber.ProblemDetails{
+//    Type:   "https://example.com/probs/unknown-user",
+//    Detail: "user 42 does not exist",
+//  })
+func NewProblem(code int, problem ProblemDetails) *Error {
+	e := NewError(code)
+	if problem.Detail != "" {
+		e.Message = problem.Detail
+	} else if problem.Title != "" {
+		e.Message = problem.Title
+	}
+	e.Problem = &problem
+	return e
+}
+
+// fieldError is implemented by the field errors of go-playground/validator
+type fieldError interface {
+	Field() string
+	Tag() string
+}
+
+// NewValidationError converts the errors of go-playground/validator to a
+// ValidationError, the message of a field names the failed tag.
+// Other errors are returned unchanged, nil returns nil.
+//  if err := validate.Struct(user); err != nil {
+//    return fiber.NewValidationError(err)
+//  }
+func NewValidationError(err error) error {
+	if err == nil {
+		return nil
+	}
+	// validator.ValidationErrors is a slice of validator.FieldError
+	var fieldErrors []fieldError
+	if fe, ok := err.(fieldError); ok {
+		fieldErrors = append(fieldErrors, fe)
+	} else if v := reflect.ValueOf(err); v.Kind() == reflect.Slice {
+		for i := 0; i < v.Len(); i++ {
+			fe, ok := v.Index(i).Interface().(fieldError)
+			if !ok {
+				return err
+			}
+			fieldErrors = append(fieldErrors, fe)
+		}
+	}
+	if len(fieldErrors) == 0 {
+		return err
+	}
+	e := make(ValidationError, len(fieldErrors))
+	for _, fe := range fieldErrors {
+		e[fe.Field()] = "failed on the '" + fe.Tag() + "' tag"
+	}
+	return e
+}
+
 // Listener can be used to pass a custom listener.
+// With EnableProxyProtocol the listener must be a plain TCP listener,
+// a tls listener is refused because load balancers send the PROXY header
+// before the tls handshake.
 func (app *App) Listener(ln net.Listener) error {
 	// Prefork is supported for custom listeners
 	if app.config.Prefork {
 		addr, tls := lnMetadata(ln)
 		return app.prefork(addr, tls)
 	}
+	// The PROXY header can't be read inside the tls stream
+	if app.config.EnableProxyProtocol && isTLSListener(ln) {
+		return errProxyProtocolTLS
+	}
 
 	// Print startup message
 	if !app.config.DisableStartupMessage {
 		app.startupMessage(ln.Addr().String(), false, "")
 	}
+	// Report the bound address
+	if app.config.OnListen != nil {
+		app.config.OnListen(ln.Addr())
+	}
+
+	// Decode PROXY protocol headers
+	if app.config.EnableProxyProtocol {
+		ln = newProxyProtocolListener(ln)
+	}
 
 	// TODO: Detect TLS
 	return app.server.Serve(ln)
 }
 
 // Listen serves HTTP requests from the given addr.
+// The port chosen for ":0" is reported to the OnListen config.
 //
 //  app.Listen(":8080")
 //  app.Listen("127.0.0.1:8080")
@@ -542,6 +952,14 @@ func (app *App) Listen(addr string) error {
 	if !app.config.DisableStartupMessage {
 		app.startupMessage(ln.Addr().String(), false, "")
 	}
+	// Report the bound address
+	if app.config.OnListen != nil {
+		app.config.OnListen(ln.Addr())
+	}
+	// Decode PROXY protocol headers
+	if app.config.EnableProxyProtocol {
+		ln = newProxyProtocolListener(ln)
+	}
 	// Start listening
 	return app.server.Serve(ln)
 }
@@ -556,9 +974,53 @@ func (app *App) Handler() fasthttp.RequestHandler {
 	return app.handler
 }
 
-// Stack returns the raw router stack.
+// Stack returns the raw router stack. The routes of mounted sub-apps are
+// appended as copies with the paths including the mount prefix.
 func (app *App) Stack() [][]*Route {
-	return app.stack
+	if len(app.mounts) == 0 {
+		return app.stack
+	}
+	stack := make([][]*Route, len(app.stack))
+	for m := range app.stack {
+		stack[m] = append(stack[m], app.stack[m]...)
+	}
+	for _, sub := range app.mounts {
+		for m, routes := range sub.Stack() {
+			for _, route := range routes {
+				mounted := *route
+				mounted.Path = getGroupPath(sub.mountPrefix, route.Path)
+				stack[m] = append(stack[m], &mounted)
+			}
+		}
+	}
+	return stack
+}
+
+// GetRoutes returns the registered routes of all methods, without middleware
+// registered with Use and the HEAD routes registered for GET routes.
+// Changing the returned routes does not affect routing.
+func (app *App) GetRoutes() []Route {
+	var routes []Route
+	stack := app.Stack()
+	for m := range stack {
+		for _, route := range stack[m] {
+			if route.use || route.autoHead {
+				continue
+			}
+			routes = append(routes, *route)
+		}
+	}
+	return routes
+}
+
+// HandlersCount returns the amount of registered handlers,
+// including the handlers of mounted sub-apps.
+func (app *App) HandlersCount() int {
+	count := app.handlerCount
+	for _, sub := range app.mounts {
+		count += sub.HandlersCount()
+	}
+	return count
 }
 
 // Shutdown gracefully shuts down the server without interrupting any active connections.
@@ -567,15 +1029,34 @@ func (app *App) Stack() [][]*Route {
 // Make sure the program doesn't exit and waits instead for Shutdown to return.
 //
 // Shutdown does not close keepalive connections so its recommended to set ReadTimeout to something else than 0.
+//
+// With Prefork the master process sends SIGTERM to the child processes, which shut down
+// gracefully, and Listen returns once all childs exited. On windows the childs are killed.
 func (app *App) Shutdown() error {
 	app.mutex.Lock()
 	defer app.mutex.Unlock()
 	if app.server == nil {
 		return fmt.Errorf("shutdown: server is not running")
 	}
+	atomic.StoreInt32(&app.draining, 1)
+	// The prefork master shuts down its child processes
+	for _, proc := range app.childs {
+		signalShutdown(proc)
+	}
 	return app.server.Shutdown()
 }
 
+// InFlight returns the amount of requests that are currently being handled.
+func (app *App) InFlight() int {
+	return int(atomic.LoadInt32(&app.inFlight))
+}
+
+// Draining reports whether the app is shutting down and drains the
+// requests in flight.
+func (app *App) Draining() bool {
+	return atomic.LoadInt32(&app.draining) == 1
+}
+
 // Server returns the underlying fasthttp server
 func (app *App) Server() *fasthttp.Server {
 	return app.server
@@ -583,6 +1064,8 @@ func (app *App) Server() *fasthttp.Server {
 
 // Test is used for internal debugging by passing a *http.Request.
 // Timeout is optional and defaults to 1s, -1 will disable it completely.
+// The RemoteAddr of the request, e.g. "1.2.3.4:5678", is used as the remote
+// address of the connection. The default of httptest.NewRequest is ignored.
 func (app *App) Test(req *http.Request, msTimeout ...int) (resp *http.Response, err error) {
 	// Set timeout
 	timeout := 1000
@@ -603,6 +1086,11 @@ func (app *App) Test(req *http.Request, msTimeout ...int) (resp *http.Response,
 
 	// Create test connection
 	conn := new(testConn)
+	if req.RemoteAddr != "" && req.RemoteAddr != testDefaultRemoteAddr {
+		if conn.remoteAddr, err = parseTestRemoteAddr(req.RemoteAddr); err != nil {
+			return nil, err
+		}
+	}
 
 	// Write raw http request
 	if _, err = conn.r.Write(dump); err != nil {
@@ -640,6 +1128,57 @@ func (app *App) Test(req *http.Request, msTimeout ...int) (resp *http.Response,
 	return http.ReadResponse(buffer, req)
 }
 
+// TestClient sends requests to the app like app.Test and keeps the cookies
+// of the responses in a cookie jar, so they are sent with the next requests,
+// e.g. to call a protected route after a login.
+// Secure cookies are only sent to https URLs, like httptest.NewRequest(MethodGet, "https://example.com/", nil).
+type TestClient struct {
+	// Jar stores the cookies of the responses.
+	Jar http.CookieJar
+
+	app *App
+}
+
+// NewTestClient creates a TestClient of the app with an empty cookie jar.
+func NewTestClient(app *App) *TestClient {
+	// cookiejar.New never returns an error without options
+	jar, _ := cookiejar.New(nil)
+	return &TestClient{Jar: jar, app: app}
+}
+
+// Test sends the request with the cookies of the jar to the app and stores
+// the cookies of the response in the jar.
+// Timeout is optional and defaults to 1s, -1 will disable it completely.
+func (tc *TestClient) Test(req *http.Request, msTimeout ...int) (*http.Response, error) {
+	u := testClientURL(req)
+	for _, cookie := range tc.Jar.Cookies(u) {
+		req.AddCookie(cookie)
+	}
+	resp, err := tc.app.Test(req, msTimeout...)
+	if err != nil {
+		return nil, err
+	}
+	if cookies := resp.Cookies(); len(cookies) > 0 {
+		tc.Jar.SetCookies(u, cookies)
+	}
+	return resp, nil
+}
+
+// testClientURL returns the absolute URL of the request for the cookie jar
+func testClientURL(req *http.Request) *url.URL {
+	u := *req.URL
+	if u.Host == "" {
+		u.Host = req.Host
+	}
+	if u.Scheme == "" {
+		u.Scheme = "http"
+		if req.TLS != nil {
+			u.Scheme = "https"
+		}
+	}
+	return &u
+}
+
 type disableLogger struct{}
 
 func (dl *disableLogger) Printf(format string, args ...interface{}) {
@@ -676,7 +1215,7 @@ func (app *App) init() *App {
 			} else {
 				err = ErrBadRequest
 			}
-			if catch := app.config.ErrorHandler(c, err); catch != nil {
+			if catch := app.errorHandler(c)(c, err); catch != nil {
 				_ = c.SendStatus(StatusInternalServerError)
 			}
 			app.ReleaseCtx(c)
@@ -688,7 +1227,8 @@ func (app *App) init() *App {
 	app.server.Name = app.config.ServerHeader
 	app.server.Concurrency = app.config.Concurrency
 	app.server.NoDefaultDate = app.config.DisableDefaultDate
-	app.server.NoDefaultContentType = app.config.DisableDefaultContentType
+	// The configured default content type is set by the handler
+	app.server.NoDefaultContentType = app.config.DisableDefaultContentType || app.config.DefaultContentType != ""
 	app.server.DisableHeaderNamesNormalizing = app.config.DisableHeaderNormalizing
 	app.server.DisableKeepalive = app.config.DisableKeepalive
 	app.server.MaxRequestBodySize = app.config.BodyLimit
@@ -5,6 +5,7 @@
 package fiber
 
 import (
+	"bufio"
 	"bytes"
 	"crypto/tls"
 	"errors"
@@ -17,6 +18,7 @@ import (
 	"reflect"
 	"regexp"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -137,6 +139,56 @@ func Test_App_ServerErrorHandler_SmallReadBuffer(t *testing.T) {
 	)
 }
 
+// go test -run Test_App_RequestHeaderFieldsTooLarge
+func Test_App_RequestHeaderFieldsTooLarge(t *testing.T) {
+	send := func(app *App, headerSize int) *http.Response {
+		app.Get("/", func(c *Ctx) error {
+			return c.SendString("ok")
+		})
+		ln, err := net.Listen("tcp4", "127.0.0.1:0")
+		utils.AssertEqual(t, nil, err)
+		go func() {
+			_ = app.Listener(ln)
+		}()
+		defer func() {
+			_ = app.Shutdown()
+		}()
+
+		conn, err := net.Dial("tcp4", ln.Addr().String())
+		utils.AssertEqual(t, nil, err)
+		defer conn.Close()
+		_, err = conn.Write([]byte("GET / HTTP/1.1\r\nHost: example.com\r\nX-Big: " + strings.Repeat("a", headerSize) + "\r\n\r\n"))
+		utils.AssertEqual(t, nil, err)
+		resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+		utils.AssertEqual(t, nil, err)
+		return resp
+	}
+
+	// the DefaultErrorHandler responds with 431
+	resp := send(New(Config{DisableStartupMessage: true}), 5000)
+	utils.AssertEqual(t, StatusRequestHeaderFieldsTooLarge, resp.StatusCode)
+	body, err := ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, utils.StatusMessage(StatusRequestHeaderFieldsTooLarge), string(body))
+
+	// the ErrorHandler can render the error
+	resp = send(New(Config{
+		DisableStartupMessage: true,
+		ErrorHandler: func(c *Ctx, err error) error {
+			utils.AssertEqual(t, ErrRequestHeaderFieldsTooLarge, err)
+			return c.Status(StatusRequestHeaderFieldsTooLarge).JSON(Map{"error": "headers too large"})
+		},
+	}), 5000)
+	utils.AssertEqual(t, StatusRequestHeaderFieldsTooLarge, resp.StatusCode)
+	body, err = ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, `{"error":"headers too large"}`, string(body))
+
+	// the ReadBufferSize limits the size of the headers
+	resp = send(New(Config{DisableStartupMessage: true, ReadBufferSize: 8192}), 5000)
+	utils.AssertEqual(t, StatusOK, resp.StatusCode)
+}
+
 func Test_App_Errors(t *testing.T) {
 	app := New(Config{
 		BodyLimit: 4,
@@ -234,6 +286,299 @@ func Test_App_ErrorHandler_RouteStack(t *testing.T) {
 	utils.AssertEqual(t, "1: USE error", string(body))
 }
 
+func Test_App_ErrorHandler_Next_Propagation(t *testing.T) {
+	errTeapot := NewError(StatusTeapot, "innermost error")
+	app := New(Config{
+		ErrorHandler: func(c *Ctx, err error) error {
+			utils.AssertEqual(t, "outer: group: innermost error", err.Error())
+			utils.AssertEqual(t, true, errors.Is(err, errTeapot))
+			return c.Status(StatusTeapot).SendString(err.Error())
+		},
+	})
+	app.Use(func(c *Ctx) error {
+		if err := c.Next(); err != nil {
+			return fmt.Errorf("outer: %w", err)
+		}
+		return nil
+	})
+	api := app.Group("/api", func(c *Ctx) error {
+		err := c.Next()
+		utils.AssertEqual(t, errTeapot, err)
+		return fmt.Errorf("group: %w", err)
+	})
+	api.Get("/test", func(c *Ctx) error {
+		return c.Next() // call the next handler of the route
+	}, func(c *Ctx) error {
+		return errTeapot
+	})
+
+	resp, err := app.Test(httptest.NewRequest(MethodGet, "/api/test", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusTeapot, resp.StatusCode, "Status code")
+
+	body, err := ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "outer: group: innermost error", string(body))
+}
+
+// go test -run Test_App_NotFoundHandler
+func Test_App_NotFoundHandler(t *testing.T) {
+	app := New(Config{
+		NotFoundHandler: func(c *Ctx) error {
+			return c.Status(StatusNotFound).JSON(Map{"path": c.Path()})
+		},
+	})
+	// middleware still applies to unmatched routes
+	app.Use(func(c *Ctx) error {
+		if c.Get(HeaderAuthorization) == "" {
+			return c.SendStatus(StatusUnauthorized)
+		}
+		return c.Next()
+	})
+	app.Get("/", func(c *Ctx) error {
+		return c.SendString("index")
+	})
+
+	req := httptest.NewRequest(MethodGet, "/unknown", nil)
+	resp, err := app.Test(req)
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusUnauthorized, resp.StatusCode, "Status code")
+
+	req.Header.Set(HeaderAuthorization, "secret")
+	resp, err = app.Test(req)
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusNotFound, resp.StatusCode, "Status code")
+	utils.AssertEqual(t, MIMEApplicationJSON, resp.Header.Get(HeaderContentType))
+	body, err := ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, `{"path":"/unknown"}`, string(body))
+
+	req = httptest.NewRequest(MethodGet, "/", nil)
+	req.Header.Set(HeaderAuthorization, "secret")
+	resp, err = app.Test(req)
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusOK, resp.StatusCode, "Status code")
+	body, err = ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "index", string(body))
+
+	// other methods of the path are still not allowed
+	req = httptest.NewRequest(MethodPost, "/", nil)
+	req.Header.Set(HeaderAuthorization, "secret")
+	resp, err = app.Test(req)
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusMethodNotAllowed, resp.StatusCode, "Status code")
+}
+
+// go test -run Test_App_DefaultContentType
+func Test_App_DefaultContentType(t *testing.T) {
+	app := New(Config{DefaultContentType: MIMEApplicationJSON})
+	app.Get("/", func(c *Ctx) error {
+		return c.Send([]byte(`{"hello":"world"}`))
+	})
+	app.Get("/html", func(c *Ctx) error {
+		c.Type("html")
+		return c.Send([]byte("<p>hello</p>"))
+	})
+
+	resp, err := app.Test(httptest.NewRequest(MethodGet, "/", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, MIMEApplicationJSON, resp.Header.Get(HeaderContentType))
+
+	// explicit types are kept
+	resp, err = app.Test(httptest.NewRequest(MethodGet, "/html", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, MIMETextHTML, resp.Header.Get(HeaderContentType))
+
+	// the built-in responses are plain text
+	resp, err = app.Test(httptest.NewRequest(MethodGet, "/missing", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusNotFound, resp.StatusCode, "Status code")
+	utils.AssertEqual(t, MIMETextPlainCharsetUTF8, resp.Header.Get(HeaderContentType))
+	resp, err = app.Test(httptest.NewRequest(MethodPost, "/", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusMethodNotAllowed, resp.StatusCode, "Status code")
+	utils.AssertEqual(t, MIMETextPlainCharsetUTF8, resp.Header.Get(HeaderContentType))
+
+	// the bodies of the error handler are not typed with the default
+	app.Get("/error", func(c *Ctx) error {
+		return errors.New("failed")
+	})
+	app.ErrorHandler(func(c *Ctx, err error) error {
+		return c.Status(StatusInternalServerError).SendString(err.Error())
+	})
+	resp, err = app.Test(httptest.NewRequest(MethodGet, "/error", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusInternalServerError, resp.StatusCode, "Status code")
+	utils.AssertEqual(t, MIMETextPlainCharsetUTF8, resp.Header.Get(HeaderContentType))
+	app.ErrorHandler(DefaultErrorHandler)
+	resp, err = app.Test(httptest.NewRequest(MethodGet, "/error", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, MIMETextPlainCharsetUTF8, resp.Header.Get(HeaderContentType))
+
+	// fasthttp default without config
+	app = New()
+	app.Get("/", func(c *Ctx) error {
+		return c.Send([]byte("hello"))
+	})
+	resp, err = app.Test(httptest.NewRequest(MethodGet, "/", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, MIMETextPlainCharsetUTF8, resp.Header.Get(HeaderContentType))
+}
+
+// go test -run Test_App_PanicRecovery
+func Test_App_PanicRecovery(t *testing.T) {
+	app := New()
+	app.Use(func(c *Ctx) error {
+		panic("middleware panic")
+	})
+	app.Get("/", testEmptyHandler)
+
+	resp, err := app.Test(httptest.NewRequest(MethodGet, "/", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusInternalServerError, resp.StatusCode, "Status code")
+
+	body, err := ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "middleware panic", string(body))
+
+	// panics with an error are passed to the error handler unchanged
+	errPanic := errors.New("error panic")
+	app = New(Config{
+		ErrorHandler: func(c *Ctx, err error) error {
+			utils.AssertEqual(t, errPanic, err)
+			return c.Status(StatusTeapot).SendString("recovered")
+		},
+	})
+	app.Get("/", func(c *Ctx) error {
+		panic(errPanic)
+	})
+
+	resp, err = app.Test(httptest.NewRequest(MethodGet, "/", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusTeapot, resp.StatusCode, "Status code")
+
+	// panics escape the router if recovery is disabled
+	app = New(Config{DisablePanicRecovery: true})
+	app.Get("/", func(c *Ctx) error {
+		panic("handler panic")
+	})
+
+	fctx := &fasthttp.RequestCtx{}
+	fctx.Request.Header.SetMethod(MethodGet)
+	fctx.Request.SetRequestURI("/")
+	defer func() {
+		utils.AssertEqual(t, "handler panic", recover())
+	}()
+	app.Handler()(fctx)
+	t.Fatal("panic expected")
+}
+
+// go test -run Test_App_Group_ErrorHandler
+func Test_App_Group_ErrorHandler(t *testing.T) {
+	app := New(Config{
+		ErrorHandler: func(c *Ctx, err error) error {
+			return c.Status(StatusTeapot).SendString("app: " + err.Error())
+		},
+	})
+	handler := func(c *Ctx) error {
+		return errors.New("failed")
+	}
+
+	v1 := app.Group("/v1")
+	v1.ErrorHandler(func(c *Ctx, err error) error {
+		return c.Status(StatusBadRequest).SendString("v1: " + err.Error())
+	})
+	v1.Get("/users", handler)
+
+	v2 := app.Group("/v2")
+	v2.Get("/users", handler)
+	v2.ErrorHandler(func(c *Ctx, err error) error {
+		return c.Status(StatusBadRequest).JSON(Map{"error": err.Error()})
+	})
+	// nested groups use the handler of the longest prefix
+	v2.Group("/admin").ErrorHandler(func(c *Ctx, err error) error {
+		return c.Status(StatusForbidden).SendString("admin: " + err.Error())
+	}).Get("/users", handler)
+
+	app.Get("/v10/users", handler)
+	app.Get("/users", handler)
+
+	testCases := []struct {
+		url    string
+		status int
+		body   string
+	}{
+		{"/v1/users", StatusBadRequest, "v1: failed"},
+		{"/V1/users/", StatusBadRequest, "v1: failed"},
+		{"/v2/users", StatusBadRequest, `{"error":"failed"}`},
+		{"/v2/admin/users", StatusForbidden, "admin: failed"},
+		{"/v1/unknown", StatusNotFound, "Cannot GET /v1/unknown"},
+		// fall back to the error handler of the app
+		{"/v10/users", StatusTeapot, "app: failed"},
+		{"/users", StatusTeapot, "app: failed"},
+	}
+	for _, tc := range testCases {
+		resp, err := app.Test(httptest.NewRequest(MethodGet, tc.url, nil))
+		utils.AssertEqual(t, nil, err, "app.Test(req)")
+		utils.AssertEqual(t, tc.status, resp.StatusCode, tc.url)
+		body, err := ioutil.ReadAll(resp.Body)
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, tc.body, string(body), tc.url)
+	}
+
+	// errors of not allowed methods are handled by the group
+	resp, err := app.Test(httptest.NewRequest(MethodPost, "/v1/users", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	body, err := ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "v1: Method Not Allowed", string(body))
+
+	// the handler is picked from the matched route, not from the request path
+	users := app.Group("/users/:id")
+	users.ErrorHandler(func(c *Ctx, err error) error {
+		return c.Status(StatusBadRequest).SendString("user " + c.Params("id") + ": " + err.Error())
+	})
+	users.Get("/profile", handler)
+	app.Get("/rewrite", func(c *Ctx) error {
+		c.Path("/v1/users")
+		return errors.New("rewritten")
+	})
+	sub := New()
+	sub.Group("/v3").ErrorHandler(func(c *Ctx, err error) error {
+		return c.Status(StatusBadRequest).SendString("v3: " + err.Error())
+	}).Get("/users", handler)
+	app.Mount("/sub", sub)
+
+	testCases = []struct {
+		url    string
+		status int
+		body   string
+	}{
+		{"/users/42/profile", StatusBadRequest, "user 42: failed"},
+		{"/rewrite", StatusTeapot, "app: rewritten"},
+		{"/sub/v3/users", StatusBadRequest, "v3: failed"},
+	}
+	for _, tc := range testCases {
+		resp, err := app.Test(httptest.NewRequest(MethodGet, tc.url, nil))
+		utils.AssertEqual(t, nil, err, "app.Test(req)")
+		utils.AssertEqual(t, tc.status, resp.StatusCode, tc.url)
+		body, err := ioutil.ReadAll(resp.Body)
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, tc.body, string(body), tc.url)
+	}
+
+	// the error handler of the app can be replaced
+	app.ErrorHandler(func(c *Ctx, err error) error {
+		return c.Status(StatusInternalServerError).SendString("replaced: " + err.Error())
+	})
+	resp, err = app.Test(httptest.NewRequest(MethodGet, "/users", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	body, err = ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "replaced: failed", string(body))
+}
+
 func Test_App_Nested_Params(t *testing.T) {
 	app := New()
 
@@ -272,6 +617,136 @@ func Test_App_Mount(t *testing.T) {
 	utils.AssertEqual(t, 200, resp.StatusCode, "Status code")
 }
 
+// go test -run Test_App_Mount_SubApp
+func Test_App_Mount_SubApp(t *testing.T) {
+	admin := New(Config{
+		ErrorHandler: func(c *Ctx, err error) error {
+			return c.Status(StatusTeapot).SendString("admin: " + err.Error())
+		},
+	})
+	admin.Use(func(c *Ctx) error {
+		c.Set("X-Admin", "true")
+		return c.Next()
+	})
+	admin.Get("/users", func(c *Ctx) error {
+		return c.SendString(c.Path() + " " + c.OriginalURL() + " " + c.App().MountPath())
+	})
+	admin.Get("/error", func(c *Ctx) error {
+		return ErrForbidden
+	})
+
+	app := New()
+	app.Use(func(c *Ctx) error {
+		err := c.Next()
+		// the parent app sees its own path again
+		c.Set("X-Path", c.Path())
+		return err
+	})
+	app.Mount("/admin", admin)
+	app.Get("/administrator", func(c *Ctx) error {
+		return c.SendString("parent")
+	})
+	app.Get("/admin/b", func(c *Ctx) error {
+		return c.SendString("parent " + c.Path())
+	})
+
+	resp, err := app.Test(httptest.NewRequest(MethodGet, "/admin/users?page=1", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusOK, resp.StatusCode, "Status code")
+	utils.AssertEqual(t, "true", resp.Header.Get("X-Admin"))
+	utils.AssertEqual(t, "/admin/users", resp.Header.Get("X-Path"))
+	body, err := ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "/users /admin/users?page=1 /admin", string(body))
+
+	resp, err = app.Test(httptest.NewRequest(MethodGet, "/admin/error", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusTeapot, resp.StatusCode, "Status code")
+	body, err = ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "admin: Forbidden", string(body))
+
+	resp, err = app.Test(httptest.NewRequest(MethodGet, "/admin/missing", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusNotFound, resp.StatusCode, "Status code")
+	body, err = ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "Cannot GET /admin/missing", string(body))
+
+	// requests without a route in the sub-app continue in the parent app
+	resp, err = app.Test(httptest.NewRequest(MethodGet, "/admin/b", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusOK, resp.StatusCode, "Status code")
+	utils.AssertEqual(t, "true", resp.Header.Get("X-Admin"))
+	body, err = ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "parent /admin/b", string(body))
+
+	// other methods of the sub-app routes are not allowed
+	resp, err = app.Test(httptest.NewRequest(MethodPost, "/admin/users", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusTeapot, resp.StatusCode, "Status code")
+	utils.AssertEqual(t, "GET, HEAD", resp.Header.Get(HeaderAllow))
+
+	resp, err = app.Test(httptest.NewRequest(MethodGet, "/administrator", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusOK, resp.StatusCode, "Status code")
+	utils.AssertEqual(t, "", resp.Header.Get("X-Admin"))
+
+	utils.AssertEqual(t, "", app.MountPath())
+	utils.AssertEqual(t, "/admin", admin.MountPath())
+}
+
+// go test -run Test_App_Mount_Routes
+func Test_App_Mount_Routes(t *testing.T) {
+	users := New()
+	users.Get("/:id", testEmptyHandler).Name("user")
+
+	api := New()
+	api.Use(testEmptyHandler)
+	api.Mount("/users", users)
+	api.Post("/items", testEmptyHandler)
+
+	app := New()
+	app.Mount("/api", api)
+	app.Get("/", testEmptyHandler)
+
+	var paths []string
+	for _, r := range app.GetRoutes() {
+		paths = append(paths, r.Method+" "+r.Path)
+	}
+	utils.AssertEqual(t, []string{"GET /", "GET /api/users/:id", "POST /api/items"}, paths)
+	// the middleware and mount routes are part of the stack
+	utils.AssertEqual(t, 5, len(app.Stack()[methodInt(MethodGet)]))
+	utils.AssertEqual(t, 2, len(app.stack[methodInt(MethodGet)]))
+	// the mount handlers of app and api are counted as well
+	utils.AssertEqual(t, 6, app.HandlersCount())
+}
+
+// go test -run Test_App_Mount_Nested
+func Test_App_Mount_Nested(t *testing.T) {
+	users := New()
+	users.Get("/:id", func(c *Ctx) error {
+		return c.SendString(c.Params("id") + " " + c.Path())
+	})
+
+	api := New()
+	api.Mount("/users", users)
+
+	app := New()
+	app.Group("/v1").Mount("/api", api)
+
+	utils.AssertEqual(t, "/v1/api", api.MountPath())
+	utils.AssertEqual(t, "/v1/api/users", users.MountPath())
+
+	resp, err := app.Test(httptest.NewRequest(MethodGet, "/v1/api/users/42", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusOK, resp.StatusCode, "Status code")
+	body, err := ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "42 /42", string(body))
+}
+
 func Test_App_Use_Params(t *testing.T) {
 	app := New()
 
@@ -381,6 +856,40 @@ func Test_App_Use_Params_Group(t *testing.T) {
 	utils.AssertEqual(t, 200, resp.StatusCode, "Status code")
 }
 
+// go test -run Test_App_Use_MultiplePrefix
+func Test_App_Use_MultiplePrefix(t *testing.T) {
+	app := New()
+
+	app.Use([]string{"/john", "/doe"}, func(c *Ctx) error {
+		c.Set("X-Middleware", "used")
+		return c.Next()
+	})
+
+	grp := app.Group("/test")
+	grp.Use([]string{"/foo", "/bar"}, func(c *Ctx) error {
+		c.Set("X-Group-Middleware", "used")
+		return c.Next()
+	})
+
+	app.Get("/*", testEmptyHandler)
+
+	for _, path := range []string{"/john", "/doe/1", "/test/foo", "/test/bar/1", "/other", "/test"} {
+		resp, err := app.Test(httptest.NewRequest(MethodGet, path, nil))
+		utils.AssertEqual(t, nil, err, "app.Test(req)")
+		utils.AssertEqual(t, 200, resp.StatusCode, "Status code")
+
+		var used, groupUsed string
+		switch path {
+		case "/john", "/doe/1":
+			used = "used"
+		case "/test/foo", "/test/bar/1":
+			groupUsed = "used"
+		}
+		utils.AssertEqual(t, used, resp.Header.Get("X-Middleware"), path)
+		utils.AssertEqual(t, groupUsed, resp.Header.Get("X-Group-Middleware"), path)
+	}
+}
+
 func Test_App_Chaining(t *testing.T) {
 	n := func(c *Ctx) error {
 		return c.Next()
@@ -495,6 +1004,27 @@ func Test_App_Config(t *testing.T) {
 	utils.AssertEqual(t, true, app.Config().DisableStartupMessage)
 }
 
+// go test -run Test_App_Config_Validate
+func Test_App_Config_Validate(t *testing.T) {
+	panics := func(config Config) (msg interface{}) {
+		defer func() {
+			msg = recover()
+		}()
+		New(config)
+		return
+	}
+
+	utils.AssertEqual(t, "config: ReadBufferSize must not be negative, got -1", panics(Config{ReadBufferSize: -1}))
+	utils.AssertEqual(t, "config: BodyLimit must not be negative, got -10", panics(Config{BodyLimit: -10}))
+	utils.AssertEqual(t, "config: IdleTimeout must not be negative, got -1s", panics(Config{IdleTimeout: -time.Second}))
+
+	// zero values are replaced by the defaults
+	utils.AssertEqual(t, nil, panics(Config{}))
+	app := New(Config{Concurrency: 0, ReadBufferSize: 8192, ReadTimeout: time.Second})
+	utils.AssertEqual(t, DefaultConcurrency, app.Config().Concurrency)
+	utils.AssertEqual(t, 8192, app.Config().ReadBufferSize)
+}
+
 func Test_App_Shutdown(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		app := New(Config{
@@ -513,6 +1043,43 @@ func Test_App_Shutdown(t *testing.T) {
 	})
 }
 
+// go test -run Test_App_InFlight
+func Test_App_InFlight(t *testing.T) {
+	app := New()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	app.Get("/slow", func(c *Ctx) error {
+		started <- struct{}{}
+		<-release
+		return c.SendStatus(StatusOK)
+	})
+
+	utils.AssertEqual(t, 0, app.InFlight())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := app.Test(httptest.NewRequest(MethodGet, "/slow", nil), -1)
+			utils.AssertEqual(t, nil, err, "app.Test(req)")
+			utils.AssertEqual(t, StatusOK, resp.StatusCode, "Status code")
+		}()
+	}
+	for i := 0; i < 3; i++ {
+		<-started
+	}
+	utils.AssertEqual(t, 3, app.InFlight())
+
+	close(release)
+	wg.Wait()
+	utils.AssertEqual(t, 0, app.InFlight())
+
+	utils.AssertEqual(t, false, app.Draining())
+	utils.AssertEqual(t, nil, app.Shutdown())
+	utils.AssertEqual(t, true, app.Draining())
+}
+
 // go test -run Test_App_Static_Index_Default
 func Test_App_Static_Index_Default(t *testing.T) {
 	app := New()
@@ -876,6 +1443,49 @@ func Test_App_Listen(t *testing.T) {
 	utils.AssertEqual(t, nil, app.Listen(":4003"))
 }
 
+// go test -run Test_App_Listen_OnListen
+func Test_App_Listen_OnListen(t *testing.T) {
+	addrs := make(chan net.Addr, 1)
+	app := New(Config{
+		DisableStartupMessage: true,
+		OnListen: func(addr net.Addr) {
+			addrs <- addr
+		},
+	})
+	app.Get("/", func(c *Ctx) error {
+		return c.SendString("random port")
+	})
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- app.Listen("127.0.0.1:0")
+	}()
+
+	var port int
+	select {
+	case addr := <-addrs:
+		port = addr.(*net.TCPAddr).Port
+	case err := <-errs:
+		t.Fatalf("listen: %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("OnListen was not called")
+	}
+	utils.AssertEqual(t, true, port > 0)
+
+	req, resp := fasthttp.AcquireRequest(), fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+	req.SetRequestURI(fmt.Sprintf("http://127.0.0.1:%d/", port))
+	// Close the connection, so the shutdown does not wait for it
+	req.SetConnectionClose()
+	utils.AssertEqual(t, nil, fasthttp.Do(req, resp))
+	utils.AssertEqual(t, StatusOK, resp.StatusCode())
+	utils.AssertEqual(t, "random port", string(resp.Body()))
+
+	utils.AssertEqual(t, nil, app.Shutdown())
+	utils.AssertEqual(t, nil, <-errs)
+}
+
 // go test -run Test_App_Listen_Prefork
 func Test_App_Listen_Prefork(t *testing.T) {
 	testPreforkMaster = true
@@ -948,6 +1558,100 @@ func Test_NewError(t *testing.T) {
 	utils.AssertEqual(t, "permission denied", e.Message)
 }
 
+// go test -run Test_NewProblem
+func Test_NewProblem(t *testing.T) {
+	e := NewProblem(StatusNotFound, ProblemDetails{Detail: "user 42 does not exist"})
+	utils.AssertEqual(t, StatusNotFound, e.Code)
+	utils.AssertEqual(t, "user 42 does not exist", e.Error())
+	utils.AssertEqual(t, "user 42 does not exist", e.Problem.Detail)
+
+	app := New()
+	app.Get("/problem", func(c *Ctx) error {
+		return NewProblem(StatusNotFound, ProblemDetails{
+			Type:     "https://example.com/probs/unknown-user",
+			Detail:   "user 42 does not exist",
+			Instance: "/users/42",
+		})
+	})
+	app.Get("/error", func(c *Ctx) error {
+		return NewError(StatusNotFound, "user 42 does not exist")
+	})
+
+	resp, err := app.Test(httptest.NewRequest(MethodGet, "/problem", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusNotFound, resp.StatusCode)
+	utils.AssertEqual(t, MIMEApplicationProblemJSON, resp.Header.Get(HeaderContentType))
+	body, err := ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, `{"type":"https://example.com/probs/unknown-user","title":"Not Found","status":404,"detail":"user 42 does not exist","instance":"/users/42"}`, string(body))
+
+	// errors without problem details are still sent as text
+	resp, err = app.Test(httptest.NewRequest(MethodGet, "/error", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusNotFound, resp.StatusCode)
+	utils.AssertEqual(t, MIMETextPlainCharsetUTF8, resp.Header.Get(HeaderContentType))
+	body, err = ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "user 42 does not exist", string(body))
+}
+
+type testFieldError struct {
+	field, tag string
+}
+
+func (e testFieldError) Field() string { return e.field }
+func (e testFieldError) Tag() string   { return e.tag }
+func (e testFieldError) Error() string { return e.field + " " + e.tag }
+
+// testFieldErrors has the shape of validator.ValidationErrors
+type testFieldErrors []fieldError
+
+func (e testFieldErrors) Error() string { return "invalid" }
+
+// go test -run Test_NewValidationError
+func Test_NewValidationError(t *testing.T) {
+	utils.AssertEqual(t, nil, NewValidationError(nil))
+	utils.AssertEqual(t, ErrBadRequest, NewValidationError(ErrBadRequest))
+	utils.AssertEqual(t, ValidationError{"name": "failed on the 'required' tag"}, NewValidationError(testFieldError{"name", "required"}))
+
+	validationErr := NewValidationError(testFieldErrors{testFieldError{"name", "required"}, testFieldError{"age", "min"}})
+	utils.AssertEqual(t, ValidationError{
+		"name": "failed on the 'required' tag",
+		"age":  "failed on the 'min' tag",
+	}, validationErr)
+	utils.AssertEqual(t, "validation failed: age: failed on the 'min' tag, name: failed on the 'required' tag", validationErr.Error())
+
+	app := New()
+	app.Post("/users", func(c *Ctx) error {
+		return validationErr
+	})
+
+	// JSON is rendered if the client accepts it
+	for _, accept := range []string{"", MIMEApplicationJSON, "*/*"} {
+		req := httptest.NewRequest(MethodPost, "/users", nil)
+		if accept != "" {
+			req.Header.Set(HeaderAccept, accept)
+		}
+		resp, err := app.Test(req)
+		utils.AssertEqual(t, nil, err, "app.Test(req)")
+		utils.AssertEqual(t, StatusUnprocessableEntity, resp.StatusCode)
+		utils.AssertEqual(t, MIMEApplicationJSON, resp.Header.Get(HeaderContentType))
+		body, err := ioutil.ReadAll(resp.Body)
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, `{"errors":{"age":"failed on the 'min' tag","name":"failed on the 'required' tag"}}`, string(body))
+	}
+
+	req := httptest.NewRequest(MethodPost, "/users", nil)
+	req.Header.Set(HeaderAccept, MIMETextPlain)
+	resp, err := app.Test(req)
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusUnprocessableEntity, resp.StatusCode)
+	utils.AssertEqual(t, MIMETextPlainCharsetUTF8, resp.Header.Get(HeaderContentType))
+	body, err := ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "validation failed: age: failed on the 'min' tag, name: failed on the 'required' tag", string(body))
+}
+
 func Test_Test_Timeout(t *testing.T) {
 	app := New()
 	app.config.DisableStartupMessage = true
@@ -984,6 +1688,93 @@ func Test_Test_DumpError(t *testing.T) {
 	utils.AssertEqual(t, "errorReader", err.Error())
 }
 
+// go test -run Test_Test_RemoteAddr
+func Test_Test_RemoteAddr(t *testing.T) {
+	app := New()
+
+	app.Get("/", func(c *Ctx) error {
+		return c.SendString(c.IP())
+	})
+
+	req := httptest.NewRequest(MethodGet, "/", nil)
+	req.RemoteAddr = "1.2.3.4:5678"
+	resp, err := app.Test(req)
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	body, err := ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "1.2.3.4", string(body))
+
+	// the default address of httptest is ignored
+	resp, err = app.Test(httptest.NewRequest(MethodGet, "/", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	body, err = ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "0.0.0.0", string(body))
+
+	req = httptest.NewRequest(MethodGet, "/", nil)
+	req.RemoteAddr = "localhost"
+	resp, err = app.Test(req)
+	utils.AssertEqual(t, true, resp == nil)
+	utils.AssertEqual(t, "test: invalid remote address localhost", err.Error())
+}
+
+// go test -run Test_TestClient_Cookies
+func Test_TestClient_Cookies(t *testing.T) {
+	app := New()
+
+	app.Post("/login", func(c *Ctx) error {
+		c.Cookie(&Cookie{Name: "session", Value: "secret", Path: "/"})
+		c.Cookie(&Cookie{Name: "secure", Value: "secret", Path: "/", Secure: true})
+		return c.SendStatus(StatusNoContent)
+	})
+	app.Post("/logout", func(c *Ctx) error {
+		c.ClearCookie("session")
+		return c.SendStatus(StatusNoContent)
+	})
+	app.Get("/profile", func(c *Ctx) error {
+		if c.Cookies("session") != "secret" {
+			return ErrUnauthorized
+		}
+		return c.SendString("secure=" + c.Cookies("secure"))
+	})
+
+	client := NewTestClient(app)
+	profile := func(target string) (int, string) {
+		resp, err := client.Test(httptest.NewRequest(MethodGet, target, nil))
+		utils.AssertEqual(t, nil, err, "client.Test(req)")
+		body, err := ioutil.ReadAll(resp.Body)
+		utils.AssertEqual(t, nil, err)
+		return resp.StatusCode, string(body)
+	}
+
+	status, _ := profile("/profile")
+	utils.AssertEqual(t, StatusUnauthorized, status)
+
+	resp, err := client.Test(httptest.NewRequest(MethodPost, "/login", nil))
+	utils.AssertEqual(t, nil, err, "client.Test(req)")
+	utils.AssertEqual(t, StatusNoContent, resp.StatusCode)
+
+	// the session cookie of the login is sent, the secure cookie only over https
+	status, body := profile("/profile")
+	utils.AssertEqual(t, StatusOK, status)
+	utils.AssertEqual(t, "secure=", body)
+	status, body = profile("https://example.com/profile")
+	utils.AssertEqual(t, StatusOK, status)
+	utils.AssertEqual(t, "secure=secret", body)
+
+	// app.Test doesn't use the jar
+	resp, err = app.Test(httptest.NewRequest(MethodGet, "/profile", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusUnauthorized, resp.StatusCode)
+
+	// the expired cookie of the logout is removed from the jar
+	resp, err = client.Test(httptest.NewRequest(MethodPost, "/logout", nil))
+	utils.AssertEqual(t, nil, err, "client.Test(req)")
+	utils.AssertEqual(t, StatusNoContent, resp.StatusCode)
+	status, _ = profile("/profile")
+	utils.AssertEqual(t, StatusUnauthorized, status)
+}
+
 func Test_App_Handler(t *testing.T) {
 	h := New().Handler()
 	utils.AssertEqual(t, "fasthttp.RequestHandler", reflect.TypeOf(h).String())
@@ -1006,6 +1797,77 @@ func Test_App_Init_Error_View(t *testing.T) {
 	_ = app.config.Views.Render(nil, "", nil)
 }
 
+// go test -run Test_App_Head_AutoRegister
+func Test_App_Head_AutoRegister(t *testing.T) {
+	handler := func(c *Ctx) error {
+		c.Set("X-Custom-Header", "gofiber")
+		return c.SendString("Hello, World!")
+	}
+
+	app := New()
+	app.Get("/", handler)
+	app.Add(MethodGet, "/add", handler)
+	app.Group("/group").Get("/", handler)
+
+	for _, path := range []string{"/", "/add", "/group"} {
+		resp, err := app.Test(httptest.NewRequest(MethodGet, path, nil))
+		utils.AssertEqual(t, nil, err, "app.Test(req)")
+		utils.AssertEqual(t, StatusOK, resp.StatusCode, path)
+		body, err := ioutil.ReadAll(resp.Body)
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, "Hello, World!", string(body))
+
+		resp, err = app.Test(httptest.NewRequest(MethodHead, path, nil))
+		utils.AssertEqual(t, nil, err, "app.Test(req)")
+		utils.AssertEqual(t, StatusOK, resp.StatusCode, path)
+		utils.AssertEqual(t, "gofiber", resp.Header.Get("X-Custom-Header"))
+		utils.AssertEqual(t, "13", resp.Header.Get(HeaderContentLength))
+		body, err = ioutil.ReadAll(resp.Body)
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, "", string(body))
+	}
+
+	app = New(Config{DisableHeadAutoRegister: true})
+	app.Get("/", handler)
+
+	resp, err := app.Test(httptest.NewRequest(MethodHead, "/", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusMethodNotAllowed, resp.StatusCode)
+	utils.AssertEqual(t, 0, len(app.Stack()[methodInt(MethodHead)]))
+}
+
+// go test -run Test_App_Head_Explicit
+func Test_App_Head_Explicit(t *testing.T) {
+	get := func(c *Ctx) error {
+		c.Set("X-Who", "get")
+		return c.SendString("get")
+	}
+	head := func(c *Ctx) error {
+		c.Set("X-Who", "head")
+		return nil
+	}
+
+	app := New()
+	// explicit HEAD route registered after the GET route
+	app.Get("/get-head", get)
+	app.Head("/get-head", head)
+	// explicit HEAD route registered before the GET route
+	app.Head("/head-get", head)
+	app.Get("/head-get", get)
+
+	for _, path := range []string{"/get-head", "/head-get"} {
+		resp, err := app.Test(httptest.NewRequest(MethodHead, path, nil))
+		utils.AssertEqual(t, nil, err, "app.Test(req)")
+		utils.AssertEqual(t, StatusOK, resp.StatusCode, path)
+		utils.AssertEqual(t, "head", resp.Header.Get("X-Who"), path)
+
+		resp, err = app.Test(httptest.NewRequest(MethodGet, path, nil))
+		utils.AssertEqual(t, nil, err, "app.Test(req)")
+		utils.AssertEqual(t, "get", resp.Header.Get("X-Who"), path)
+	}
+	utils.AssertEqual(t, 2, len(app.Stack()[methodInt(MethodHead)]))
+}
+
 func Test_App_Stack(t *testing.T) {
 	app := New()
 
@@ -1027,6 +1889,133 @@ func Test_App_Stack(t *testing.T) {
 	utils.AssertEqual(t, 1, len(stack[methodInt(MethodTrace)]))
 }
 
+// go test -run Test_App_GetRoutes
+func Test_App_GetRoutes(t *testing.T) {
+	app := New()
+
+	app.Use(testEmptyHandler)
+	app.Get("/users/:id", testEmptyHandler, testEmptyHandler).Name("user")
+	app.Post("/users", testEmptyHandler)
+	api := app.Group("/api", testEmptyHandler)
+	api.Put("/items/:id/:action?", testEmptyHandler).Name("item")
+	// explicit HEAD routes are listed, also on the path of a GET route
+	app.Head("/ping", testEmptyHandler)
+	app.Get("/status", testEmptyHandler)
+	app.Head("/status", testEmptyHandler)
+
+	type route struct {
+		Method string
+		Name   string
+		Path   string
+		Params []string
+	}
+	var routes []route
+	for _, r := range app.GetRoutes() {
+		routes = append(routes, route{r.Method, r.Name, r.Path, r.Params})
+	}
+	utils.AssertEqual(t, []route{
+		{MethodGet, "user", "/users/:id", []string{"id"}},
+		{MethodGet, "", "/status", nil},
+		{MethodHead, "", "/ping", nil},
+		{MethodHead, "", "/status", nil},
+		{MethodPost, "", "/users", nil},
+		{MethodPut, "item", "/api/items/:id/:action?", []string{"id", "action"}},
+	}, routes)
+
+	// the middleware handlers are counted as well
+	utils.AssertEqual(t, 9, app.HandlersCount())
+}
+
+// go test -run Test_App_Route_Timeout
+func Test_App_Route_Timeout(t *testing.T) {
+	t.Parallel()
+	app := New()
+
+	sleep := func(c *Ctx) error {
+		select {
+		case <-c.UserContext().Done():
+		case <-time.After(500 * time.Millisecond):
+		}
+		return c.SendString("done")
+	}
+	app.Get("/slow", sleep).Timeout(20 * time.Millisecond)
+	app.Get("/fast", sleep).Timeout(time.Second)
+	app.Group("/api").Get("/slow", sleep).Timeout(20 * time.Millisecond)
+
+	for path, status := range map[string]int{
+		"/slow":     StatusRequestTimeout,
+		"/fast":     StatusOK,
+		"/api/slow": StatusRequestTimeout,
+	} {
+		resp, err := app.Test(httptest.NewRequest(MethodGet, path, nil))
+		utils.AssertEqual(t, nil, err, "app.Test(req)")
+		utils.AssertEqual(t, status, resp.StatusCode, path)
+	}
+	// the HEAD route of a GET route has the timeout as well
+	resp, err := app.Test(httptest.NewRequest(MethodHead, "/slow", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusRequestTimeout, resp.StatusCode)
+
+	// the user context of the handlers before the route is restored
+	app = New()
+	app.Use(func(c *Ctx) error {
+		err := c.Next()
+		utils.AssertEqual(t, nil, c.UserContext().Err())
+		return err
+	})
+	app.Get("/slow", sleep).Timeout(20 * time.Millisecond)
+	resp, err = app.Test(httptest.NewRequest(MethodGet, "/slow", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusRequestTimeout, resp.StatusCode)
+}
+
+// go test -run Test_App_Route_Limit
+func Test_App_Route_Limit(t *testing.T) {
+	t.Parallel()
+	app := New()
+
+	app.Get("/limited", testEmptyHandler).Limit(2, time.Minute)
+	app.Get("/other", testEmptyHandler).Limit(5, time.Minute)
+	app.Get("/free", testEmptyHandler)
+
+	get := func(path string) *http.Response {
+		resp, err := app.Test(httptest.NewRequest(MethodGet, path, nil))
+		utils.AssertEqual(t, nil, err, "app.Test(req)")
+		return resp
+	}
+	utils.AssertEqual(t, StatusOK, get("/limited").StatusCode)
+	utils.AssertEqual(t, StatusOK, get("/limited").StatusCode)
+	resp := get("/limited")
+	utils.AssertEqual(t, StatusTooManyRequests, resp.StatusCode)
+	utils.AssertEqual(t, "60", resp.Header.Get(HeaderRetryAfter))
+
+	// every route is limited independently
+	for i := 0; i < 5; i++ {
+		utils.AssertEqual(t, StatusOK, get("/other").StatusCode)
+		utils.AssertEqual(t, StatusOK, get("/free").StatusCode)
+	}
+	utils.AssertEqual(t, StatusTooManyRequests, get("/other").StatusCode)
+	utils.AssertEqual(t, StatusOK, get("/free").StatusCode)
+
+	// only the handlers of the latest registration of a path are limited
+	app.Get("/merged", func(c *Ctx) error {
+		c.Set("X-First", "1")
+		if c.Query("skip") != "" {
+			return nil
+		}
+		return c.Next()
+	})
+	app.Get("/merged", testEmptyHandler).Limit(1, time.Minute)
+	for i := 0; i < 3; i++ {
+		utils.AssertEqual(t, StatusOK, get("/merged?skip=1").StatusCode)
+	}
+	utils.AssertEqual(t, StatusOK, get("/merged").StatusCode)
+	resp = get("/merged")
+	utils.AssertEqual(t, StatusTooManyRequests, resp.StatusCode)
+	// the handler of the earlier registration still runs first
+	utils.AssertEqual(t, "1", resp.Header.Get("X-First"))
+}
+
 // go test -run Test_App_ReadTimeout
 func Test_App_ReadTimeout(t *testing.T) {
 	app := New(Config{
@@ -5,31 +5,46 @@
 package fiber
 
 import (
+	"bufio"
 	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"html"
 	"io"
+	"io/ioutil"
 	"mime/multipart"
 	"net/http"
+	"net/url"
+	"os"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"text/template"
 	"time"
 
+	"github.com/andybalholm/brotli"
 	"github.com/gofiber/fiber/v2/internal/bytebufferpool"
 	"github.com/gofiber/fiber/v2/internal/encoding/json"
 	"github.com/gofiber/fiber/v2/internal/schema"
 	"github.com/gofiber/fiber/v2/utils"
+	"github.com/klauspost/compress/zstd"
 	"github.com/valyala/fasthttp"
 )
 
 // maxParams defines the maximum number of parameters per route.
 const maxParams = 30
 
+// disconnectInterval defines how often the connection is checked for a client disconnect.
+const disconnectInterval = 100 * time.Millisecond
+
 // Ctx represents the Context which hold the HTTP request and response.
 // It has methods for the request query string, parameters, body, HTTP headers and so on.
 type Ctx struct {
@@ -47,6 +62,14 @@ type Ctx struct {
 	values       [maxParams]string    // Route parameter values
 	fasthttp     *fasthttp.RequestCtx // Reference to *fasthttp.RequestCtx
 	matched      bool                 // Non use route matched
+	mountDepth   int                  // Amount of mounted sub-apps handling the request
+	unmatched    bool                 // No route of a mounted sub-app matched, see app.mount
+	formChecked  bool                 // Multipart form limits are checked, see checkMultipartLimits
+	bound        Map                  // Variables of the request, see BindVars
+	userContext  context.Context      // Context set by the user, see SetUserContext
+	cancel       context.CancelFunc   // Releases the deadlines of the user context, see SetDeadline
+	disconnect   *disconnectWatcher   // Cancels the user contexts on client disconnect, see watchDisconnect
+	watched      bool                 // The connection is watched for client disconnects
 }
 
 // Range data for c.Range
@@ -60,15 +83,52 @@ type Range struct {
 
 // Cookie data for c.Cookie
 type Cookie struct {
-	Name     string    `json:"name"`
-	Value    string    `json:"value"`
-	Path     string    `json:"path"`
-	Domain   string    `json:"domain"`
-	MaxAge   int       `json:"max_age"`
-	Expires  time.Time `json:"expires"`
-	Secure   bool      `json:"secure"`
-	HTTPOnly bool      `json:"http_only"`
-	SameSite string    `json:"same_site"`
+	Name        string    `json:"name"`
+	Value       string    `json:"value"`
+	Path        string    `json:"path"`
+	Domain      string    `json:"domain"`
+	MaxAge      int       `json:"max_age"`
+	Expires     time.Time `json:"expires"`
+	Secure      bool      `json:"secure"`
+	HTTPOnly    bool      `json:"http_only"`
+	SameSite    string    `json:"same_site"`
+	Partitioned bool      `json:"partitioned"`
+}
+
+// Event is a Server-Sent Event for c.SendEventStream
+type Event struct {
+	ID    string        `json:"id"`
+	Event string        `json:"event"`
+	Data  string        `json:"data"`
+	Retry time.Duration `json:"retry"`
+}
+
+// String formats the event in the text/event-stream format,
+// every line of Data is sent as a data field.
+func (e Event) String() string {
+	var sb strings.Builder
+	if e.ID != "" {
+		sb.WriteString("id: " + removeNewLines(e.ID) + "\n")
+	}
+	if e.Event != "" {
+		sb.WriteString("event: " + removeNewLines(e.Event) + "\n")
+	}
+	if e.Retry > 0 {
+		sb.WriteString("retry: " + strconv.FormatInt(e.Retry.Milliseconds(), 10) + "\n")
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(e.Data, "\r\n", "\n"), "\n") {
+		sb.WriteString("data: " + line + "\n")
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// Write writes the event to the stream of c.SendEventStream and flushes it to the client.
+func (e Event) Write(w *bufio.Writer) error {
+	if _, err := w.WriteString(e.String()); err != nil {
+		return err
+	}
+	return w.Flush()
 }
 
 // Views is the interface that wraps the Render function.
@@ -87,6 +147,10 @@ func (app *App) AcquireCtx(fctx *fasthttp.RequestCtx) *Ctx {
 	c.indexHandler = 0
 	// Reset matched flag
 	c.matched = false
+	// Reset the state of mounted sub-apps
+	c.mountDepth = 0
+	c.unmatched = false
+	c.formChecked = false
 	// Set paths
 	c.pathBuffer = append(c.pathBuffer[0:0], fctx.URI().PathOriginal()...)
 	c.pathOriginal = getString(fctx.URI().PathOriginal())
@@ -107,63 +171,23 @@ func (app *App) ReleaseCtx(c *Ctx) {
 	// Reset values
 	c.route = nil
 	c.fasthttp = nil
+	c.userContext = nil
+	c.disconnect = nil
+	c.watched = false
+	c.bound = nil
+	// Stop the timers of the deadlines
+	if c.cancel != nil {
+		c.cancel()
+		c.cancel = nil
+	}
 	app.pool.Put(c)
 }
 
 // Accepts checks if the specified extensions or content types are acceptable.
+// The offer with the highest q-value is returned, media ranges like "text/*"
+// and "*/*" match with a lower priority than exact types.
 func (c *Ctx) Accepts(offers ...string) string {
-	if len(offers) == 0 {
-		return ""
-	}
-	header := c.Get(HeaderAccept)
-	if header == "" {
-		return offers[0]
-	}
-
-	spec, commaPos := "", 0
-	for len(header) > 0 && commaPos != -1 {
-		commaPos = strings.IndexByte(header, ',')
-		if commaPos != -1 {
-			spec = utils.Trim(header[:commaPos], ' ')
-		} else {
-			spec = utils.TrimLeft(header, ' ')
-		}
-		if factorSign := strings.IndexByte(spec, ';'); factorSign != -1 {
-			spec = spec[:factorSign]
-		}
-
-		var mimetype string
-		for _, offer := range offers {
-			if len(offer) == 0 {
-				continue
-				// Accept: */*
-			} else if spec == "*/*" {
-				return offer
-			}
-
-			if strings.IndexByte(offer, '/') != -1 {
-				mimetype = offer // MIME type
-			} else {
-				mimetype = utils.GetMIME(offer) // extension
-			}
-
-			if spec == mimetype {
-				// Accept: <MIME_type>/<MIME_subtype>
-				return offer
-			}
-
-			s := strings.IndexByte(mimetype, '/')
-			// Accept: <MIME_type>/*
-			if strings.HasPrefix(spec, mimetype[:s]) && (spec[s:] == "/*" || mimetype[s:] == "/*") {
-				return offer
-			}
-		}
-		if commaPos != -1 {
-			header = header[commaPos+1:]
-		}
-	}
-
-	return ""
+	return getMediaOffer(c.Get(HeaderAccept), offers...)
 }
 
 // AcceptsCharsets checks if the specified charset is acceptable.
@@ -172,8 +196,10 @@ func (c *Ctx) AcceptsCharsets(offers ...string) string {
 }
 
 // AcceptsEncodings checks if the specified encoding is acceptable.
+// The offer with the highest q-value is returned, identity is acceptable
+// unless it is rejected with "identity;q=0" or "*;q=0".
 func (c *Ctx) AcceptsEncodings(offers ...string) string {
-	return getOffer(c.Get(HeaderAcceptEncoding), offers...)
+	return getEncodingOffer(c.Get(HeaderAcceptEncoding), offers...)
 }
 
 // AcceptsLanguages checks if the specified language is acceptable.
@@ -188,10 +214,18 @@ func (c *Ctx) App() *App {
 
 // Append the specified value to the HTTP response header field.
 // If the header is not already set, it creates the header with the specified value.
+// Set-Cookie values are not joined, every value is sent as a separate header,
+// also if a cookie of the same name was appended before.
 func (c *Ctx) Append(field string, values ...string) {
 	if len(values) == 0 {
 		return
 	}
+	if strings.EqualFold(field, HeaderSetCookie) {
+		for _, value := range values {
+			c.fasthttp.Response.Header.Add(HeaderSetCookie, value)
+		}
+		return
+	}
 	h := getString(c.fasthttp.Response.Header.Peek(field))
 	originalH := h
 	for _, value := range values {
@@ -219,6 +253,48 @@ func (c *Ctx) Attachment(filename ...string) {
 	c.setCanonical(HeaderContentDisposition, "attachment")
 }
 
+// AttachmentStream sends the reader as an attachment with the filename, e.g.
+// for generated CSV or ZIP downloads. The reader is streamed without buffering
+// the response, it's closed after the response if it's an io.Closer.
+// With the optional size the Content-Length is set, otherwise the response is chunked.
+//  c.AttachmentStream("report.csv", r)
+func (c *Ctx) AttachmentStream(filename string, r io.Reader, size ...int) error {
+	c.Attachment(filename)
+	if len(size) > 0 && size[0] >= 0 {
+		c.fasthttp.Response.SetBodyStream(r, size[0])
+	} else {
+		c.fasthttp.Response.SetBodyStream(r, -1)
+	}
+	return nil
+}
+
+// AutoFormat serializes the body based on the Accept HTTP header to
+// JSON, XML or plain text, in this order of preference.
+// ErrNotAcceptable is returned if none of them is acceptable.
+//  c.AutoFormat(user)
+func (c *Ctx) AutoFormat(body interface{}) error {
+	// The response differs based on the Accept header
+	c.Vary(HeaderAccept)
+
+	switch c.Accepts("json", "xml", "txt") {
+	case "json":
+		return c.JSON(body)
+	case "xml":
+		return c.XML(body)
+	case "txt":
+		c.Type("txt")
+		switch val := body.(type) {
+		case string:
+			return c.SendString(val)
+		case []byte:
+			return c.Send(val)
+		default:
+			return c.SendString(fmt.Sprintf("%v", val))
+		}
+	}
+	return ErrNotAcceptable
+}
+
 // BaseURL returns (protocol + host + base path).
 func (c *Ctx) BaseURL() string {
 	// TODO: Could be improved: 53.8 ns/op  32 B/op  1 allocs/op
@@ -230,10 +306,26 @@ func (c *Ctx) BaseURL() string {
 	return c.baseURI
 }
 
+// BindVars adds the variables to the request scoped variables shared by
+// the middleware and handlers of the request, existing keys are overwritten.
+// The variables are passed to c.Render together with a Map bind.
+func (c *Ctx) BindVars(vars Map) *Ctx {
+	if c.bound == nil {
+		c.bound = make(Map, len(vars))
+	}
+	for key, val := range vars {
+		c.bound[key] = val
+	}
+	return c
+}
+
 // Body contains the raw body submitted in a POST request.
 // Returned value is only valid within the handler. Do not store any references.
 // Make copies or use the Immutable setting instead.
 func (c *Ctx) Body() []byte {
+	if c.app.config.Immutable {
+		return utils.SafeBytes(c.fasthttp.Request.Body())
+	}
 	return c.fasthttp.Request.Body()
 }
 
@@ -244,43 +336,157 @@ var decoderPool = &sync.Pool{New: func() interface{} {
 	return decoder
 }}
 
+// customBinders holds the binders of RegisterCustomBinder by content type
+var customBinders = struct {
+	sync.RWMutex
+	binders map[string]func([]byte, interface{}) error
+}{binders: make(map[string]func([]byte, interface{}) error)}
+
+// RegisterCustomBinder registers a function that binds request bodies of the
+// content type to a struct in BodyParser. The built-in content types can
+// not be overridden.
+//  fiber.RegisterCustomBinder("application/msgpack", msgpack.Unmarshal)
+func RegisterCustomBinder(contentType string, binder func([]byte, interface{}) error) {
+	customBinders.Lock()
+	customBinders.binders[utils.ToLower(contentType)] = binder
+	customBinders.Unlock()
+}
+
 // BodyParser binds the request body to a struct.
 // It supports decoding the following content types based on the Content-Type header:
 // application/json, application/xml, application/x-www-form-urlencoded, multipart/form-data
-func (c *Ctx) BodyParser(out interface{}) error {
+// and the content types of RegisterCustomBinder.
+// If a content type is given, it is used instead of the Content-Type header:
+//  c.BodyParser(&out, fiber.MIMEApplicationJSON)
+// multipart/form-data still requires the boundary of the Content-Type header.
+// Form keys fill nested structs and maps in dotted or bracket notation,
+// e.g. "address.city=NYC" or "address[city]=NYC". Repeated keys like
+// "tag=a&tag=b" or "tag[]=a&tag[]=b" fill slice fields.
+// Bodies with a gzip Content-Encoding, or deflate, br and zstd with EnableRequestDecompression,
+// are decoded up to the DecompressedBodyLimit of the app, exceeding it returns ErrRequestEntityTooLarge.
+func (c *Ctx) BodyParser(out interface{}, contentType ...string) error {
 	// Get decoder from pool
 	schemaDecoder := decoderPool.Get().(*schema.Decoder)
 	defer decoderPool.Put(schemaDecoder)
 
 	// Get content-type
-	ctype := getString(c.fasthttp.Request.Header.ContentType())
+	header := getString(c.fasthttp.Request.Header.ContentType())
+	ctype := header
+	if len(contentType) > 0 && contentType[0] != "" {
+		ctype = contentType[0]
+	}
+
+	// Decode the body of the Content-Encoding
+	body, encoded, err := c.decodedBody()
+	if err != nil {
+		return err
+	}
 
 	// Parse body accordingly
 	if strings.HasPrefix(ctype, MIMEApplicationJSON) {
 		schemaDecoder.SetAliasTag("json")
-		return json.Unmarshal(c.fasthttp.Request.Body(), out)
+		return json.Unmarshal(body, out)
 	} else if strings.HasPrefix(ctype, MIMEApplicationForm) {
 		schemaDecoder.SetAliasTag("form")
+		args := c.fasthttp.PostArgs()
+		// PostArgs are only parsed from the raw body with a matching Content-Type header
+		if encoded || !strings.HasPrefix(header, MIMEApplicationForm) {
+			args = fasthttp.AcquireArgs()
+			defer fasthttp.ReleaseArgs(args)
+			args.ParseBytes(body)
+		}
 		data := make(map[string][]string)
-		c.fasthttp.PostArgs().VisitAll(func(key []byte, val []byte) {
-			data[getString(key)] = append(data[getString(key)], getString(val))
+		args.VisitAll(func(key []byte, val []byte) {
+			k := formKey(getString(key))
+			data[k] = append(data[k], getString(val))
 		})
 		return schemaDecoder.Decode(out, data)
 	} else if strings.HasPrefix(ctype, MIMEMultipartForm) {
 		schemaDecoder.SetAliasTag("form")
-		data, err := c.fasthttp.MultipartForm()
+		form, err := c.MultipartForm()
 		if err != nil {
 			return err
 		}
-		return schemaDecoder.Decode(out, data.Value)
+		data := make(map[string][]string, len(form.Value))
+		for key, values := range form.Value {
+			k := formKey(key)
+			data[k] = append(data[k], values...)
+		}
+		return schemaDecoder.Decode(out, data)
 	} else if strings.HasPrefix(ctype, MIMETextXML) || strings.HasPrefix(ctype, MIMEApplicationXML) {
 		schemaDecoder.SetAliasTag("xml")
-		return xml.Unmarshal(c.fasthttp.Request.Body(), out)
+		return xml.Unmarshal(body, out)
+	}
+	// Use a custom binder of the media type
+	mediaType := ctype
+	if paramSign := strings.IndexByte(mediaType, ';'); paramSign != -1 {
+		mediaType = mediaType[:paramSign]
+	}
+	customBinders.RLock()
+	binder, ok := customBinders.binders[utils.ToLower(utils.Trim(mediaType, ' '))]
+	customBinders.RUnlock()
+	if ok {
+		return binder(body, out)
 	}
 	// No suitable content type found
 	return fmt.Errorf("bodyparser: cannot parse content-type: %v", ctype)
 }
 
+// decodedBody returns the request body decoded according to a gzip Content-Encoding
+// and whether it was encoded, deflate, br and zstd are decoded with EnableRequestDecompression.
+// The decoded body must not exceed the DecompressedBodyLimit,
+// so a small compressed body can not expand to an unlimited size in memory.
+func (c *Ctx) decodedBody() ([]byte, bool, error) {
+	raw := c.fasthttp.Request.Body()
+	var reader io.Reader
+	switch utils.ToLower(utils.Trim(getString(c.fasthttp.Request.Header.Peek(HeaderContentEncoding)), ' ')) {
+	case "gzip", "x-gzip":
+		gzipReader, err := gzip.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return nil, true, ErrBadRequest
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	case "deflate":
+		if !c.app.config.EnableRequestDecompression {
+			return raw, false, nil
+		}
+		zlibReader, err := zlib.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return nil, true, ErrBadRequest
+		}
+		defer zlibReader.Close()
+		reader = zlibReader
+	case "br":
+		if !c.app.config.EnableRequestDecompression {
+			return raw, false, nil
+		}
+		reader = brotli.NewReader(bytes.NewReader(raw))
+	case "zstd":
+		if !c.app.config.EnableRequestDecompression {
+			return raw, false, nil
+		}
+		zstdReader, err := zstd.NewReader(bytes.NewReader(raw), zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, true, ErrBadRequest
+		}
+		defer zstdReader.Close()
+		reader = zstdReader
+	default:
+		return raw, false, nil
+	}
+	limit := int64(c.app.config.DecompressedBodyLimit)
+	// Read one byte more than the limit to detect bodies exceeding it
+	body, err := ioutil.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil {
+		return nil, true, ErrBadRequest
+	}
+	if int64(len(body)) > limit {
+		return nil, true, ErrRequestEntityTooLarge
+	}
+	return body, true, nil
+}
+
 // ClearCookie expires a specific cookie by key on the client side.
 // If no key is provided it expires all cookies that came with the request.
 func (c *Ctx) ClearCookie(key ...string) {
@@ -301,7 +507,13 @@ func (c *Ctx) Context() *fasthttp.RequestCtx {
 	return c.fasthttp
 }
 
+// Deadline returns the deadline of the user context, ok is false if no deadline is set.
+func (c *Ctx) Deadline() (deadline time.Time, ok bool) {
+	return c.UserContext().Deadline()
+}
+
 // Cookie sets a cookie by passing a cookie struct.
+// Partitioned cookies (CHIPS) are always Secure.
 func (c *Ctx) Cookie(cookie *Cookie) {
 	fcookie := fasthttp.AcquireCookie()
 	fcookie.SetKey(cookie.Name)
@@ -310,7 +522,7 @@ func (c *Ctx) Cookie(cookie *Cookie) {
 	fcookie.SetDomain(cookie.Domain)
 	fcookie.SetMaxAge(cookie.MaxAge)
 	fcookie.SetExpire(cookie.Expires)
-	fcookie.SetSecure(cookie.Secure)
+	fcookie.SetSecure(cookie.Secure || cookie.Partitioned)
 	fcookie.SetHTTPOnly(cookie.HTTPOnly)
 
 	switch utils.ToLower(cookie.SameSite) {
@@ -322,10 +534,35 @@ func (c *Ctx) Cookie(cookie *Cookie) {
 		fcookie.SetSameSite(fasthttp.CookieSameSiteLaxMode)
 	}
 
-	c.fasthttp.Response.Header.SetCookie(fcookie)
+	if cookie.Partitioned {
+		// fasthttp does not support the Partitioned attribute
+		c.fasthttp.Response.Header.DelCookie(cookie.Name)
+		c.fasthttp.Response.Header.Set(HeaderSetCookie, getString(fcookie.Cookie())+"; Partitioned")
+	} else {
+		c.fasthttp.Response.Header.SetCookie(fcookie)
+	}
 	fasthttp.ReleaseCookie(fcookie)
 }
 
+// CookieParser binds the request cookies to a struct.
+// Fields are matched by the `cookie:"name"` tag and converted to the field type.
+func (c *Ctx) CookieParser(out interface{}) error {
+	// Get decoder from pool
+	var decoder = decoderPool.Get().(*schema.Decoder)
+	defer decoderPool.Put(decoder)
+
+	// Set correct alias tag
+	decoder.SetAliasTag("cookie")
+
+	data := make(map[string][]string)
+	c.fasthttp.Request.Header.VisitAllCookie(func(key []byte, val []byte) {
+		k := utils.UnsafeString(key)
+		data[k] = append(data[k], utils.UnsafeString(val))
+	})
+
+	return decoder.Decode(out, data)
+}
+
 // Cookies is used for getting a cookie value by key.
 // Defaults to the empty string "" if the cookie doesn't exist.
 // If a default value is given, it will return that value if the cookie doesn't exist.
@@ -335,18 +572,38 @@ func (c *Ctx) Cookies(key string, defaultValue ...string) string {
 	return defaultString(getString(c.fasthttp.Request.Header.Cookie(key)), defaultValue)
 }
 
+// CookiesBool is used for getting a cookie value by key as bool.
+// Defaults to false if the cookie doesn't exist or is not a valid bool.
+// If a default value is given, it will return that value instead.
+func (c *Ctx) CookiesBool(key string, defaultValue ...bool) bool {
+	value, err := strconv.ParseBool(getString(c.fasthttp.Request.Header.Cookie(key)))
+	if err != nil && len(defaultValue) > 0 {
+		return defaultValue[0]
+	}
+	return value
+}
+
+// CookiesInt is used for getting a cookie value by key as int.
+// Defaults to 0 if the cookie doesn't exist or is not a valid int.
+// If a default value is given, it will return that value instead.
+func (c *Ctx) CookiesInt(key string, defaultValue ...int) int {
+	value, err := strconv.Atoi(getString(c.fasthttp.Request.Header.Cookie(key)))
+	if err != nil && len(defaultValue) > 0 {
+		return defaultValue[0]
+	}
+	return value
+}
+
 // Download transfers the file from path as an attachment.
 // Typically, browsers will prompt the user for download.
 // By default, the Content-Disposition header filename= parameter is the filepath (this typically appears in the browser dialog).
 // Override this default with the filename parameter.
 func (c *Ctx) Download(file string, filename ...string) error {
-	var fname string
+	fname := file
 	if len(filename) > 0 {
 		fname = filename[0]
-	} else {
-		fname = filepath.Base(file)
 	}
-	c.setCanonical(HeaderContentDisposition, `attachment; filename="`+quoteString(fname)+`"`)
+	c.Attachment(fname)
 	return c.SendFile(file)
 }
 
@@ -364,10 +621,32 @@ func (c *Ctx) Response() *fasthttp.Response {
 	return &c.fasthttp.Response
 }
 
+// EscapeHTML escapes the special characters of HTML like "<" to "&lt;",
+// so that untrusted text can't inject markup into an HTML response.
+//  c.SendHTML("<p>" + c.EscapeHTML(c.Query("name")) + "</p>")
+func (c *Ctx) EscapeHTML(s string) string {
+	return html.EscapeString(s)
+}
+
 // Format performs content-negotiation on the Accept HTTP header.
 // It uses Accepts to select a proper format.
 // If the header is not specified or there is no proper format, text/plain is used.
+// The body is HTML escaped for text/html.
+//
+// If a map[string]func() error is given, the handler of the best matching
+// content type is executed instead. The keys can be extensions or mime types
+// and are offered in alphabetical order, so the first key is used if the header
+// is not specified. ErrNotAcceptable is returned if no content type matches.
+//
+//  c.Format(map[string]func() error{
+//       "html": func() error { return c.SendString("<p>Hello</p>") },
+//       "json": func() error { return c.JSON(data) },
+//  })
 func (c *Ctx) Format(body interface{}) error {
+	// Select a handler by content type
+	if handlers, ok := body.(map[string]func() error); ok {
+		return c.formatHandlers(handlers)
+	}
 	// Get accepted content type
 	accept := c.Accepts("html", "json", "txt", "xml")
 	// Set accepted content type
@@ -386,7 +665,7 @@ func (c *Ctx) Format(body interface{}) error {
 	// Format based on the accept content type
 	switch accept {
 	case "html":
-		return c.SendString("<p>" + b + "</p>")
+		return c.SendString("<p>" + html.EscapeString(b) + "</p>")
 	case "json":
 		return c.JSON(body)
 	case "txt":
@@ -402,17 +681,50 @@ func (c *Ctx) Format(body interface{}) error {
 	return c.SendString(b)
 }
 
+// formatHandlers executes the handler of the best matching content type
+func (c *Ctx) formatHandlers(handlers map[string]func() error) error {
+	offers := make([]string, 0, len(handlers))
+	for offer := range handlers {
+		offers = append(offers, offer)
+	}
+	sort.Strings(offers)
+	// The response differs based on the Accept header
+	c.Vary(HeaderAccept)
+	accept := c.Accepts(offers...)
+	if accept == "" {
+		return ErrNotAcceptable
+	}
+	if strings.IndexByte(accept, '/') != -1 {
+		c.fasthttp.Response.Header.SetContentType(accept)
+	} else {
+		c.Type(accept)
+	}
+	return handlers[accept]()
+}
+
 // FormFile returns the first file by key from a MultipartForm.
 func (c *Ctx) FormFile(key string) (*multipart.FileHeader, error) {
-	return c.fasthttp.FormFile(key)
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil, err
+	}
+	files := form.File[key]
+	if len(files) == 0 {
+		return nil, fasthttp.ErrMissingFile
+	}
+	return files[0], nil
 }
 
 // FormValue returns the first value by key from a MultipartForm.
 // Defaults to the empty string "" if the form value doesn't exist.
 // If a default value is given, it will return that value if the form value does not exist.
+// Only the query string is used if the form exceeds the MultipartFilesLimit or MultipartSizeLimit.
 // Returned value is only valid within the handler. Do not store any references.
 // Make copies or use the Immutable setting instead.
 func (c *Ctx) FormValue(key string, defaultValue ...string) string {
+	if c.checkMultipartLimits() != nil {
+		return defaultString(getString(c.fasthttp.QueryArgs().Peek(key)), defaultValue)
+	}
 	return defaultString(getString(c.fasthttp.FormValue(key)), defaultValue)
 }
 
@@ -476,17 +788,67 @@ func (c *Ctx) Get(key string, defaultValue ...string) string {
 	return defaultString(getString(c.fasthttp.Request.Header.Peek(key)), defaultValue)
 }
 
+// GetBound returns the variables added with BindVars, nil if there are none.
+// Unlike c.Locals the variables are passed to c.Render.
+func (c *Ctx) GetBound() Map {
+	return c.bound
+}
+
+// GetReqHeaders returns all HTTP request headers,
+// the values of headers that are sent multiple times are kept in order.
+// Returned value is only valid within the handler. Do not store any references.
+// Make copies or use the Immutable setting instead.
+func (c *Ctx) GetReqHeaders() map[string][]string {
+	headers := make(map[string][]string)
+	c.fasthttp.Request.Header.VisitAll(func(key, val []byte) {
+		k := getString(key)
+		headers[k] = append(headers[k], getString(val))
+	})
+	return headers
+}
+
+// GetRespHeader returns the HTTP response header specified by field.
+// Field names are case-insensitive
+// Returned value is only valid within the handler. Do not store any references.
+// Make copies or use the Immutable setting instead.
+func (c *Ctx) GetRespHeader(key string, defaultValue ...string) string {
+	return defaultString(getString(c.fasthttp.Response.Header.Peek(key)), defaultValue)
+}
+
+// GetRespHeaders returns all HTTP response headers that are set so far,
+// the values of headers that are set multiple times are kept in order.
+// Returned value is only valid within the handler. Do not store any references.
+// Make copies or use the Immutable setting instead.
+func (c *Ctx) GetRespHeaders() map[string][]string {
+	headers := make(map[string][]string)
+	c.fasthttp.Response.Header.VisitAll(func(key, val []byte) {
+		k := getString(key)
+		headers[k] = append(headers[k], getString(val))
+	})
+	return headers
+}
+
 // Hostname contains the hostname derived from the Host HTTP header.
+// With the EnableForwardedHost config the first host of the X-Forwarded-Host header is used.
 // Returned value is only valid within the handler. Do not store any references.
 // Make copies or use the Immutable setting instead.
 func (c *Ctx) Hostname() string {
+	if c.app.config.EnableForwardedHost {
+		if host := c.fasthttp.Request.Header.Peek(HeaderXForwardedHost); len(host) > 0 {
+			if commaPos := bytes.IndexByte(host, ','); commaPos != -1 {
+				host = host[:commaPos]
+			}
+			return utils.Trim(getString(host), ' ')
+		}
+	}
 	return getString(c.fasthttp.Request.URI().Host())
 }
 
-// IP returns the remote IP address of the request.
+// IP returns the remote IP address of the request in its canonical form,
+// e.g. "[::1]:8080" of the proxy header is returned as "::1".
 func (c *Ctx) IP() string {
 	if len(c.app.config.ProxyHeader) > 0 {
-		return c.Get(c.app.config.ProxyHeader)
+		return normalizeIP(c.Get(c.app.config.ProxyHeader))
 	}
 	return c.fasthttp.RemoteIP().String()
 }
@@ -527,13 +889,36 @@ func (c *Ctx) Is(extension string) bool {
 
 // JSON converts any interface or string to JSON.
 // This method also sets the content header to application/json.
-func (c *Ctx) JSON(data interface{}) error {
+// An optional status code can be passed to set the response status inline.
+func (c *Ctx) JSON(data interface{}, status ...int) error {
 	raw, err := json.Marshal(data)
 	if err != nil {
 		return err
 	}
 	c.fasthttp.Response.SetBodyRaw(raw)
 	c.fasthttp.Response.Header.SetContentType(MIMEApplicationJSON)
+	if len(status) > 0 {
+		c.Status(status[0])
+	}
+	return nil
+}
+
+// JSONStream streams any interface or string encoded to JSON to the client,
+// without marshaling it into a buffer first. Useful for large payloads.
+// Like json.Encoder, the output is terminated by a newline.
+// The data is encoded after the handler has returned, so it must not be modified
+// afterwards and an encoding error truncates the response instead of being returned.
+// This method also sets the content header to application/json.
+// An optional status code can be passed to set the response status inline.
+func (c *Ctx) JSONStream(data interface{}, status ...int) error {
+	c.fasthttp.Response.Header.SetContentType(MIMEApplicationJSON)
+	if len(status) > 0 {
+		c.Status(status[0])
+	}
+	c.fasthttp.Response.SetBodyStreamWriter(func(w *bufio.Writer) {
+		// The headers are already sent, an error can only end the response
+		_ = json.NewEncoder(w).Encode(data)
+	})
 	return nil
 }
 
@@ -563,21 +948,25 @@ func (c *Ctx) JSONP(data interface{}, callback ...string) error {
 }
 
 // Links joins the links followed by the property to populate the response's Link HTTP header field.
+// Characters that are not allowed in a link target are percent-encoded, links without
+// a valid relation type (RFC 5988) are skipped.
+//  c.Links("http://api.example.com/users?page=2", "next", "http://api.example.com/users?page=1", "prev")
 func (c *Ctx) Links(link ...string) {
 	if len(link) == 0 {
 		return
 	}
 	bb := bytebufferpool.Get()
-	for i := range link {
-		if i%2 == 0 {
-			_ = bb.WriteByte('<')
-			_, _ = bb.WriteString(link[i])
-			_ = bb.WriteByte('>')
-		} else {
-			_, _ = bb.WriteString(`; rel="` + link[i] + `",`)
+	for i := 0; i+1 < len(link); i += 2 {
+		if !isLinkRel(link[i+1]) {
+			continue
 		}
+		_ = bb.WriteByte('<')
+		_, _ = bb.WriteString(escapeLinkURL(link[i]))
+		_, _ = bb.WriteString(`>; rel="` + link[i+1] + `",`)
+	}
+	if bb.Len() > 0 {
+		c.setCanonical(HeaderLink, utils.TrimRight(getString(bb.Bytes()), ','))
 	}
-	c.setCanonical(HeaderLink, utils.TrimRight(getString(bb.Bytes()), ','))
 	bytebufferpool.Put(bb)
 }
 
@@ -591,6 +980,30 @@ func (c *Ctx) Locals(key string, value ...interface{}) (val interface{}) {
 	return value[0]
 }
 
+// LocalsInt returns the local value by key as an int.
+// If the value doesn't exist or is not an int, it returns the given default value or 0.
+func (c *Ctx) LocalsInt(key string, defaultValue ...int) int {
+	if val, ok := c.fasthttp.UserValue(key).(int); ok {
+		return val
+	}
+	if len(defaultValue) > 0 {
+		return defaultValue[0]
+	}
+	return 0
+}
+
+// LocalsString returns the local value by key as a string.
+// If the value doesn't exist or is not a string, it returns the given default value or "".
+func (c *Ctx) LocalsString(key string, defaultValue ...string) string {
+	if val, ok := c.fasthttp.UserValue(key).(string); ok {
+		return val
+	}
+	if len(defaultValue) > 0 {
+		return defaultValue[0]
+	}
+	return ""
+}
+
 // Location sets the response Location HTTP header to the specified path parameter.
 func (c *Ctx) Location(path string) {
 	c.setCanonical(HeaderLocation, path)
@@ -612,11 +1025,63 @@ func (c *Ctx) Method(override ...string) string {
 
 // MultipartForm parse form entries from binary.
 // This returns a map[string][]string, so given a key the value will be a string slice.
+// ErrRequestEntityTooLarge is returned if the files exceed the MultipartFilesLimit
+// or MultipartSizeLimit of the app.
 func (c *Ctx) MultipartForm() (*multipart.Form, error) {
+	if err := c.checkMultipartLimits(); err != nil {
+		return nil, err
+	}
 	return c.fasthttp.MultipartForm()
 }
 
+// checkMultipartLimits streams the parts of a multipart body before the form is parsed,
+// so it is rejected as soon as the files exceed the MultipartFilesLimit or MultipartSizeLimit
+// and the files are never stored. Invalid bodies are left to the parser of the form.
+func (c *Ctx) checkMultipartLimits() error {
+	filesLimit, sizeLimit := c.app.config.MultipartFilesLimit, int64(c.app.config.MultipartSizeLimit)
+	if c.formChecked || (filesLimit == 0 && sizeLimit == 0) {
+		return nil
+	}
+	boundary := c.fasthttp.Request.Header.MultipartFormBoundary()
+	if len(boundary) == 0 {
+		return nil
+	}
+	body, _, err := c.decodedBody()
+	if err != nil {
+		return err
+	}
+	reader := multipart.NewReader(bytes.NewReader(body), string(boundary))
+	var count int
+	var size int64
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			break
+		}
+		if part.FileName() == "" {
+			continue
+		}
+		if count++; filesLimit > 0 && count > filesLimit {
+			return ErrRequestEntityTooLarge
+		}
+		if sizeLimit > 0 {
+			// Read one byte more than the remaining size to detect files exceeding it
+			n, err := io.Copy(ioutil.Discard, io.LimitReader(part, sizeLimit-size+1))
+			if size += n; size > sizeLimit {
+				return ErrRequestEntityTooLarge
+			}
+			if err != nil {
+				break
+			}
+		}
+	}
+	c.formChecked = true
+	return nil
+}
+
 // Next executes the next method in the stack that matches the current route.
+// It returns the error of the downstream handlers, so a middleware can inspect
+// or replace it before it reaches the ErrorHandler of the app.
 func (c *Ctx) Next() (err error) {
 	// Increment handler index
 	c.indexHandler++
@@ -639,11 +1104,36 @@ func (c *Ctx) OriginalURL() string {
 }
 
 // Params is used to get the route parameters.
+// The value is URL decoded, e.g. "a%2Fb" is returned as "a/b". Use ParamsRaw
+// to get the value as it is in the path.
 // Defaults to empty string "" if the param doesn't exist.
 // If a default value is given, it will return that value if the param doesn't exist.
 // Returned value is only valid within the handler. Do not store any references.
 // Make copies or use the Immutable setting to use the value outside the Handler.
 func (c *Ctx) Params(key string, defaultValue ...string) string {
+	value, ok := c.param(key)
+	if !ok {
+		return defaultString("", defaultValue)
+	}
+	return unescapeParam(value)
+}
+
+// ParamsRaw is used to get the route parameters without URL decoding, e.g.
+// "a%2Fb" is returned as "a%2Fb".
+// Defaults to empty string "" if the param doesn't exist.
+// If a default value is given, it will return that value if the param doesn't exist.
+// Returned value is only valid within the handler. Do not store any references.
+// Make copies or use the Immutable setting to use the value outside the Handler.
+func (c *Ctx) ParamsRaw(key string, defaultValue ...string) string {
+	value, ok := c.param(key)
+	if !ok {
+		return defaultString("", defaultValue)
+	}
+	return value
+}
+
+// param returns the undecoded value of the route parameter
+func (c *Ctx) param(key string) (string, bool) {
 	if key == "*" || key == "+" {
 		key += "1"
 	}
@@ -656,14 +1146,51 @@ func (c *Ctx) Params(key string, defaultValue ...string) string {
 			if len(c.values) <= i || len(c.values[i]) == 0 {
 				break
 			}
-			return c.values[i]
+			return c.values[i], true
 		}
 	}
-	return defaultString("", defaultValue)
+	return "", false
+}
+
+// ParamsParser binds the route parameters to a struct.
+// Parameters tagged as required fail with a 400 error if they are empty,
+// the `default` tag sets the value of empty parameters.
+//  type Params struct {
+//    ID   int `params:"id,required"`
+//    Page int `params:"page" default:"1"`
+//  }
+func (c *Ctx) ParamsParser(out interface{}) error {
+	// Get decoder from pool
+	var decoder = decoderPool.Get().(*schema.Decoder)
+	defer decoderPool.Put(decoder)
+
+	// Set correct alias tag
+	decoder.SetAliasTag("params")
+
+	data := make(map[string][]string, len(c.route.Params))
+	for i := range c.route.Params {
+		// Optional parameters are empty if they are not in the path
+		if len(c.values) <= i || len(c.values[i]) == 0 {
+			continue
+		}
+		data[c.route.Params[i]] = []string{unescapeParam(c.values[i])}
+	}
+	setDefaultValues(out, "params", data)
+
+	if err := decoder.Decode(out, data); err != nil {
+		// Missing or invalid parameters are errors of the client
+		if _, ok := err.(schema.MultiError); ok {
+			return NewError(StatusBadRequest, err.Error())
+		}
+		return err
+	}
+	return nil
 }
 
 // Path returns the path part of the request URL.
-// Optionally, you could override the path.
+// Optionally, you could override the path. After an override c.Next() continues
+// with the routes of the new path that are registered after the current route,
+// routes registered before it are not matched again.
 func (c *Ctx) Path(override ...string) string {
 	if len(override) != 0 && c.path != override[0] {
 		// Set new path to context
@@ -676,10 +1203,36 @@ func (c *Ctx) Path(override ...string) string {
 		c.fasthttp.Request.URI().SetPath(c.pathOriginal)
 		// Prettify path
 		c.prettifyPath()
+		// Continue in the route stack of the new path after the current route
+		if c.route != nil {
+			tree, ok := c.app.treeStack[c.methodINT][c.treePath]
+			if !ok {
+				tree = c.app.treeStack[c.methodINT][""]
+			}
+			c.indexRoute = sort.Search(len(tree), func(i int) bool {
+				return tree[i].pos > c.route.pos
+			}) - 1
+		}
 	}
 	return c.pathOriginal
 }
 
+// Problem sends the problem details of RFC 7807 with the given status code
+// and sets the Content-Type to application/problem+json.
+// The status of the details is always set to the status code,
+// a missing title defaults to the status message.
+func (c *Ctx) Problem(status int, problem ProblemDetails) error {
+	problem.Status = status
+	if problem.Title == "" {
+		problem.Title = utils.StatusMessage(status)
+	}
+	if err := c.JSON(problem, status); err != nil {
+		return err
+	}
+	c.fasthttp.Response.Header.SetContentType(MIMEApplicationProblemJSON)
+	return nil
+}
+
 // Protocol contains the request protocol string: http or https for TLS requests.
 func (c *Ctx) Protocol() string {
 	if c.fasthttp.IsTLS() {
@@ -704,6 +1257,18 @@ func (c *Ctx) Protocol() string {
 	return scheme
 }
 
+// Queries returns a map of all query string parameters in the url.
+// If a key is repeated, the last value wins, use QueryArray to get all values.
+// Returned value is only valid within the handler. Do not store any references.
+// Make copies or use the Immutable setting to use the value outside the Handler.
+func (c *Ctx) Queries() map[string]string {
+	queries := make(map[string]string, c.fasthttp.QueryArgs().Len())
+	c.fasthttp.QueryArgs().VisitAll(func(key, val []byte) {
+		queries[getString(key)] = getString(val)
+	})
+	return queries
+}
+
 // Query returns the query string parameter in the url.
 // Defaults to empty string "" if the query doesn't exist.
 // If a default value is given, it will return that value if the query doesn't exist.
@@ -713,6 +1278,74 @@ func (c *Ctx) Query(key string, defaultValue ...string) string {
 	return defaultString(getString(c.fasthttp.QueryArgs().Peek(key)), defaultValue)
 }
 
+// QueryArray returns all values of a repeated query string parameter in the url,
+// e.g. ?a=1&a=2 returns []string{"1", "2"} for the key "a".
+// Returns nil if the query doesn't exist.
+// Returned value is only valid within the handler. Do not store any references.
+// Make copies or use the Immutable setting to use the value outside the Handler.
+func (c *Ctx) QueryArray(key string) []string {
+	raw := c.fasthttp.QueryArgs().PeekMulti(key)
+	if len(raw) == 0 {
+		return nil
+	}
+	values := make([]string, len(raw))
+	for i := range raw {
+		values[i] = getString(raw[i])
+	}
+	return values
+}
+
+// QueryBool returns the query string parameter in the url as bool.
+// Defaults to false if the query doesn't exist or is not a valid bool.
+// If a default value is given, it will return that value instead.
+func (c *Ctx) QueryBool(key string, defaultValue ...bool) bool {
+	value, err := c.QueryBoolErr(key)
+	if err != nil && len(defaultValue) > 0 {
+		return defaultValue[0]
+	}
+	return value
+}
+
+// QueryBoolErr returns the query string parameter in the url as bool,
+// an error is returned if the query doesn't exist or is not a valid bool.
+func (c *Ctx) QueryBoolErr(key string) (bool, error) {
+	return strconv.ParseBool(getString(c.fasthttp.QueryArgs().Peek(key)))
+}
+
+// QueryFloat returns the query string parameter in the url as float64.
+// Defaults to 0 if the query doesn't exist or is not a valid float.
+// If a default value is given, it will return that value instead.
+func (c *Ctx) QueryFloat(key string, defaultValue ...float64) float64 {
+	value, err := c.QueryFloatErr(key)
+	if err != nil && len(defaultValue) > 0 {
+		return defaultValue[0]
+	}
+	return value
+}
+
+// QueryFloatErr returns the query string parameter in the url as float64,
+// an error is returned if the query doesn't exist or is not a valid float.
+func (c *Ctx) QueryFloatErr(key string) (float64, error) {
+	return strconv.ParseFloat(getString(c.fasthttp.QueryArgs().Peek(key)), 64)
+}
+
+// QueryInt returns the query string parameter in the url as int.
+// Defaults to 0 if the query doesn't exist or is not a valid int.
+// If a default value is given, it will return that value instead.
+func (c *Ctx) QueryInt(key string, defaultValue ...int) int {
+	value, err := c.QueryIntErr(key)
+	if err != nil && len(defaultValue) > 0 {
+		return defaultValue[0]
+	}
+	return value
+}
+
+// QueryIntErr returns the query string parameter in the url as int,
+// an error is returned if the query doesn't exist or is not a valid int.
+func (c *Ctx) QueryIntErr(key string) (int, error) {
+	return strconv.Atoi(getString(c.fasthttp.QueryArgs().Peek(key)))
+}
+
 // QueryParser binds the query string to a struct.
 func (c *Ctx) QueryParser(out interface{}) error {
 	// Get decoder from pool
@@ -777,6 +1410,45 @@ func equalFieldType(out interface{}, kind reflect.Kind, key string) bool {
 	return false
 }
 
+// setDefaultValues adds the values of the `default` tags of out to data,
+// if the keys of the fields are missing
+func setDefaultValues(out interface{}, key string, data map[string][]string) {
+	// Get type of interface
+	outTyp := reflect.TypeOf(out)
+	// Must be a pointer to a struct to have fields
+	if outTyp == nil || outTyp.Kind() != reflect.Ptr || outTyp.Elem().Kind() != reflect.Struct {
+		return
+	}
+	outTyp = outTyp.Elem()
+	// Loop over each field
+	for i := 0; i < outTyp.NumField(); i++ {
+		typeField := outTyp.Field(i)
+		value, ok := typeField.Tag.Lookup("default")
+		if !ok {
+			continue
+		}
+		// Get tag from field if exist, without options
+		inputFieldName := strings.Split(typeField.Tag.Get(key), ",")[0]
+		if inputFieldName == "" {
+			inputFieldName = typeField.Name
+		}
+		if inputFieldName == "-" {
+			continue
+		}
+		// Field names are case insensitive like in the decoder
+		var found bool
+		for k := range data {
+			if strings.EqualFold(k, inputFieldName) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			data[inputFieldName] = []string{value}
+		}
+	}
+}
+
 var (
 	ErrRangeMalformed     = errors.New("range: malformed range header string")
 	ErrRangeUnsatisfiable = errors.New("range: unsatisfiable range")
@@ -844,10 +1516,96 @@ func (c *Ctx) Redirect(location string, status ...int) error {
 	return nil
 }
 
+// RedirectToRoute redirects to the URL of the route with the name, see Router.Name.
+// The parameters of the route are replaced by the params, e.g. fiber.Map{"id": 42},
+// the first wildcard or plus parameter is also replaced by the key "*" or "+".
+// An error is returned if there is no route with the name or a required parameter is missing.
+//  c.RedirectToRoute("user", fiber.Map{"id": 42})
+func (c *Ctx) RedirectToRoute(name string, params Map, status ...int) error {
+	location, err := c.getRouteURL(name, params)
+	if err != nil {
+		return err
+	}
+	return c.Redirect(location, status...)
+}
+
+// getRouteURL builds the URL of the route with the name from the params
+func (c *Ctx) getRouteURL(name string, params Map) (string, error) {
+	var route *Route
+	for _, routes := range c.app.Stack() {
+		for _, r := range routes {
+			if r.Name == name && !r.use {
+				route = r
+				break
+			}
+		}
+		if route != nil {
+			break
+		}
+	}
+	if route == nil {
+		return "", fmt.Errorf("redirect: route %s not found", name)
+	}
+
+	var sb strings.Builder
+	var skipped bool
+	for _, seg := range parseRoute(route.Path).segs {
+		if !seg.IsParam {
+			sb.WriteString(seg.Const)
+			continue
+		}
+		val, ok := params[seg.ParamName]
+		if !ok && seg.IsGreedy && seg.ParamName[1:] == "1" {
+			val, ok = params[seg.ParamName[:1]]
+		}
+		if !ok || val == nil {
+			if !seg.IsOptional {
+				return "", fmt.Errorf("redirect: parameter %s of route %s is missing", seg.ParamName, name)
+			}
+			skipped = true
+			continue
+		}
+		value := fmt.Sprint(val)
+		if seg.IsGreedy {
+			// Greedy parameters keep their slashes
+			parts := strings.Split(value, "/")
+			for i := range parts {
+				parts[i] = url.PathEscape(parts[i])
+			}
+			sb.WriteString(strings.Join(parts, "/"))
+		} else {
+			sb.WriteString(url.PathEscape(value))
+		}
+	}
+	location := sb.String()
+	// Remove the slash left by a missing optional parameter
+	if skipped && len(location) > 1 {
+		location = utils.TrimRight(location, '/')
+	}
+	return location, nil
+}
+
 // Render a template with data and sends a text/html response.
+// The variables of BindVars are added to a nil or Map bind, keys of the bind take precedence.
 // We support the following engines: html, amber, handlebars, mustache, pug
 func (c *Ctx) Render(name string, bind interface{}, layouts ...string) error {
 	var err error
+	// Merge the bound variables
+	if len(c.bound) > 0 {
+		switch vars := bind.(type) {
+		case nil:
+			bind = c.bound
+		case Map:
+			merged := make(Map, len(c.bound)+len(vars))
+			for key, val := range c.bound {
+				merged[key] = val
+			}
+			for key, val := range vars {
+				merged[key] = val
+			}
+			bind = merged
+		}
+	}
 	// Get new buffer from pool
 	buf := bytebufferpool.Get()
 	defer bytebufferpool.Put(buf)
@@ -882,6 +1640,7 @@ func (c *Ctx) Render(name string, bind interface{}, layouts ...string) error {
 }
 
 // Route returns the matched Route struct.
+// Route().Path is the registered pattern, e.g. "/users/:id" for "/users/42".
 func (c *Ctx) Route() *Route {
 	if c.route == nil {
 		// Fallback for fasthttp error handler
@@ -896,8 +1655,24 @@ func (c *Ctx) Route() *Route {
 	return c.route
 }
 
+// RoutePattern returns the registered pattern of the matched route, e.g. "/users/:id".
+// Routes of mounted sub-apps include the mount path.
+// Unlike the requested path it is suitable as low-cardinality metrics label.
+func (c *Ctx) RoutePattern() string {
+	pattern := c.Route().Path
+	if mountPath := c.app.MountPath(); mountPath != "" {
+		return getGroupPath(mountPath, pattern)
+	}
+	return pattern
+}
+
 // SaveFile saves any multipart file to disk.
+// ErrRequestEntityTooLarge is returned if the files of the request exceed the
+// MultipartFilesLimit or MultipartSizeLimit of the app.
 func (c *Ctx) SaveFile(fileheader *multipart.FileHeader, path string) error {
+	if err := c.checkMultipartLimits(); err != nil {
+		return err
+	}
 	return fasthttp.SaveMultipartFile(fileheader, path)
 }
 
@@ -914,38 +1689,108 @@ func (c *Ctx) Send(body []byte) error {
 	return nil
 }
 
-var sendFileOnce sync.Once
-var sendFileFS *fasthttp.FS
-var sendFileHandler fasthttp.RequestHandler
+// SendFileConfig defines the options for c.SendFileWithOptions
+type SendFileConfig struct {
+	// When set to true, the file is compressed if the client supports it.
+	// Optional. Default value false
+	Compress bool `json:"compress"`
+
+	// When set to true, enables byte range requests.
+	// Optional. Default value false
+	ByteRange bool `json:"byte_range"`
+
+	// When set to true, the Content-Disposition header is set to attachment,
+	// so the browser prompts the user to download the file.
+	// Optional. Default value false
+	Download bool `json:"download"`
+
+	// Expiration duration for inactive file handlers, rounded up to a
+	// power of two seconds and limited to maxSendFileCacheDuration.
+	// Optional. Default value 10 * time.Second
+	CacheDuration time.Duration `json:"cache_duration"`
+
+	// ModifyResponse is called after the file was served successfully,
+	// it can be used to alter the response headers.
+	// Optional. Default value nil
+	ModifyResponse Handler `json:"-"`
+}
+
+// sendFileKey identifies the file handler for a combination of SendFile options
+type sendFileKey struct {
+	byteRange            bool
+	cacheDuration        time.Duration
+	compressedFileSuffix string
+}
+
+// maxSendFileCacheDuration limits the CacheDuration of SendFileConfig
+const maxSendFileCacheDuration = 1 << 16 * time.Second
+
+var sendFileMutex sync.Mutex
+var sendFileHandlers = make(map[sendFileKey]fasthttp.RequestHandler)
+
+// sendFileCacheDuration normalizes the cache duration of the file handlers to
+// a power of two seconds, so arbitrary durations share a small set of handlers
+func sendFileCacheDuration(d time.Duration) time.Duration {
+	normalized := time.Second
+	for normalized < d && normalized < maxSendFileCacheDuration {
+		normalized *= 2
+	}
+	return normalized
+}
 
 // SendFile transfers the file from the given path.
 // The file is not compressed by default, enable this by passing a 'true' argument
 // Sets the Content-Type response HTTP header field based on the filenames extension.
+// A weak ETag of the size and modification time and the Last-Modified header are set,
+// conditional requests of an unchanged file are answered with 304 Not Modified.
 func (c *Ctx) SendFile(file string, compress ...bool) error {
+	return c.SendFileWithOptions(file, SendFileConfig{
+		Compress:  len(compress) > 0 && compress[0],
+		ByteRange: true,
+	})
+}
+
+// SendFileWithOptions transfers the file from the given path with the given options.
+// Sets the Content-Type response HTTP header field based on the filenames extension.
+func (c *Ctx) SendFileWithOptions(file string, config SendFileConfig) error {
 	// Save the filename, we will need it in the error message if the file isn't found
 	filename := file
 
+	// Set default values
+	if config.CacheDuration <= 0 {
+		config.CacheDuration = 10 * time.Second
+	}
+
 	// https://github.com/valyala/fasthttp/blob/master/fs.go#L81
-	sendFileOnce.Do(func() {
-		sendFileFS = &fasthttp.FS{
+	key := sendFileKey{
+		byteRange:            config.ByteRange,
+		cacheDuration:        sendFileCacheDuration(config.CacheDuration),
+		compressedFileSuffix: c.app.config.CompressedFileSuffix,
+	}
+	sendFileMutex.Lock()
+	sendFileHandler, ok := sendFileHandlers[key]
+	if !ok {
+		fs := &fasthttp.FS{
 			Root:                 "/",
 			GenerateIndexPages:   false,
-			AcceptByteRange:      true,
+			AcceptByteRange:      key.byteRange,
 			Compress:             true,
-			CompressedFileSuffix: c.app.config.CompressedFileSuffix,
-			CacheDuration:        10 * time.Second,
+			CompressedFileSuffix: key.compressedFileSuffix,
+			CacheDuration:        key.cacheDuration,
 			IndexNames:           []string{"index.html"},
 			PathNotFound: func(ctx *fasthttp.RequestCtx) {
 				ctx.Response.SetStatusCode(StatusNotFound)
 			},
 		}
-		sendFileHandler = sendFileFS.NewRequestHandler()
-	})
+		sendFileHandler = fs.NewRequestHandler()
+		sendFileHandlers[key] = sendFileHandler
+	}
+	sendFileMutex.Unlock()
 
 	// Keep original path for mutable params
 	c.pathOriginal = utils.SafeString(c.pathOriginal)
 	// Disable compression
-	if len(compress) <= 0 || !compress[0] {
+	if !config.Compress {
 		// https://github.com/valyala/fasthttp/blob/master/fs.go#L46
 		c.fasthttp.Request.Header.Del(HeaderAcceptEncoding)
 	}
@@ -960,8 +1805,28 @@ func (c *Ctx) SendFile(file string, compress ...bool) error {
 			file += "/"
 		}
 	}
+	// Answer conditional requests of unchanged files with 304 Not Modified,
+	// the Last-Modified header and If-Modified-Since are handled by the file handler
+	var etag string
+	if info, err := os.Stat(file); err == nil && info.Mode().IsRegular() {
+		etag = fileETag(info)
+		if clientETag := c.Get(HeaderIfNoneMatch); clientETag != "" && (c.method == MethodGet || c.method == MethodHead) {
+			if matchETag(clientETag, etag) {
+				c.setCanonical(normalizedHeaderETag, etag)
+				c.fasthttp.Response.Header.Set(HeaderLastModified, info.ModTime().UTC().Format(http.TimeFormat))
+				c.fasthttp.ResetBody()
+				return c.SendStatus(StatusNotModified)
+			}
+			// If-None-Match takes precedence over If-Modified-Since
+			c.fasthttp.Request.Header.Del(HeaderIfModifiedSince)
+		}
+	}
 	// Set new URI for fileHandler
 	c.fasthttp.Request.SetRequestURI(file)
+	// Prompt the client to download the file, the Content-Type is set by the file handler
+	if config.Download {
+		c.Attachment(file)
+	}
 	// Save status code
 	status := c.fasthttp.Response.StatusCode()
 	// Serve file
@@ -974,11 +1839,28 @@ func (c *Ctx) SendFile(file string, compress ...bool) error {
 	}
 	// Check for error
 	if status != StatusNotFound && fsStatus == StatusNotFound {
+		// The error response is no download
+		if config.Download {
+			c.fasthttp.Response.Header.Del(HeaderContentDisposition)
+		}
 		return NewError(StatusNotFound, fmt.Sprintf("sendfile: file %s not found", filename))
 	}
+	if etag != "" && (fsStatus == StatusOK || fsStatus == StatusPartialContent) {
+		c.setCanonical(normalizedHeaderETag, etag)
+	}
+	if config.ModifyResponse != nil {
+		return config.ModifyResponse(c)
+	}
 	return nil
 }
 
+// SendHTML sets the Content-Type to text/html and sends the HTML body as is,
+// untrusted text should be escaped with c.EscapeHTML.
+func (c *Ctx) SendHTML(body string) error {
+	c.fasthttp.Response.Header.SetContentType(MIMETextHTMLCharsetUTF8)
+	return c.SendString(body)
+}
+
 // SendStatus sets the HTTP status code and if the response body is empty,
 // it sets the correct status message in the body.
 func (c *Ctx) SendStatus(status int) error {
@@ -992,6 +1874,22 @@ func (c *Ctx) SendStatus(status int) error {
 	return nil
 }
 
+// SendStatusJSON sets the HTTP status code and if the response body is empty,
+// it sets the status message as JSON body, e.g. {"error":"Forbidden","code":403}
+func (c *Ctx) SendStatusJSON(status int) error {
+	c.Status(status)
+
+	// Only set status body when there is no response body
+	if len(c.fasthttp.Response.Body()) == 0 {
+		return c.JSON(struct {
+			Error string `json:"error"`
+			Code  int    `json:"code"`
+		}{utils.StatusMessage(status), status})
+	}
+
+	return nil
+}
+
 // SendString sets the HTTP response body for string types.
 // This means no type assertion, recommended for faster performance
 func (c *Ctx) SendString(body string) error {
@@ -1012,6 +1910,19 @@ func (c *Ctx) SendStream(stream io.Reader, size ...int) error {
 	return nil
 }
 
+// SendEventStream streams Server-Sent Events written by gen to the client,
+// e.g. with Event.Write which flushes every event. The response is sent with
+// the text/event-stream Content-Type, it isn't cached, buffered by proxies or compressed.
+// gen runs after the handler returned, it ends the stream by returning or on a failed write.
+func (c *Ctx) SendEventStream(gen func(w *bufio.Writer)) error {
+	c.fasthttp.Response.Header.SetContentType(MIMETextEventStream)
+	c.fasthttp.Response.Header.Set(HeaderCacheControl, "no-cache")
+	c.fasthttp.Response.Header.Set(HeaderXAccelBuffering, "no")
+	c.fasthttp.Response.SetBodyStreamWriter(gen)
+
+	return nil
+}
+
 // Set sets the response's HTTP header field to the specified key, value.
 func (c *Ctx) Set(key string, val string) {
 	c.fasthttp.Response.Header.Set(key, removeNewLines(val))
@@ -1074,19 +1985,169 @@ func (c *Ctx) Type(extension string, charset ...string) *Ctx {
 	return c
 }
 
+// UserContext returns a context implementation that was set by
+// user earlier or returns a non-nil, empty context, if it was not set earlier.
+// The context is canceled when the client closes the connection,
+// unless DisableClientDisconnectCancel is set.
+func (c *Ctx) UserContext() context.Context {
+	if c.userContext == nil {
+		c.userContext = c.cancelOnDisconnect(context.Background())
+	}
+	return c.userContext
+}
+
+// SetUserContext sets a context implementation by user.
+// A context derived from it is canceled when the client closes the connection,
+// unless DisableClientDisconnectCancel is set.
+// The context is reset when the Ctx is released back into the pool.
+func (c *Ctx) SetUserContext(ctx context.Context) {
+	c.userContext = c.cancelOnDisconnect(ctx)
+}
+
+// SetDeadline sets the deadline of the request, the user context is
+// canceled when the deadline passes. Like with context.WithDeadline,
+// a later deadline does not extend an earlier one.
+// The user context is canceled as well when the Ctx is released back into the pool.
+//  c.SetDeadline(time.Now().Add(2 * time.Second))
+//  rows, err := db.QueryContext(c.UserContext(), query)
+func (c *Ctx) SetDeadline(deadline time.Time) {
+	ctx, cancel := context.WithDeadline(c.UserContext(), deadline)
+	c.addCancel(cancel)
+	c.userContext = ctx
+}
+
+// addCancel chains the cancel function of a derived user context
+func (c *Ctx) addCancel(cancel context.CancelFunc) {
+	if prev := c.cancel; prev != nil {
+		c.cancel = func() {
+			cancel()
+			prev()
+		}
+	} else {
+		c.cancel = cancel
+	}
+}
+
+// disconnectWatcher cancels the user contexts of a request when the client closes the connection
+type disconnectWatcher struct {
+	mutex   sync.Mutex
+	closed  bool
+	cancels []context.CancelFunc
+}
+
+// add registers the cancel function of a user context, it's called
+// immediately if the client already closed the connection
+func (w *disconnectWatcher) add(cancel context.CancelFunc) {
+	w.mutex.Lock()
+	if w.closed {
+		cancel()
+	} else {
+		w.cancels = append(w.cancels, cancel)
+	}
+	w.mutex.Unlock()
+}
+
+// cancel cancels the registered user contexts
+func (w *disconnectWatcher) cancel() {
+	w.mutex.Lock()
+	w.closed = true
+	for _, cancel := range w.cancels {
+		cancel()
+	}
+	w.cancels = nil
+	w.mutex.Unlock()
+}
+
+// cancelOnDisconnect derives a context that is canceled when the client closes the connection
+func (c *Ctx) cancelOnDisconnect(ctx context.Context) context.Context {
+	if c.app.config.DisableClientDisconnectCancel {
+		return ctx
+	}
+	// The connection is watched from the first user context on
+	if !c.watched {
+		c.watched = true
+		c.disconnect = c.watchDisconnect()
+	}
+	if c.disconnect == nil {
+		return ctx
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	c.addCancel(cancel)
+	c.disconnect.add(cancel)
+	return ctx
+}
+
+// watchDisconnect starts a goroutine that checks the connection of the request
+// until the Ctx is released, it returns nil if disconnects can't be detected
+func (c *Ctx) watchDisconnect() *disconnectWatcher {
+	conn, ok := c.fasthttp.Conn().(syscall.Conn)
+	if !ok || !canPeekConn {
+		return nil
+	}
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return nil
+	}
+	w := &disconnectWatcher{}
+	stop := make(chan struct{})
+	c.addCancel(func() {
+		close(stop)
+	})
+
+	go func() {
+		ticker := time.NewTicker(disconnectInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if connClosed(raw) {
+					w.cancel()
+					return
+				}
+			}
+		}
+	}()
+	return w
+}
+
 // Vary adds the given header field to the Vary response header.
 // This will append the header, if not already listed, otherwise leaves it listed in the current location.
 func (c *Ctx) Vary(fields ...string) {
 	c.Append(HeaderVary, fields...)
 }
 
-// Write appends p into response body.
+// Write appends p into response body, so the Ctx can be used as an io.Writer,
+// e.g. fmt.Fprintf(c, "Hello, %s!", name). The status and headers that are
+// already set are kept.
 func (c *Ctx) Write(p []byte) (int, error) {
 	c.fasthttp.Response.AppendBody(p)
 	return len(p), nil
 }
 
-// WriteString appends s to response body.
+// WriteEarlyHints sends a 103 Early Hints informational response with the
+// links as Link headers, so the client can preload them while the handler
+// prepares the final response.
+//  c.WriteEarlyHints([]string{"</style.css>; rel=preload; as=style"})
+// The response is written directly to the connection and only to HTTP/1.1
+// clients, nothing is sent to HTTP/1.0 clients.
+func (c *Ctx) WriteEarlyHints(links []string) error {
+	if len(links) == 0 || !c.fasthttp.Request.Header.IsHTTP11() {
+		return nil
+	}
+	buf := bytebufferpool.Get()
+	defer bytebufferpool.Put(buf)
+	_, _ = buf.WriteString("HTTP/1.1 103 Early Hints\r\n")
+	for _, link := range links {
+		_, _ = buf.WriteString(HeaderLink + ": " + removeNewLines(link) + "\r\n")
+	}
+	_, _ = buf.WriteString("\r\n")
+	_, err := c.fasthttp.Conn().Write(buf.Bytes())
+	return err
+}
+
+// WriteString appends s to response body like Write.
 func (c *Ctx) WriteString(s string) (int, error) {
 	c.fasthttp.Response.AppendBodyString(s)
 	return len(s), nil
@@ -1098,6 +2159,28 @@ func (c *Ctx) XHR() bool {
 	return utils.EqualsFold(utils.UnsafeBytes(c.Get(HeaderXRequestedWith)), []byte("xmlhttprequest"))
 }
 
+// XML converts any interface to XML with the XMLEncoder of the app,
+// the XML header is prepended unless the encoded body has one.
+// This method also sets the content header to application/xml.
+// An optional status code can be passed to set the response status inline.
+func (c *Ctx) XML(data interface{}, status ...int) error {
+	raw, err := c.app.config.XMLEncoder(data)
+	if err != nil {
+		return err
+	}
+	if bytes.HasPrefix(raw, []byte("<?xml")) {
+		c.fasthttp.Response.SetBodyRaw(raw)
+	} else {
+		c.fasthttp.Response.SetBodyString(xml.Header)
+		c.fasthttp.Response.AppendBody(raw)
+	}
+	c.fasthttp.Response.Header.SetContentType(MIMEApplicationXML)
+	if len(status) > 0 {
+		c.Status(status[0])
+	}
+	return nil
+}
+
 // prettifyPath ...
 func (c *Ctx) prettifyPath() {
 	// If UnescapePath enabled, we decode the path
@@ -10,14 +10,22 @@ package fiber
 import (
 	"bufio"
 	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"mime/multipart"
+	"net"
+	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"reflect"
+	"runtime"
 	"strconv"
 	"strings"
 	"sync"
@@ -25,8 +33,11 @@ import (
 	"text/template"
 	"time"
 
+	"github.com/andybalholm/brotli"
 	"github.com/gofiber/fiber/v2/internal/bytebufferpool"
+	"github.com/gofiber/fiber/v2/internal/encoding/json"
 	"github.com/gofiber/fiber/v2/utils"
+	"github.com/klauspost/compress/zstd"
 	"github.com/valyala/fasthttp"
 )
 
@@ -57,6 +68,41 @@ func Test_Ctx_Accepts(t *testing.T) {
 	utils.AssertEqual(t, "html", c.Accepts("html"))
 }
 
+// go test -run Test_Ctx_Accepts_Quality
+func Test_Ctx_Accepts_Quality(t *testing.T) {
+	t.Parallel()
+	app := New()
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+
+	c.Request().Header.Set(HeaderAccept, "text/*;q=0.5, application/json;q=0.9")
+	utils.AssertEqual(t, "json", c.Accepts("html", "json"))
+	utils.AssertEqual(t, "html", c.Accepts("html", "png"))
+
+	// */* matches the first offer
+	c.Request().Header.Set(HeaderAccept, "*/*")
+	utils.AssertEqual(t, "json", c.Accepts("json", "html"))
+	utils.AssertEqual(t, "html", c.Accepts("html", "json"))
+
+	// the most specific media range decides the quality
+	c.Request().Header.Set(HeaderAccept, "text/*, text/plain;q=0.2, */*;q=0.1")
+	utils.AssertEqual(t, "html", c.Accepts("txt", "html"))
+	utils.AssertEqual(t, "txt", c.Accepts("txt", "png"))
+
+	// exact types are preferred over ranges of equal quality
+	c.Request().Header.Set(HeaderAccept, "*/*, application/xml")
+	utils.AssertEqual(t, "xml", c.Accepts("json", "xml"))
+
+	// q=0 rejects the type
+	c.Request().Header.Set(HeaderAccept, "application/json;q=0, */*")
+	utils.AssertEqual(t, "html", c.Accepts("json", "html"))
+	utils.AssertEqual(t, "", c.Accepts("json"))
+
+	// the header order decides on equal quality and specificity
+	c.Request().Header.Set(HeaderAccept, "application/json, text/html")
+	utils.AssertEqual(t, "json", c.Accepts("html", "json"))
+}
+
 // go test -v -run=^$ -bench=Benchmark_Ctx_Accepts -benchmem -count=4
 func Benchmark_Ctx_Accepts(b *testing.B) {
 	app := New()
@@ -129,6 +175,23 @@ func Test_Ctx_AcceptsEncodings(t *testing.T) {
 	c.Request().Header.Set(HeaderAcceptEncoding, "deflate, gzip;q=1.0, *;q=0.5")
 	utils.AssertEqual(t, "gzip", c.AcceptsEncodings("gzip"))
 	utils.AssertEqual(t, "abc", c.AcceptsEncodings("abc"))
+
+	c.Request().Header.Set(HeaderAcceptEncoding, "br;q=1.0, gzip;q=0.5")
+	utils.AssertEqual(t, "br", c.AcceptsEncodings("gzip", "br"))
+	utils.AssertEqual(t, "gzip", c.AcceptsEncodings("deflate", "gzip"))
+	utils.AssertEqual(t, "gzip", c.AcceptsEncodings("identity", "gzip"))
+	utils.AssertEqual(t, "identity", c.AcceptsEncodings("deflate", "identity"))
+	utils.AssertEqual(t, "", c.AcceptsEncodings("deflate"))
+
+	c.Request().Header.Set(HeaderAcceptEncoding, "gzip, deflate;q=0, identity;q=0")
+	utils.AssertEqual(t, "", c.AcceptsEncodings("identity", "deflate"))
+	utils.AssertEqual(t, "gzip", c.AcceptsEncodings("identity", "gzip"))
+
+	c.Request().Header.Set(HeaderAcceptEncoding, "gzip, *;q=0")
+	utils.AssertEqual(t, "", c.AcceptsEncodings("identity"))
+
+	c.Request().Header.Set(HeaderAcceptEncoding, "deflate, gzip")
+	utils.AssertEqual(t, "deflate", c.AcceptsEncodings("gzip", "deflate"))
 }
 
 // go test -v -run=^$ -bench=Benchmark_Ctx_AcceptsEncodings -benchmem -count=4
@@ -215,6 +278,21 @@ func Test_Ctx_Append(t *testing.T) {
 	utils.AssertEqual(t, "XHello, World, Hello", string(c.Response().Header.Peek("X3-Test")))
 	utils.AssertEqual(t, "XHello, Hello, HelloZ, YHello", string(c.Response().Header.Peek("X4-Test")))
 	utils.AssertEqual(t, "", string(c.Response().Header.Peek("x-custom-header")))
+
+	c.Append("X-Foo", "bar")
+	c.Append("X-Foo", "baz")
+	utils.AssertEqual(t, "bar, baz", string(c.Response().Header.Peek("X-Foo")))
+
+	// cookies are not joined or overwritten by cookies of the same name
+	c.Append(HeaderSetCookie, "a=1; path=/", "b=2")
+	c.Append("set-cookie", "c=3", "a=2; path=/admin")
+	cookies := []string{}
+	c.Response().Header.VisitAll(func(key, value []byte) {
+		if string(key) == HeaderSetCookie {
+			cookies = append(cookies, string(value))
+		}
+	})
+	utils.AssertEqual(t, []string{"a=1; path=/", "b=2", "c=3", "a=2; path=/admin"}, cookies)
 }
 
 // go test -v -run=^$ -bench=Benchmark_Ctx_Append -benchmem -count=4
@@ -250,6 +328,43 @@ func Test_Ctx_Attachment(t *testing.T) {
 	utils.AssertEqual(t, `attachment; filename="another+document.pdf%22%0D%0ABla%3A+%22fasel"`, string(c.Response().Header.Peek(HeaderContentDisposition)))
 }
 
+// go test -run Test_Ctx_AttachmentStream
+func Test_Ctx_AttachmentStream(t *testing.T) {
+	t.Parallel()
+	app := New()
+	app.Get("/report", func(c *Ctx) error {
+		r, w := io.Pipe()
+		go func() {
+			for i := 1; i <= 3; i++ {
+				fmt.Fprintf(w, "%d,user%d\n", i, i)
+			}
+			w.Close()
+		}()
+		return c.AttachmentStream("report.csv", r)
+	})
+	app.Get("/sized", func(c *Ctx) error {
+		return c.AttachmentStream("report.csv", strings.NewReader("1,user1\n"), 8)
+	})
+
+	resp, err := app.Test(httptest.NewRequest(MethodGet, "/report", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusOK, resp.StatusCode)
+	utils.AssertEqual(t, `attachment; filename="report.csv"`, resp.Header.Get(HeaderContentDisposition))
+	utils.AssertEqual(t, []string{"chunked"}, resp.TransferEncoding)
+	body, err := ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "1,user1\n2,user2\n3,user3\n", string(body))
+
+	// the size is sent as Content-Length
+	resp, err = app.Test(httptest.NewRequest(MethodGet, "/sized", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, `attachment; filename="report.csv"`, resp.Header.Get(HeaderContentDisposition))
+	utils.AssertEqual(t, int64(8), resp.ContentLength)
+	body, err = ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "1,user1\n", string(body))
+}
+
 // go test -v -run=^$ -bench=Benchmark_Ctx_Attachment -benchmem -count=4
 func Benchmark_Ctx_Attachment(b *testing.B) {
 	app := New()
@@ -276,6 +391,20 @@ func Test_Ctx_BaseURL(t *testing.T) {
 	utils.AssertEqual(t, "http://google.com", c.BaseURL())
 }
 
+// go test -run Test_Ctx_BaseURL_Proxy
+func Test_Ctx_BaseURL_Proxy(t *testing.T) {
+	t.Parallel()
+	app := New(Config{EnableForwardedHost: true})
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+	c.Request().SetRequestURI("/test?search=demo")
+	c.Request().Header.SetHost("internal:8080")
+	c.Request().Header.Set(HeaderXForwardedProto, "https")
+	c.Request().Header.Set(HeaderXForwardedHost, "example.com, proxy.internal")
+	utils.AssertEqual(t, "https://example.com", c.BaseURL())
+	utils.AssertEqual(t, "/test?search=demo", c.OriginalURL())
+}
+
 // go test -v -run=^$ -bench=Benchmark_Ctx_BaseURL -benchmem
 func Benchmark_Ctx_BaseURL(b *testing.B) {
 	app := New()
@@ -339,6 +468,356 @@ func Test_Ctx_BodyParser(t *testing.T) {
 	testDecodeParserError(MIMEMultipartForm+`;boundary="b"`, "--b")
 }
 
+// go test -run Test_Ctx_BodyParser_CustomBinder
+func Test_Ctx_BodyParser_CustomBinder(t *testing.T) {
+	t.Parallel()
+	app := New()
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+
+	// Minimal msgpack decoder for fixmaps of fixstr keys and fixstr or positive fixint values
+	RegisterCustomBinder("application/msgpack", func(data []byte, out interface{}) error {
+		if len(data) == 0 || data[0]&0xf0 != 0x80 {
+			return errors.New("msgpack: expected fixmap")
+		}
+		size, m, data := int(data[0]&0x0f), make(map[string]interface{}), data[1:]
+		readStr := func() (string, error) {
+			if len(data) == 0 || data[0]&0xe0 != 0xa0 || len(data) < 1+int(data[0]&0x1f) {
+				return "", errors.New("msgpack: expected fixstr")
+			}
+			n := 1 + int(data[0]&0x1f)
+			str := string(data[1:n])
+			data = data[n:]
+			return str, nil
+		}
+		for i := 0; i < size; i++ {
+			key, err := readStr()
+			if err != nil {
+				return err
+			}
+			if len(data) > 0 && data[0] < 0x80 {
+				m[key], data = int(data[0]), data[1:]
+				continue
+			}
+			if m[key], err = readStr(); err != nil {
+				return err
+			}
+		}
+		raw, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(raw, out)
+	})
+
+	type Demo struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	// {"name": "john", "age": 42}
+	body := []byte{0x82, 0xa4, 'n', 'a', 'm', 'e', 0xa4, 'j', 'o', 'h', 'n', 0xa3, 'a', 'g', 'e', 42}
+	c.Request().Header.SetContentType("application/msgpack; charset=binary")
+	c.Request().SetBody(body)
+	d := new(Demo)
+	utils.AssertEqual(t, nil, c.BodyParser(d))
+	utils.AssertEqual(t, Demo{Name: "john", Age: 42}, *d)
+
+	// the content type is case-insensitive and can be given explicitly
+	c.Request().Header.SetContentType("")
+	d = new(Demo)
+	utils.AssertEqual(t, nil, c.BodyParser(d, "Application/MsgPack"))
+	utils.AssertEqual(t, Demo{Name: "john", Age: 42}, *d)
+
+	c.Request().Header.SetContentType("application/msgpack")
+	c.Request().SetBody([]byte("{}"))
+	utils.AssertEqual(t, "msgpack: expected fixmap", c.BodyParser(d).Error())
+
+	// unknown content types are still rejected
+	c.Request().Header.SetContentType("application/x-protobuf")
+	utils.AssertEqual(t, "bodyparser: cannot parse content-type: application/x-protobuf", c.BodyParser(d).Error())
+}
+
+// go test -run Test_Ctx_BodyParser_Nested
+func Test_Ctx_BodyParser_Nested(t *testing.T) {
+	t.Parallel()
+	app := New()
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+
+	type Item struct {
+		Name string `form:"name"`
+	}
+	type Address struct {
+		City string `form:"city"`
+		Zip  *int   `form:"zip"`
+	}
+	type Demo struct {
+		Name    string            `form:"name"`
+		Address Address           `form:"address"`
+		Billing *Address          `form:"billing"`
+		Items   []Item            `form:"items"`
+		Tags    []string          `form:"tags"`
+		Meta    map[string]string `form:"meta"`
+		Counts  map[string]int    `form:"counts"`
+	}
+
+	testDecodeParser := func(contentType, body string) {
+		// Parsed form arguments are cached by the request
+		c.Request().Reset()
+		c.Request().Header.SetContentType(contentType)
+		c.Request().SetBody([]byte(body))
+		c.Request().Header.SetContentLength(len(body))
+		d := new(Demo)
+		utils.AssertEqual(t, nil, c.BodyParser(d))
+		utils.AssertEqual(t, "john", d.Name)
+		utils.AssertEqual(t, "NYC", d.Address.City)
+		utils.AssertEqual(t, 10001, *d.Address.Zip)
+		utils.AssertEqual(t, "LA", d.Billing.City)
+		utils.AssertEqual(t, []Item{{"a"}, {"b"}}, d.Items)
+		utils.AssertEqual(t, []string{"x", "y"}, d.Tags)
+		utils.AssertEqual(t, map[string]string{"color": "red"}, d.Meta)
+		utils.AssertEqual(t, map[string]int{"views": 3}, d.Counts)
+	}
+
+	// bracket notation
+	testDecodeParser(MIMEApplicationForm, "name=john&address[city]=NYC&address[zip]=10001&billing[city]=LA&items[0][name]=a&items[1][name]=b&tags[]=x&tags[]=y&meta[color]=red&counts[views]=3")
+	// dotted notation
+	testDecodeParser(MIMEApplicationForm, "name=john&address.city=NYC&address.zip=10001&billing.city=LA&items.0.name=a&items.1.name=b&tags=x&tags=y&meta.color=red&counts.views=3")
+	// mixed notation
+	testDecodeParser(MIMEApplicationForm, "name=john&address[city]=NYC&address.zip=10001&billing[city]=LA&items[0].name=a&items.1[name]=b&tags[]=x&tags=y&meta[color]=red&counts.views=3")
+	testDecodeParser(MIMEMultipartForm+`;boundary="b"`, "--b\r\nContent-Disposition: form-data; name=\"name\"\r\n\r\njohn\r\n"+
+		"--b\r\nContent-Disposition: form-data; name=\"address[city]\"\r\n\r\nNYC\r\n"+
+		"--b\r\nContent-Disposition: form-data; name=\"address[zip]\"\r\n\r\n10001\r\n"+
+		"--b\r\nContent-Disposition: form-data; name=\"billing[city]\"\r\n\r\nLA\r\n"+
+		"--b\r\nContent-Disposition: form-data; name=\"items[0][name]\"\r\n\r\na\r\n"+
+		"--b\r\nContent-Disposition: form-data; name=\"items[1][name]\"\r\n\r\nb\r\n"+
+		"--b\r\nContent-Disposition: form-data; name=\"tags[]\"\r\n\r\nx\r\n"+
+		"--b\r\nContent-Disposition: form-data; name=\"tags[]\"\r\n\r\ny\r\n"+
+		"--b\r\nContent-Disposition: form-data; name=\"meta[color]\"\r\n\r\nred\r\n"+
+		"--b\r\nContent-Disposition: form-data; name=\"counts[views]\"\r\n\r\n3\r\n--b--")
+
+	// invalid map values are reported
+	c.Request().Reset()
+	c.Request().Header.SetContentType(MIMEApplicationForm)
+	c.Request().SetBody([]byte("counts[views]=many"))
+	utils.AssertEqual(t, false, c.BodyParser(new(Demo)) == nil)
+
+	// maps of values without converter are ignored
+	type Unsupported struct {
+		Grid  map[string][3]int         `form:"grid"`
+		Lists map[string][]int          `form:"lists"`
+		Maps  map[string]map[string]int `form:"maps"`
+	}
+	c.Request().Reset()
+	c.Request().Header.SetContentType(MIMEApplicationForm)
+	c.Request().SetBody([]byte("grid[a]=1&lists[b]=2&maps[c]=3"))
+	u := new(Unsupported)
+	utils.AssertEqual(t, nil, c.BodyParser(u))
+	utils.AssertEqual(t, 0, len(u.Grid))
+	utils.AssertEqual(t, 0, len(u.Lists))
+	utils.AssertEqual(t, 0, len(u.Maps))
+}
+
+// go test -run Test_Ctx_BodyParser_FormArrays
+func Test_Ctx_BodyParser_FormArrays(t *testing.T) {
+	t.Parallel()
+	app := New()
+	type Post struct {
+		Title string   `form:"title"`
+		Tags  []string `form:"tag"`
+		IDs   []int    `form:"id"`
+	}
+	app.Post("/", func(c *Ctx) error {
+		post := new(Post)
+		if err := c.BodyParser(post); err != nil {
+			return err
+		}
+		return c.JSON(post)
+	})
+
+	testCases := []struct {
+		body     string
+		expected string
+	}{
+		{"title=go&tag=a&tag=b&id=1&id=2", `{"Title":"go","Tags":["a","b"],"IDs":[1,2]}`},
+		{"tag[]=a&tag[]=b", `{"Title":"","Tags":["a","b"],"IDs":null}`},
+		// a single value fills a slice with one element, commas are kept
+		{"tag=a%2Cb", `{"Title":"","Tags":["a,b"],"IDs":null}`},
+	}
+	for _, tc := range testCases {
+		req := httptest.NewRequest(MethodPost, "/", strings.NewReader(tc.body))
+		req.Header.Set(HeaderContentType, MIMEApplicationForm)
+		resp, err := app.Test(req)
+		utils.AssertEqual(t, nil, err, "app.Test(req)")
+		utils.AssertEqual(t, StatusOK, resp.StatusCode, tc.body)
+		body, err := ioutil.ReadAll(resp.Body)
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, tc.expected, string(body), tc.body)
+	}
+}
+
+// go test -run Test_Ctx_BodyParser_ContentType
+func Test_Ctx_BodyParser_ContentType(t *testing.T) {
+	t.Parallel()
+	app := New()
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+
+	type Demo struct {
+		Name string `json:"name" xml:"name" form:"name"`
+	}
+
+	testDecodeParser := func(override, body string) {
+		c.Request().Header.SetContentType(MIMETextPlain)
+		c.Request().SetBody([]byte(body))
+		c.Request().Header.SetContentLength(len(body))
+		d := new(Demo)
+		utils.AssertEqual(t, nil, c.BodyParser(d, override))
+		utils.AssertEqual(t, "john", d.Name)
+	}
+
+	testDecodeParser(MIMEApplicationJSON, `{"name":"john"}`)
+	testDecodeParser(MIMEApplicationXML, `<Demo><name>john</name></Demo>`)
+	testDecodeParser(MIMEApplicationForm, "name=john")
+
+	// without override the Content-Type header is used
+	utils.AssertEqual(t, "bodyparser: cannot parse content-type: text/plain", c.BodyParser(new(Demo)).Error())
+	utils.AssertEqual(t, "bodyparser: cannot parse content-type: text/plain", c.BodyParser(new(Demo), "").Error())
+}
+
+// go test -run Test_Ctx_BodyParser_Gzip
+func Test_Ctx_BodyParser_Gzip(t *testing.T) {
+	t.Parallel()
+	app := New(Config{DecompressedBodyLimit: 1024})
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+
+	type Demo struct {
+		Name string `json:"name" form:"name"`
+	}
+
+	gzipBody := func(body []byte) []byte {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		_, err := w.Write(body)
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, nil, w.Close())
+		return buf.Bytes()
+	}
+
+	c.Request().Header.Set(HeaderContentEncoding, "gzip")
+	c.Request().Header.SetContentType(MIMEApplicationJSON)
+	c.Request().SetBody(gzipBody([]byte(`{"name":"john"}`)))
+	d := new(Demo)
+	utils.AssertEqual(t, nil, c.BodyParser(d))
+	utils.AssertEqual(t, "john", d.Name)
+
+	c.Request().Header.SetContentType(MIMEApplicationForm)
+	c.Request().SetBody(gzipBody([]byte("name=doe")))
+	d = new(Demo)
+	utils.AssertEqual(t, nil, c.BodyParser(d))
+	utils.AssertEqual(t, "doe", d.Name)
+
+	// a body exactly at the limit is accepted
+	c.Request().Header.SetContentType(MIMEApplicationJSON)
+	c.Request().SetBody(gzipBody([]byte(`{"name":"` + strings.Repeat("a", 1024-11) + `"}`)))
+	utils.AssertEqual(t, nil, c.BodyParser(new(Demo)))
+
+	// a gzip bomb of 10MB is rejected at the limit
+	bomb := gzipBody(make([]byte, 10*1024*1024))
+	utils.AssertEqual(t, true, len(bomb) < 64*1024)
+	c.Request().SetBody(bomb)
+	utils.AssertEqual(t, ErrRequestEntityTooLarge, c.BodyParser(new(Demo)))
+
+	// an invalid gzip body
+	c.Request().SetBody([]byte("not gzip"))
+	utils.AssertEqual(t, ErrBadRequest, c.BodyParser(new(Demo)))
+
+	// the status code of the error handler
+	app.Post("/", func(c *Ctx) error {
+		return c.BodyParser(new(Demo))
+	})
+	req := httptest.NewRequest(MethodPost, "/", bytes.NewReader(bomb))
+	req.Header.Set(HeaderContentType, MIMEApplicationJSON)
+	req.Header.Set(HeaderContentEncoding, "gzip")
+	resp, err := app.Test(req)
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusRequestEntityTooLarge, resp.StatusCode)
+}
+
+// go test -run Test_Ctx_BodyParser_Decompression
+func Test_Ctx_BodyParser_Decompression(t *testing.T) {
+	t.Parallel()
+	type Demo struct {
+		Name string `json:"name"`
+	}
+
+	encoders := map[string]func(w io.Writer) io.WriteCloser{
+		"deflate": func(w io.Writer) io.WriteCloser {
+			return zlib.NewWriter(w)
+		},
+		"br": func(w io.Writer) io.WriteCloser {
+			return brotli.NewWriter(w)
+		},
+		"zstd": func(w io.Writer) io.WriteCloser {
+			zw, err := zstd.NewWriter(w)
+			utils.AssertEqual(t, nil, err)
+			return zw
+		},
+	}
+	encode := func(encoding string, body []byte) []byte {
+		var buf bytes.Buffer
+		w := encoders[encoding](&buf)
+		_, err := w.Write(body)
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, nil, w.Close())
+		return buf.Bytes()
+	}
+
+	app := New(Config{EnableRequestDecompression: true, DecompressedBodyLimit: 1024})
+	app.Post("/", func(c *Ctx) error {
+		d := new(Demo)
+		if err := c.BodyParser(d); err != nil {
+			return err
+		}
+		return c.SendString(d.Name)
+	})
+	disabled := New()
+	disabled.Post("/", func(c *Ctx) error {
+		return c.BodyParser(new(Demo))
+	})
+
+	for encoding := range encoders {
+		post := func(app *App, body []byte) *http.Response {
+			req := httptest.NewRequest(MethodPost, "/", bytes.NewReader(body))
+			req.Header.Set(HeaderContentType, MIMEApplicationJSON)
+			req.Header.Set(HeaderContentEncoding, encoding)
+			resp, err := app.Test(req)
+			utils.AssertEqual(t, nil, err, "app.Test(req)")
+			return resp
+		}
+
+		resp := post(app, encode(encoding, []byte(`{"name":"john"}`)))
+		utils.AssertEqual(t, StatusOK, resp.StatusCode, encoding)
+		body, err := ioutil.ReadAll(resp.Body)
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, "john", string(body), encoding)
+
+		// a bomb is rejected at the limit
+		resp = post(app, encode(encoding, make([]byte, 1024*1024)))
+		utils.AssertEqual(t, StatusRequestEntityTooLarge, resp.StatusCode, encoding)
+
+		// an invalid body
+		resp = post(app, []byte("not encoded"))
+		utils.AssertEqual(t, StatusBadRequest, resp.StatusCode, encoding)
+
+		// the body is not decoded without EnableRequestDecompression
+		resp = post(disabled, encode(encoding, []byte(`{"name":"john"}`)))
+		utils.AssertEqual(t, StatusInternalServerError, resp.StatusCode, encoding)
+	}
+}
+
 // go test -v -run=^$ -bench=Benchmark_Ctx_BodyParser_JSON -benchmem -count=4
 func Benchmark_Ctx_BodyParser_JSON(b *testing.B) {
 	app := New()
@@ -468,6 +947,29 @@ func Test_Ctx_Cookie(t *testing.T) {
 	c.Cookie(&Cookie{SameSite: "none"})
 }
 
+// go test -run Test_Ctx_Cookie_Partitioned
+func Test_Ctx_Cookie_Partitioned(t *testing.T) {
+	t.Parallel()
+	app := New()
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+
+	c.Cookie(&Cookie{
+		Name:        "session",
+		Value:       "john",
+		SameSite:    "none",
+		Partitioned: true,
+	})
+	utils.AssertEqual(t, "session=john; path=/; secure; SameSite=None; Partitioned", string(c.Response().Header.Peek(HeaderSetCookie)))
+
+	// the partitioned cookie replaces the previous cookie of the same name
+	c.Cookie(&Cookie{Name: "session", Value: "doe", Partitioned: true})
+	utils.AssertEqual(t, "session=doe; path=/; secure; SameSite=Lax; Partitioned", string(c.Response().Header.Peek(HeaderSetCookie)))
+
+	c.Cookie(&Cookie{Name: "session", Value: "john"})
+	utils.AssertEqual(t, "session=john; path=/; SameSite=Lax", string(c.Response().Header.Peek(HeaderSetCookie)))
+}
+
 // go test -v -run=^$ -bench=Benchmark_Ctx_Cookie -benchmem -count=4
 func Benchmark_Ctx_Cookie(b *testing.B) {
 	app := New()
@@ -495,6 +997,98 @@ func Test_Ctx_Cookies(t *testing.T) {
 	utils.AssertEqual(t, "default", c.Cookies("unknown", "default"))
 }
 
+// go test -run Test_Ctx_CookiesTyped
+func Test_Ctx_CookiesTyped(t *testing.T) {
+	t.Parallel()
+	app := New()
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+	c.Request().Header.Set("Cookie", "session_count=42; remember=true; invalid=abc")
+	utils.AssertEqual(t, 42, c.CookiesInt("session_count"))
+	utils.AssertEqual(t, 0, c.CookiesInt("invalid"))
+	utils.AssertEqual(t, 7, c.CookiesInt("unknown", 7))
+	utils.AssertEqual(t, true, c.CookiesBool("remember"))
+	utils.AssertEqual(t, false, c.CookiesBool("invalid"))
+	utils.AssertEqual(t, true, c.CookiesBool("unknown", true))
+}
+
+// go test -run Test_Ctx_CookieParser
+func Test_Ctx_CookieParser(t *testing.T) {
+	t.Parallel()
+	app := New()
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+	type Session struct {
+		Count    int    `cookie:"session_count"`
+		Remember bool   `cookie:"remember"`
+		Name     string `cookie:"name"`
+	}
+	c.Request().Header.Set("Cookie", "session_count=42; remember=true; name=john")
+	s := new(Session)
+	utils.AssertEqual(t, nil, c.CookieParser(s))
+	utils.AssertEqual(t, 42, s.Count)
+	utils.AssertEqual(t, true, s.Remember)
+	utils.AssertEqual(t, "john", s.Name)
+
+	c.Request().Header.Set("Cookie", "session_count=abc")
+	utils.AssertEqual(t, "schema: error converting value for \"session_count\"", c.CookieParser(new(Session)).Error())
+}
+
+// go test -run Test_Ctx_AutoFormat
+func Test_Ctx_AutoFormat(t *testing.T) {
+	t.Parallel()
+	app := New()
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+
+	type User struct {
+		XMLName xml.Name `json:"-" xml:"user"`
+		Name    string   `json:"name" xml:"name"`
+	}
+	user := User{Name: "john"}
+
+	c.Request().Header.Set(HeaderAccept, "text/xml;q=0.5, application/json")
+	utils.AssertEqual(t, nil, c.AutoFormat(user))
+	utils.AssertEqual(t, `{"name":"john"}`, string(c.Response().Body()))
+	utils.AssertEqual(t, MIMEApplicationJSON, string(c.Response().Header.ContentType()))
+	utils.AssertEqual(t, HeaderAccept, string(c.Response().Header.Peek(HeaderVary)))
+
+	c.Request().Header.Set(HeaderAccept, "application/xml")
+	utils.AssertEqual(t, nil, c.AutoFormat(user))
+	utils.AssertEqual(t, xml.Header+`<user><name>john</name></user>`, string(c.Response().Body()))
+	utils.AssertEqual(t, MIMEApplicationXML, string(c.Response().Header.ContentType()))
+
+	c.Request().Header.Set(HeaderAccept, "text/*")
+	utils.AssertEqual(t, nil, c.AutoFormat("<b>john</b>"))
+	utils.AssertEqual(t, "<b>john</b>", string(c.Response().Body()))
+	utils.AssertEqual(t, MIMETextPlain, string(c.Response().Header.ContentType()))
+
+	// json is preferred without Accept header
+	c.Request().Header.Del(HeaderAccept)
+	utils.AssertEqual(t, nil, c.AutoFormat(user))
+	utils.AssertEqual(t, `{"name":"john"}`, string(c.Response().Body()))
+
+	c.Request().Header.Set(HeaderAccept, "image/png")
+	utils.AssertEqual(t, ErrNotAcceptable, c.AutoFormat(user))
+}
+
+// go test -run Test_Ctx_SendHTML
+func Test_Ctx_SendHTML(t *testing.T) {
+	t.Parallel()
+	app := New()
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+
+	utils.AssertEqual(t, nil, c.SendHTML("<p>"+c.EscapeHTML(`<script>alert("john")</script>`)+"</p>"))
+	utils.AssertEqual(t, "<p>&lt;script&gt;alert(&#34;john&#34;)&lt;/script&gt;</p>", string(c.Response().Body()))
+	utils.AssertEqual(t, MIMETextHTMLCharsetUTF8, string(c.Response().Header.ContentType()))
+
+	// Format escapes html as well
+	c.Request().Header.Set(HeaderAccept, MIMETextHTML)
+	utils.AssertEqual(t, nil, c.Format("<b>john</b>"))
+	utils.AssertEqual(t, "<p>&lt;b&gt;john&lt;/b&gt;</p>", string(c.Response().Body()))
+}
+
 // go test -run Test_Ctx_Format
 func Test_Ctx_Format(t *testing.T) {
 	t.Parallel()
@@ -534,6 +1128,62 @@ func Test_Ctx_Format(t *testing.T) {
 	utils.AssertEqual(t, `Hello, World!`, string(c.Response().Body()))
 }
 
+// go test -run Test_Ctx_Format_Handlers
+func Test_Ctx_Format_Handlers(t *testing.T) {
+	t.Parallel()
+	app := New()
+	app.Get("/", func(c *Ctx) error {
+		return c.Format(map[string]func() error{
+			"json": func() error {
+				return c.JSON(Map{"hello": "world"})
+			},
+			"html": func() error {
+				return c.SendString("<p>Hello, World!</p>")
+			},
+		})
+	})
+
+	testCases := []struct {
+		accept      string
+		status      int
+		contentType string
+		body        string
+	}{
+		{"", StatusOK, MIMETextHTML, "<p>Hello, World!</p>"},
+		{MIMEApplicationJSON, StatusOK, MIMEApplicationJSON, `{"hello":"world"}`},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", StatusOK, MIMETextHTML, "<p>Hello, World!</p>"},
+		{"*/*", StatusOK, MIMETextHTML, "<p>Hello, World!</p>"},
+		{MIMETextPlain, StatusNotAcceptable, MIMETextPlainCharsetUTF8, utils.StatusMessage(StatusNotAcceptable)},
+	}
+	for _, tc := range testCases {
+		req := httptest.NewRequest(MethodGet, "/", nil)
+		if tc.accept != "" {
+			req.Header.Set(HeaderAccept, tc.accept)
+		}
+		resp, err := app.Test(req)
+		utils.AssertEqual(t, nil, err, "app.Test(req)")
+		utils.AssertEqual(t, tc.status, resp.StatusCode, tc.accept)
+		utils.AssertEqual(t, tc.contentType, resp.Header.Get(HeaderContentType), tc.accept)
+		utils.AssertEqual(t, HeaderAccept, resp.Header.Get(HeaderVary), tc.accept)
+		body, err := ioutil.ReadAll(resp.Body)
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, tc.body, string(body), tc.accept)
+	}
+
+	// mime types as keys
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+	c.Request().Header.Set(HeaderAccept, "application/vnd.api+json")
+	err := c.Format(map[string]func() error{
+		"application/vnd.api+json": func() error {
+			return c.SendString("vnd")
+		},
+	})
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "application/vnd.api+json", string(c.Response().Header.ContentType()))
+	utils.AssertEqual(t, "vnd", string(c.Response().Body()))
+}
+
 // go test -v -run=^$ -bench=Benchmark_Ctx_Format -benchmem -count=4
 func Benchmark_Ctx_Format(b *testing.B) {
 	app := New()
@@ -747,6 +1397,55 @@ func Test_Ctx_Get(t *testing.T) {
 	utils.AssertEqual(t, "default", c.Get("unknown", "default"))
 }
 
+// go test -run Test_Ctx_GetRespHeader
+func Test_Ctx_GetRespHeader(t *testing.T) {
+	t.Parallel()
+	app := New()
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+	c.Set("test", "Hello, World 👋!")
+	c.Set(HeaderContentType, MIMEApplicationJSON)
+	utils.AssertEqual(t, "Hello, World 👋!", c.GetRespHeader("test"))
+	utils.AssertEqual(t, "Hello, World 👋!", c.GetRespHeader("Test"))
+	utils.AssertEqual(t, MIMEApplicationJSON, c.GetRespHeader(HeaderContentType))
+	utils.AssertEqual(t, "default", c.GetRespHeader("unknown", "default"))
+	utils.AssertEqual(t, "", c.GetRespHeader("unknown"))
+}
+
+// go test -run Test_Ctx_GetRespHeaders
+func Test_Ctx_GetRespHeaders(t *testing.T) {
+	t.Parallel()
+	app := New()
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+	c.Set("test", "Hello, World 👋!")
+	c.Set(HeaderContentType, MIMEApplicationJSON)
+	c.Response().Header.Add("X-Multi", "a")
+	c.Response().Header.Add("X-Multi", "b")
+	utils.AssertEqual(t, map[string][]string{
+		"Content-Type": {MIMEApplicationJSON},
+		"Test":         {"Hello, World 👋!"},
+		"X-Multi":      {"a", "b"},
+	}, c.GetRespHeaders())
+}
+
+// go test -run Test_Ctx_GetReqHeaders
+func Test_Ctx_GetReqHeaders(t *testing.T) {
+	t.Parallel()
+	app := New()
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+	c.Request().Header.Set("test", "Hello, World 👋!")
+	c.Request().Header.SetContentType(MIMEApplicationJSON)
+	c.Request().Header.Add("X-Multi", "a")
+	c.Request().Header.Add("X-Multi", "b")
+	utils.AssertEqual(t, map[string][]string{
+		"Content-Type": {MIMEApplicationJSON},
+		"Test":         {"Hello, World 👋!"},
+		"X-Multi":      {"a", "b"},
+	}, c.GetReqHeaders())
+}
+
 // go test -run Test_Ctx_Hostname
 func Test_Ctx_Hostname(t *testing.T) {
 	t.Parallel()
@@ -755,6 +1454,26 @@ func Test_Ctx_Hostname(t *testing.T) {
 	defer app.ReleaseCtx(c)
 	c.Request().SetRequestURI("http://google.com/test")
 	utils.AssertEqual(t, "google.com", c.Hostname())
+
+	// X-Forwarded-Host is ignored without EnableForwardedHost
+	c.Request().Header.Set(HeaderXForwardedHost, "example.com")
+	utils.AssertEqual(t, "google.com", c.Hostname())
+}
+
+// go test -run Test_Ctx_Hostname_ForwardedHost
+func Test_Ctx_Hostname_ForwardedHost(t *testing.T) {
+	t.Parallel()
+	app := New(Config{EnableForwardedHost: true})
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+	c.Request().SetRequestURI("http://google.com/test")
+	utils.AssertEqual(t, "google.com", c.Hostname())
+
+	c.Request().Header.Set(HeaderXForwardedHost, " example.com ")
+	utils.AssertEqual(t, "example.com", c.Hostname())
+
+	c.Request().Header.Set(HeaderXForwardedHost, "example.com , proxy.internal")
+	utils.AssertEqual(t, "example.com", c.Hostname())
 }
 
 // go test -run Test_Ctx_IP
@@ -775,6 +1494,30 @@ func Test_Ctx_IP_ProxyHeader(t *testing.T) {
 	utils.AssertEqual(t, "", c.IP())
 }
 
+// go test -run Test_Ctx_IP_Normalize
+func Test_Ctx_IP_Normalize(t *testing.T) {
+	t.Parallel()
+	app := New(Config{ProxyHeader: "Real-Ip"})
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+
+	for raw, ip := range map[string]string{
+		"[::1]:8080":       "::1",
+		"[::1]":            "::1",
+		"::1":              "::1",
+		"::ffff:127.0.0.1": "127.0.0.1",
+		"[fe80::1%eth0]:0": "fe80::1",
+		"fe80::1%eth0":     "fe80::1",
+		"2001:DB8::0:1":    "2001:db8::1",
+		"1.2.3.4:80":       "1.2.3.4",
+		" 1.2.3.4 ":        "1.2.3.4",
+		"unknown":          "unknown",
+	} {
+		c.Request().Header.Set("Real-Ip", raw)
+		utils.AssertEqual(t, ip, c.IP(), raw)
+	}
+}
+
 // go test -run Test_Ctx_IPs  -parallel
 func Test_Ctx_IPs(t *testing.T) {
 	t.Parallel()
@@ -873,6 +1616,170 @@ func Test_Ctx_Locals(t *testing.T) {
 	utils.AssertEqual(t, StatusOK, resp.StatusCode, "Status code")
 }
 
+// go test -run Test_Ctx_Locals_Typed
+func Test_Ctx_Locals_Typed(t *testing.T) {
+	t.Parallel()
+	app := New()
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+	c.Locals("int", 42)
+	c.Locals("string", "john")
+	utils.AssertEqual(t, 42, c.LocalsInt("int"))
+	utils.AssertEqual(t, 0, c.LocalsInt("string"))
+	utils.AssertEqual(t, 7, c.LocalsInt("unknown", 7))
+	utils.AssertEqual(t, "john", c.LocalsString("string"))
+	utils.AssertEqual(t, "", c.LocalsString("int"))
+	utils.AssertEqual(t, "doe", c.LocalsString("unknown", "doe"))
+	// stored zero values are returned instead of the default
+	c.Locals("zero", 0)
+	c.Locals("empty", "")
+	utils.AssertEqual(t, 0, c.LocalsInt("zero", 7))
+	utils.AssertEqual(t, "", c.LocalsString("empty", "doe"))
+}
+
+// go test -run Test_Ctx_UserContext
+func Test_Ctx_UserContext(t *testing.T) {
+	t.Parallel()
+	app := New()
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	utils.AssertEqual(t, context.Background(), c.UserContext())
+
+	type ctxKey struct{}
+	c.SetUserContext(context.WithValue(context.Background(), ctxKey{}, "span"))
+	utils.AssertEqual(t, "span", c.UserContext().Value(ctxKey{}))
+
+	app.ReleaseCtx(c)
+	utils.AssertEqual(t, nil, c.userContext)
+}
+
+// go test -run Test_Ctx_UserContext_Pooled
+func Test_Ctx_UserContext_Pooled(t *testing.T) {
+	type ctxKey struct{}
+	app := New()
+	app.Get("/set", func(c *Ctx) error {
+		c.Locals("john", "doe")
+		c.SetUserContext(context.WithValue(c.UserContext(), ctxKey{}, "span"))
+		return nil
+	})
+	app.Get("/get", func(c *Ctx) error {
+		utils.AssertEqual(t, nil, c.Locals("john"))
+		utils.AssertEqual(t, nil, c.UserContext().Value(ctxKey{}))
+		return nil
+	})
+	for i := 0; i < 5; i++ {
+		resp, err := app.Test(httptest.NewRequest(MethodGet, "/set", nil))
+		utils.AssertEqual(t, nil, err, "app.Test(req)")
+		utils.AssertEqual(t, StatusOK, resp.StatusCode, "Status code")
+		resp, err = app.Test(httptest.NewRequest(MethodGet, "/get", nil))
+		utils.AssertEqual(t, nil, err, "app.Test(req)")
+		utils.AssertEqual(t, StatusOK, resp.StatusCode, "Status code")
+	}
+}
+
+// go test -run Test_Ctx_SetDeadline
+func Test_Ctx_SetDeadline(t *testing.T) {
+	t.Parallel()
+	app := New()
+	app.Get("/", func(c *Ctx) error {
+		_, ok := c.Deadline()
+		utils.AssertEqual(t, false, ok)
+
+		deadline := time.Now().Add(20 * time.Millisecond)
+		c.SetDeadline(deadline)
+		// a later deadline does not extend the earlier one
+		c.SetDeadline(deadline.Add(time.Hour))
+		got, ok := c.Deadline()
+		utils.AssertEqual(t, true, ok)
+		utils.AssertEqual(t, deadline, got)
+
+		select {
+		case <-c.UserContext().Done():
+		case <-time.After(time.Second):
+			t.Fatal("the user context is not canceled after the deadline")
+		}
+		utils.AssertEqual(t, context.DeadlineExceeded, c.UserContext().Err())
+		return c.SendStatus(StatusGatewayTimeout)
+	})
+	app.Get("/pooled", func(c *Ctx) error {
+		_, ok := c.Deadline()
+		utils.AssertEqual(t, false, ok)
+		utils.AssertEqual(t, nil, c.UserContext().Err())
+		return nil
+	})
+
+	resp, err := app.Test(httptest.NewRequest(MethodGet, "/", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusGatewayTimeout, resp.StatusCode, "Status code")
+
+	// the deadline is cleared when the Ctx is reused
+	for i := 0; i < 5; i++ {
+		resp, err = app.Test(httptest.NewRequest(MethodGet, "/pooled", nil))
+		utils.AssertEqual(t, nil, err, "app.Test(req)")
+		utils.AssertEqual(t, StatusOK, resp.StatusCode, "Status code")
+	}
+
+	// releasing the Ctx cancels the user context
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	c.SetDeadline(time.Now().Add(time.Hour))
+	ctx := c.UserContext()
+	app.ReleaseCtx(c)
+	utils.AssertEqual(t, context.Canceled, ctx.Err())
+	utils.AssertEqual(t, true, c.cancel == nil)
+}
+
+// go test -run Test_Ctx_UserContext_ClientDisconnect
+func Test_Ctx_UserContext_ClientDisconnect(t *testing.T) {
+	if !canPeekConn {
+		t.Skip("client disconnects are not detected on " + runtime.GOOS)
+	}
+	t.Parallel()
+
+	type traceKey struct{}
+	disconnected := func(config Config, setUserContext bool) bool {
+		config.DisableStartupMessage = true
+		app := New(config)
+		started, canceled := make(chan struct{}), make(chan bool, 1)
+		app.Get("/", func(c *Ctx) error {
+			// e.g. a context of a tracing middleware
+			if setUserContext {
+				c.SetUserContext(context.WithValue(context.Background(), traceKey{}, "trace"))
+				utils.AssertEqual(t, "trace", c.UserContext().Value(traceKey{}))
+			}
+			ctx := c.UserContext()
+			close(started)
+			select {
+			case <-ctx.Done():
+				canceled <- true
+			case <-time.After(500 * time.Millisecond):
+				canceled <- false
+			}
+			return nil
+		})
+
+		ln, err := net.Listen("tcp4", "127.0.0.1:0")
+		utils.AssertEqual(t, nil, err)
+		go func() {
+			_ = app.Listener(ln)
+		}()
+		defer func() {
+			_ = app.Shutdown()
+		}()
+
+		conn, err := net.Dial("tcp4", ln.Addr().String())
+		utils.AssertEqual(t, nil, err)
+		_, err = conn.Write([]byte("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n"))
+		utils.AssertEqual(t, nil, err)
+		<-started
+		utils.AssertEqual(t, nil, conn.Close())
+		return <-canceled
+	}
+
+	utils.AssertEqual(t, true, disconnected(Config{}, false))
+	utils.AssertEqual(t, true, disconnected(Config{}, true))
+	utils.AssertEqual(t, false, disconnected(Config{DisableClientDisconnectCancel: true}, false))
+	utils.AssertEqual(t, false, disconnected(Config{DisableClientDisconnectCancel: true}, true))
+}
+
 // go test -run Test_Ctx_Method
 func Test_Ctx_Method(t *testing.T) {
 	t.Parallel()
@@ -934,6 +1841,80 @@ func Test_Ctx_MultipartForm(t *testing.T) {
 	utils.AssertEqual(t, StatusOK, resp.StatusCode, "Status code")
 }
 
+// go test -run Test_Ctx_MultipartForm_Limits
+func Test_Ctx_MultipartForm_Limits(t *testing.T) {
+	t.Parallel()
+	app := New(Config{
+		MultipartFilesLimit: 2,
+		MultipartSizeLimit:  10,
+	})
+
+	app.Post("/form", func(c *Ctx) error {
+		form, err := c.MultipartForm()
+		if err != nil {
+			return err
+		}
+		return c.SendString(strconv.Itoa(len(form.File["file"])))
+	})
+	app.Post("/file", func(c *Ctx) error {
+		fh, err := c.FormFile("file")
+		if err != nil {
+			return err
+		}
+		return c.SendString(fh.Filename)
+	})
+	app.Post("/body", func(c *Ctx) error {
+		var data struct {
+			Name string `form:"name"`
+		}
+		if err := c.BodyParser(&data); err != nil {
+			return err
+		}
+		return c.SendString(data.Name)
+	})
+	app.Post("/value", func(c *Ctx) error {
+		return c.SendString(c.FormValue("name", "none"))
+	})
+
+	upload := func(path string, files ...string) *http.Response {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		utils.AssertEqual(t, nil, writer.WriteField("name", "john"))
+		for i, content := range files {
+			ioWriter, err := writer.CreateFormFile("file", "file"+strconv.Itoa(i))
+			utils.AssertEqual(t, nil, err)
+			_, err = ioWriter.Write([]byte(content))
+			utils.AssertEqual(t, nil, err)
+		}
+		utils.AssertEqual(t, nil, writer.Close())
+
+		req := httptest.NewRequest(MethodPost, path, body)
+		req.Header.Set(HeaderContentType, writer.FormDataContentType())
+		resp, err := app.Test(req)
+		utils.AssertEqual(t, nil, err, "app.Test(req)")
+		return resp
+	}
+
+	utils.AssertEqual(t, StatusOK, upload("/form", "a", "b").StatusCode)
+	// too many files
+	utils.AssertEqual(t, StatusRequestEntityTooLarge, upload("/form", "a", "b", "c").StatusCode)
+	utils.AssertEqual(t, StatusRequestEntityTooLarge, upload("/file", "a", "b", "c").StatusCode)
+	// too large files
+	utils.AssertEqual(t, StatusRequestEntityTooLarge, upload("/form", "hello", "world!").StatusCode)
+	utils.AssertEqual(t, StatusOK, upload("/file", "hello").StatusCode)
+	// every entry point of the form is limited
+	utils.AssertEqual(t, StatusOK, upload("/body", "a").StatusCode)
+	utils.AssertEqual(t, StatusRequestEntityTooLarge, upload("/body", "a", "b", "c").StatusCode)
+	resp := upload("/value", "hello", "world!")
+	text, err := ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "none", string(text))
+	resp = upload("/value", "a")
+	text, err = ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "john", string(text))
+}
+
 // go test -run Test_Ctx_OriginalURL
 func Test_Ctx_OriginalURL(t *testing.T) {
 	t.Parallel()
@@ -983,45 +1964,272 @@ func Test_Ctx_Params(t *testing.T) {
 	utils.AssertEqual(t, StatusOK, resp.StatusCode, "Status code")
 }
 
-// go test -v -run=^$ -bench=Benchmark_Ctx_Params -benchmem -count=4
-func Benchmark_Ctx_Params(b *testing.B) {
+// go test -race -run Test_Ctx_Immutable_Reuse
+func Test_Ctx_Immutable_Reuse(t *testing.T) {
+	t.Parallel()
+	app := New(Config{Immutable: true})
+
+	release := make(chan struct{})
+	captured := make(chan []string, 2)
+	app.Post("/user/:name", func(c *Ctx) error {
+		body := c.Body()
+		values := []string{
+			c.Params("name"),
+			c.ParamsRaw("name"),
+			c.Query("q"),
+			c.Get("X-Custom"),
+			c.GetReqHeaders()["X-Custom"][0],
+			c.Cookies("session"),
+			c.Path(),
+		}
+		// The values are read after the RequestCtx is reused for the next request
+		go func() {
+			<-release
+			captured <- append(values, string(body))
+		}()
+		return nil
+	})
+
+	handler := app.Handler()
+	fctx := &fasthttp.RequestCtx{}
+	// The requests have the same length so the buffers are overwritten in place
+	for _, name := range []string{"first", "other"} {
+		fctx.Request.Reset()
+		fctx.Request.Header.SetMethod(MethodPost)
+		fctx.Request.SetRequestURI("/user/" + name + "?q=" + name)
+		fctx.Request.Header.Set("X-Custom", name)
+		fctx.Request.Header.SetCookie("session", name)
+		fctx.Request.SetBodyString(name)
+		handler(fctx)
+		utils.AssertEqual(t, StatusOK, fctx.Response.StatusCode())
+	}
+	close(release)
+
+	results := map[string][]string{}
+	for i := 0; i < 2; i++ {
+		values := <-captured
+		results[values[0]] = values
+	}
+	for _, name := range []string{"first", "other"} {
+		utils.AssertEqual(t, []string{name, name, name, name, name, name, "/user/" + name, name}, results[name])
+	}
+}
+
+// go test -run Test_Ctx_ParamsRaw
+func Test_Ctx_ParamsRaw(t *testing.T) {
+	t.Parallel()
+	app := New()
+	app.Get("/files/:name/info", func(c *Ctx) error {
+		return c.SendString(c.Params("name") + "|" + c.ParamsRaw("name"))
+	})
+	app.Get("/wild/*", func(c *Ctx) error {
+		return c.SendString(c.Params("*") + "|" + c.ParamsRaw("*"))
+	})
+	app.Get("/user/:id", func(c *Ctx) error {
+		return c.SendString(c.Params("id") + "|" + c.ParamsRaw("id") + "|" + c.ParamsRaw("missing", "default"))
+	})
+
+	testCases := []struct {
+		path string
+		body string
+	}{
+		{"/files/a%2Fb/info", "a/b|a%2Fb"},
+		{"/files/a%20b%3F/info", "a b?|a%20b%3F"},
+		{"/wild/a/b%2Fc", "a/b/c|a/b%2Fc"},
+		{"/user/john", "john|john|default"},
+	}
+	for _, tc := range testCases {
+		resp, err := app.Test(httptest.NewRequest(MethodGet, tc.path, nil))
+		utils.AssertEqual(t, nil, err, "app.Test(req)")
+		utils.AssertEqual(t, StatusOK, resp.StatusCode, tc.path)
+		body, err := ioutil.ReadAll(resp.Body)
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, tc.body, string(body), tc.path)
+	}
+
+	// an invalid encoding is returned unchanged
+	utils.AssertEqual(t, "100%", unescapeParam("100%"))
+}
+
+// go test -v -run=^$ -bench=Benchmark_Ctx_Params -benchmem -count=4
+func Benchmark_Ctx_Params(b *testing.B) {
+	app := New()
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+	c.route = &Route{
+		Params: []string{
+			"param1", "param2", "param3", "param4",
+		},
+	}
+	c.values = [maxParams]string{
+		"john", "doe", "is", "awesome",
+	}
+	var res string
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		_ = c.Params("param1")
+		_ = c.Params("param2")
+		_ = c.Params("param3")
+		res = c.Params("param4")
+	}
+	utils.AssertEqual(b, "awesome", res)
+}
+
+// go test -run Test_Ctx_ParamsParser
+func Test_Ctx_ParamsParser(t *testing.T) {
+	t.Parallel()
+	app := New()
+
+	type User struct {
+		ID   int    `params:"id,required"`
+		Page int    `params:"page" default:"1"`
+		Sort string `params:"sort" default:"name"`
+		Path string `params:"*1"`
+	}
+
+	app.Get("/users/:id?/:page?/*", func(c *Ctx) error {
+		user := new(User)
+		if err := c.ParamsParser(user); err != nil {
+			return err
+		}
+		return c.JSON(user)
+	})
+
+	testCases := []struct {
+		url    string
+		status int
+		body   string
+	}{
+		{"/users/42/3/a/b", StatusOK, `{"ID":42,"Page":3,"Sort":"name","Path":"a/b"}`},
+		// defaults are applied to empty parameters
+		{"/users/42", StatusOK, `{"ID":42,"Page":1,"Sort":"name","Path":""}`},
+		// required parameters must not be empty
+		{"/users", StatusBadRequest, "id is empty"},
+		{"/users/john", StatusBadRequest, "schema: error converting value for \"id\""},
+	}
+	for _, tc := range testCases {
+		resp, err := app.Test(httptest.NewRequest(MethodGet, tc.url, nil))
+		utils.AssertEqual(t, nil, err, "app.Test(req)")
+		utils.AssertEqual(t, tc.status, resp.StatusCode, tc.url)
+		body, err := ioutil.ReadAll(resp.Body)
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, tc.body, string(body), tc.url)
+	}
+}
+
+// go test -run Test_Ctx_Path
+func Test_Ctx_Path(t *testing.T) {
+	t.Parallel()
+	app := New()
+	app.Get("/test/:user", func(c *Ctx) error {
+		utils.AssertEqual(t, "/test/john", c.Path())
+		// not strict && case insensitive
+		utils.AssertEqual(t, "/ABC/", c.Path("/ABC/"))
+		utils.AssertEqual(t, "/test/john/", c.Path("/test/john/"))
+		return nil
+	})
+	resp, err := app.Test(httptest.NewRequest(MethodGet, "/test/john", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusOK, resp.StatusCode, "Status code")
+}
+
+// go test -run Test_Ctx_Path_Override_Routing
+func Test_Ctx_Path_Override_Routing(t *testing.T) {
+	t.Parallel()
+	app := New()
+	app.Get("/new/other", func(c *Ctx) error {
+		return c.SendString("other")
+	})
+	calls := 0
+	app.Use(func(c *Ctx) error {
+		calls++
+		return c.Next()
+	})
+	app.Use(func(c *Ctx) error {
+		calls++
+		if c.Path() == "/old" {
+			c.Path("/new/path")
+		}
+		return c.Next()
+	})
+	app.Get("/old", func(c *Ctx) error {
+		return c.SendString("old")
+	})
+	app.Get("/new/path", func(c *Ctx) error {
+		return c.SendString("new")
+	})
+
+	// routing continues with the new path after the current route
+	resp, err := app.Test(httptest.NewRequest(MethodGet, "/old", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusOK, resp.StatusCode, "Status code")
+	body, err := ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "new", string(body))
+	utils.AssertEqual(t, 2, calls)
+
+	// routes of the new path registered before the current route are not matched again
+	app = New(Config{DisableHeadAutoRegister: true})
+	app.Get("/before", func(c *Ctx) error {
+		return c.SendString("before")
+	})
+	app.Use(func(c *Ctx) error {
+		if c.Path() == "/override" {
+			c.Path("/before")
+		}
+		return c.Next()
+	})
+	app.Get("/override", func(c *Ctx) error {
+		return c.SendString("override")
+	})
+	resp, err = app.Test(httptest.NewRequest(MethodGet, "/override", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusNotFound, resp.StatusCode, "Status code")
+
+	// overrides within the routes of the same path prefix continue like before
+	app = New()
+	app.Use(func(c *Ctx) error {
+		c.Path("/final")
+		return c.Next()
+	})
+	app.Get("/first", func(c *Ctx) error {
+		return c.SendString("first")
+	})
+	app.Get("/final", func(c *Ctx) error {
+		return c.SendString("final")
+	})
+	resp, err = app.Test(httptest.NewRequest(MethodGet, "/first", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusOK, resp.StatusCode, "Status code")
+	body, err = ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "final", string(body))
+}
+
+// go test -run Test_Ctx_Problem
+func Test_Ctx_Problem(t *testing.T) {
+	t.Parallel()
 	app := New()
 	c := app.AcquireCtx(&fasthttp.RequestCtx{})
 	defer app.ReleaseCtx(c)
-	c.route = &Route{
-		Params: []string{
-			"param1", "param2", "param3", "param4",
-		},
-	}
-	c.values = [maxParams]string{
-		"john", "doe", "is", "awesome",
-	}
-	var res string
-	b.ReportAllocs()
-	b.ResetTimer()
-	for n := 0; n < b.N; n++ {
-		_ = c.Params("param1")
-		_ = c.Params("param2")
-		_ = c.Params("param3")
-		res = c.Params("param4")
-	}
-	utils.AssertEqual(b, "awesome", res)
-}
 
-// go test -run Test_Ctx_Path
-func Test_Ctx_Path(t *testing.T) {
-	t.Parallel()
-	app := New()
-	app.Get("/test/:user", func(c *Ctx) error {
-		utils.AssertEqual(t, "/test/john", c.Path())
-		// not strict && case insensitive
-		utils.AssertEqual(t, "/ABC/", c.Path("/ABC/"))
-		utils.AssertEqual(t, "/test/john/", c.Path("/test/john/"))
-		return nil
+	err := c.Problem(StatusForbidden, ProblemDetails{
+		Type:   "https://example.com/probs/out-of-credit",
+		Title:  "You do not have enough credit.",
+		Status: StatusOK,
+		Detail: "Your current balance is 30, but that costs 50.",
 	})
-	resp, err := app.Test(httptest.NewRequest(MethodGet, "/test/john", nil))
-	utils.AssertEqual(t, nil, err, "app.Test(req)")
-	utils.AssertEqual(t, StatusOK, resp.StatusCode, "Status code")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, StatusForbidden, c.Response().StatusCode())
+	utils.AssertEqual(t, MIMEApplicationProblemJSON, string(c.Response().Header.ContentType()))
+	utils.AssertEqual(t, `{"type":"https://example.com/probs/out-of-credit","title":"You do not have enough credit.","status":403,"detail":"Your current balance is 30, but that costs 50."}`, string(c.Response().Body()))
+
+	// the title defaults to the status message
+	err = c.Problem(StatusBadRequest, ProblemDetails{})
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, StatusBadRequest, c.Response().StatusCode())
+	utils.AssertEqual(t, `{"title":"Bad Request","status":400}`, string(c.Response().Body()))
 }
 
 // go test -run Test_Ctx_Protocol
@@ -1078,6 +2286,68 @@ func Test_Ctx_Query(t *testing.T) {
 	utils.AssertEqual(t, "default", c.Query("unknown", "default"))
 }
 
+// go test -run Test_Ctx_Queries
+func Test_Ctx_Queries(t *testing.T) {
+	t.Parallel()
+	app := New()
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+	c.Request().URI().SetQueryString("a=1&a=2&b=3&name=john%20doe")
+	utils.AssertEqual(t, map[string]string{"a": "2", "b": "3", "name": "john doe"}, c.Queries())
+
+	c.Request().URI().SetQueryString("")
+	utils.AssertEqual(t, map[string]string{}, c.Queries())
+}
+
+// go test -run Test_Ctx_QueryArray
+func Test_Ctx_QueryArray(t *testing.T) {
+	t.Parallel()
+	app := New()
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+	c.Request().URI().SetQueryString("a=1&a=2&b=3&c=x%2Cy")
+	utils.AssertEqual(t, []string{"1", "2"}, c.QueryArray("a"))
+	utils.AssertEqual(t, []string{"3"}, c.QueryArray("b"))
+	utils.AssertEqual(t, []string{"x,y"}, c.QueryArray("c"))
+	utils.AssertEqual(t, true, c.QueryArray("unknown") == nil)
+}
+
+// go test -run Test_Ctx_QueryTyped
+func Test_Ctx_QueryTyped(t *testing.T) {
+	t.Parallel()
+	app := New()
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+	c.Request().URI().SetQueryString("id=42&ok=true&price=9.5&bad=abc")
+
+	id, err := c.QueryIntErr("id")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 42, id)
+	_, err = c.QueryIntErr("bad")
+	utils.AssertEqual(t, true, err != nil)
+	_, err = c.QueryIntErr("unknown")
+	utils.AssertEqual(t, true, err != nil)
+	utils.AssertEqual(t, 42, c.QueryInt("id", 1))
+	utils.AssertEqual(t, 1, c.QueryInt("bad", 1))
+	utils.AssertEqual(t, 0, c.QueryInt("unknown"))
+
+	ok, err := c.QueryBoolErr("ok")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, ok)
+	_, err = c.QueryBoolErr("bad")
+	utils.AssertEqual(t, true, err != nil)
+	utils.AssertEqual(t, true, c.QueryBool("bad", true))
+	utils.AssertEqual(t, false, c.QueryBool("unknown"))
+
+	price, err := c.QueryFloatErr("price")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 9.5, price)
+	_, err = c.QueryFloatErr("bad")
+	utils.AssertEqual(t, true, err != nil)
+	utils.AssertEqual(t, 1.5, c.QueryFloat("bad", 1.5))
+	utils.AssertEqual(t, 0.0, c.QueryFloat("unknown"))
+}
+
 // go test -run Test_Ctx_Range
 func Test_Ctx_Range(t *testing.T) {
 	t.Parallel()
@@ -1153,6 +2423,52 @@ func Test_Ctx_RouteNormalized(t *testing.T) {
 	utils.AssertEqual(t, StatusNotFound, resp.StatusCode, "Status code")
 }
 
+// go test -run Test_Ctx_RoutePattern
+func Test_Ctx_RoutePattern(t *testing.T) {
+	t.Parallel()
+	app := New()
+
+	// metrics middleware reading the pattern after the handler
+	app.Use(func(c *Ctx) error {
+		err := c.Next()
+		c.Set("X-Route", c.RoutePattern())
+		return err
+	})
+	app.Get("/users/:id", func(c *Ctx) error {
+		utils.AssertEqual(t, "/users/:id", c.Route().Path)
+		utils.AssertEqual(t, "/users/:id", c.RoutePattern())
+		return nil
+	})
+	app.Group("/api").Get("/posts/:id?", func(c *Ctx) error {
+		return c.SendString(c.RoutePattern())
+	})
+	sub := New()
+	sub.Get("/orders/:id", func(c *Ctx) error {
+		return c.SendString(c.RoutePattern())
+	})
+	app.Mount("/shop", sub)
+
+	testCases := []struct {
+		url  string
+		body string
+		// the mount handler is the matched route of the parent app
+		route string
+	}{
+		{"/users/42", "", "/users/:id"},
+		{"/api/posts/42", "/api/posts/:id?", "/api/posts/:id?"},
+		{"/shop/orders/42", "/shop/orders/:id", "/shop"},
+	}
+	for _, tc := range testCases {
+		resp, err := app.Test(httptest.NewRequest(MethodGet, tc.url, nil))
+		utils.AssertEqual(t, nil, err, "app.Test(req)")
+		utils.AssertEqual(t, StatusOK, resp.StatusCode, tc.url)
+		utils.AssertEqual(t, tc.route, resp.Header.Get("X-Route"), tc.url)
+		body, err := ioutil.ReadAll(resp.Body)
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, tc.body, string(body), tc.url)
+	}
+}
+
 // go test -run Test_Ctx_SaveFile
 func Test_Ctx_SaveFile(t *testing.T) {
 	// TODO We should clean this up
@@ -1325,6 +2641,168 @@ func Test_Ctx_SendFile(t *testing.T) {
 	app.ReleaseCtx(c)
 }
 
+// go test -run Test_Ctx_SendFile_ETag
+func Test_Ctx_SendFile_ETag(t *testing.T) {
+	t.Parallel()
+	app := New()
+
+	dir, err := ioutil.TempDir("", "fiber-sendfile")
+	utils.AssertEqual(t, nil, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "file.txt")
+	utils.AssertEqual(t, nil, ioutil.WriteFile(file, []byte("Hello, World!"), 0644))
+
+	app.Get("/", func(c *Ctx) error {
+		return c.SendFile(file)
+	})
+	get := func(etag string) *http.Response {
+		req := httptest.NewRequest(MethodGet, "/", nil)
+		if etag != "" {
+			req.Header.Set(HeaderIfNoneMatch, etag)
+		}
+		resp, err := app.Test(req)
+		utils.AssertEqual(t, nil, err, "app.Test(req)")
+		return resp
+	}
+
+	resp := get("")
+	utils.AssertEqual(t, StatusOK, resp.StatusCode)
+	etag := resp.Header.Get(HeaderETag)
+	utils.AssertEqual(t, true, strings.HasPrefix(etag, `W/"d-`), etag)
+	utils.AssertEqual(t, true, resp.Header.Get(HeaderLastModified) != "")
+
+	// a conditional request of the unchanged file
+	for _, header := range []string{etag, `"other", ` + etag, strings.TrimPrefix(etag, "W/"), "*"} {
+		resp = get(header)
+		utils.AssertEqual(t, StatusNotModified, resp.StatusCode, header)
+		utils.AssertEqual(t, etag, resp.Header.Get(HeaderETag))
+		body, err := ioutil.ReadAll(resp.Body)
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, "", string(body))
+	}
+
+	// the changed file is sent again
+	modTime := time.Now().Add(time.Hour)
+	utils.AssertEqual(t, nil, os.Chtimes(file, modTime, modTime))
+	resp = get(etag)
+	utils.AssertEqual(t, StatusOK, resp.StatusCode)
+	utils.AssertEqual(t, false, etag == resp.Header.Get(HeaderETag))
+	body, err := ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "Hello, World!", string(body))
+}
+
+// go test -race -run Test_Ctx_SendFileWithOptions
+func Test_Ctx_SendFileWithOptions(t *testing.T) {
+	t.Parallel()
+	app := New()
+
+	// use a temporary copy, compressed files are cached next to the file
+	content, err := ioutil.ReadFile("./ctx.go")
+	utils.AssertEqual(t, nil, err)
+	dir, err := ioutil.TempDir("", "fiber-sendfile")
+	utils.AssertEqual(t, nil, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "ctx.go")
+	utils.AssertEqual(t, nil, ioutil.WriteFile(file, content, 0600))
+
+	testCases := []struct {
+		config      SendFileConfig
+		status      int
+		encoding    string
+		disposition string
+		header      string
+	}{
+		{SendFileConfig{}, StatusOK, "", "", ""},
+		{SendFileConfig{Compress: true}, StatusOK, "gzip", "", ""},
+		{SendFileConfig{ByteRange: true}, StatusPartialContent, "", "", ""},
+		{SendFileConfig{Download: true}, StatusOK, "", `attachment; filename="ctx.go"`, ""},
+		{SendFileConfig{CacheDuration: time.Minute, Compress: true, Download: true}, StatusOK, "gzip", `attachment; filename="ctx.go"`, ""},
+		{SendFileConfig{ByteRange: true, Download: true, ModifyResponse: func(c *Ctx) error {
+			c.Set(HeaderCacheControl, "no-cache")
+			return nil
+		}}, StatusPartialContent, "", `attachment; filename="ctx.go"`, "no-cache"},
+	}
+	for i, tc := range testCases {
+		c := app.AcquireCtx(&fasthttp.RequestCtx{})
+		c.Request().Header.Set(HeaderAcceptEncoding, "gzip")
+		if tc.config.ByteRange {
+			c.Request().Header.Set(HeaderRange, "bytes=0-9")
+		}
+		err = c.SendFileWithOptions(file, tc.config)
+		utils.AssertEqual(t, nil, err, fmt.Sprintf("case %d", i))
+		utils.AssertEqual(t, tc.status, c.Response().StatusCode(), fmt.Sprintf("case %d", i))
+		utils.AssertEqual(t, tc.encoding, string(c.Response().Header.Peek(HeaderContentEncoding)), fmt.Sprintf("case %d", i))
+		utils.AssertEqual(t, tc.disposition, string(c.Response().Header.Peek(HeaderContentDisposition)), fmt.Sprintf("case %d", i))
+		utils.AssertEqual(t, tc.header, string(c.Response().Header.Peek(HeaderCacheControl)), fmt.Sprintf("case %d", i))
+		if tc.status == StatusPartialContent {
+			utils.AssertEqual(t, content[:10], c.Response().Body(), fmt.Sprintf("case %d", i))
+		} else if tc.encoding == "" {
+			utils.AssertEqual(t, content, c.Response().Body(), fmt.Sprintf("case %d", i))
+		}
+		app.ReleaseCtx(c)
+	}
+
+	// byte ranges are ignored if disabled
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	c.Request().Header.Set(HeaderRange, "bytes=0-9")
+	utils.AssertEqual(t, nil, c.SendFileWithOptions(file, SendFileConfig{}))
+	utils.AssertEqual(t, StatusOK, c.Response().StatusCode())
+	utils.AssertEqual(t, content, c.Response().Body())
+	app.ReleaseCtx(c)
+
+	// errors of ModifyResponse are returned
+	c = app.AcquireCtx(&fasthttp.RequestCtx{})
+	err = c.SendFileWithOptions(file, SendFileConfig{ModifyResponse: func(c *Ctx) error {
+		return ErrForbidden
+	}})
+	utils.AssertEqual(t, ErrForbidden, err)
+	app.ReleaseCtx(c)
+
+	// not found files skip the options
+	app.Get("/", func(c *Ctx) error {
+		return c.SendFileWithOptions(filepath.Join(dir, "missing.go"), SendFileConfig{Download: true})
+	})
+	resp, err := app.Test(httptest.NewRequest(MethodGet, "/", nil))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, StatusNotFound, resp.StatusCode)
+	utils.AssertEqual(t, "", resp.Header.Get(HeaderContentDisposition))
+}
+
+// go test -run Test_Ctx_SendFile_CacheDuration
+func Test_Ctx_SendFile_CacheDuration(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		duration   time.Duration
+		normalized time.Duration
+	}{
+		{time.Millisecond, time.Second},
+		{time.Second, time.Second},
+		{1500 * time.Millisecond, 2 * time.Second},
+		{10 * time.Second, 16 * time.Second},
+		{time.Minute, 64 * time.Second},
+		{365 * 24 * time.Hour, maxSendFileCacheDuration},
+	}
+	for _, tc := range testCases {
+		utils.AssertEqual(t, tc.normalized, sendFileCacheDuration(tc.duration), tc.duration.String())
+	}
+
+	// per request durations share the file handlers
+	app := New()
+	for i := 1; i <= 100; i++ {
+		c := app.AcquireCtx(&fasthttp.RequestCtx{})
+		utils.AssertEqual(t, nil, c.SendFileWithOptions("./ctx.go", SendFileConfig{CacheDuration: time.Duration(i) * time.Millisecond}))
+		app.ReleaseCtx(c)
+	}
+	sendFileMutex.Lock()
+	suffix := app.config.CompressedFileSuffix
+	_, ok := sendFileHandlers[sendFileKey{cacheDuration: time.Second, compressedFileSuffix: suffix}]
+	_, notOk := sendFileHandlers[sendFileKey{cacheDuration: 42 * time.Millisecond, compressedFileSuffix: suffix}]
+	sendFileMutex.Unlock()
+	utils.AssertEqual(t, true, ok)
+	utils.AssertEqual(t, false, notOk)
+}
+
 // go test -race -run Test_Ctx_SendFile_404
 func Test_Ctx_SendFile_404(t *testing.T) {
 	t.Parallel()
@@ -1388,6 +2866,45 @@ func Test_Ctx_JSON(t *testing.T) {
 	testEmpty("", `""`)
 	testEmpty(0, "0")
 	testEmpty([]int{}, "[]")
+
+	// inline status code
+	utils.AssertEqual(t, nil, c.JSON(Map{"created": true}, StatusCreated))
+	utils.AssertEqual(t, `{"created":true}`, string(c.Response().Body()))
+	utils.AssertEqual(t, StatusCreated, c.Response().StatusCode())
+}
+
+// go test -run Test_Ctx_JSONStream
+func Test_Ctx_JSONStream(t *testing.T) {
+	t.Parallel()
+	app := New()
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+
+	data := Map{
+		"Name":  "Grame",
+		"Age":   20,
+		"Items": []string{"<a>", "b", "c"},
+	}
+	utils.AssertEqual(t, nil, c.JSON(data))
+	buffered := string(c.Response().Body())
+
+	c.Response().SetBodyString("previous body")
+	utils.AssertEqual(t, nil, c.JSONStream(data, StatusAccepted))
+	utils.AssertEqual(t, buffered+"\n", string(c.Response().Body()))
+	utils.AssertEqual(t, "application/json", string(c.Response().Header.Peek("content-type")))
+	utils.AssertEqual(t, StatusAccepted, c.Response().StatusCode())
+
+	// the body is streamed to the client
+	app.Get("/", func(c *Ctx) error {
+		return c.JSONStream(data)
+	})
+	resp, err := app.Test(httptest.NewRequest(MethodGet, "/", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusOK, resp.StatusCode)
+	utils.AssertEqual(t, []string{"chunked"}, resp.TransferEncoding)
+	body, err := ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, buffered+"\n", string(body))
 }
 
 // go test -run=^$ -bench=Benchmark_Ctx_JSON -benchmem -count=4
@@ -1476,6 +2993,32 @@ func Test_Ctx_Links(t *testing.T) {
 		"http://api.example.com/users?page=5", "last",
 	)
 	utils.AssertEqual(t, `<http://api.example.com/users?page=2>; rel="next",<http://api.example.com/users?page=5>; rel="last"`, string(c.Response().Header.Peek(HeaderLink)))
+
+	c.Links(
+		"http://api.example.com/users?page=1", "first",
+		"http://api.example.com/users?page=2", "prev",
+		"http://api.example.com/users?page=4", "next",
+		"http://api.example.com/users?page=5", "last",
+	)
+	utils.AssertEqual(t, `<http://api.example.com/users?page=1>; rel="first",<http://api.example.com/users?page=2>; rel="prev",<http://api.example.com/users?page=4>; rel="next",<http://api.example.com/users?page=5>; rel="last"`, string(c.Response().Header.Peek(HeaderLink)))
+
+	// link targets are escaped
+	c.Links("http://api.example.com/users?q=john doe&sort=<name>", "next")
+	utils.AssertEqual(t, `<http://api.example.com/users?q=john%20doe&sort=%3Cname%3E>; rel="next"`, string(c.Response().Header.Peek(HeaderLink)))
+
+	// multiple and extension relation types
+	c.Links("http://api.example.com/users?page=1", "first prev", "http://api.example.com/docs", "http://example.com/rel/docs")
+	utils.AssertEqual(t, `<http://api.example.com/users?page=1>; rel="first prev",<http://api.example.com/docs>; rel="http://example.com/rel/docs"`, string(c.Response().Header.Peek(HeaderLink)))
+
+	// invalid relation types and links without relation type are skipped
+	c.Links(
+		"http://api.example.com/users?page=2", `next"; title="injected`,
+		"http://api.example.com/users?page=3", "next,prev",
+		"http://api.example.com/users?page=4", "",
+		"http://api.example.com/users?page=5", "last",
+		"http://api.example.com/users?page=6",
+	)
+	utils.AssertEqual(t, `<http://api.example.com/users?page=5>; rel="last"`, string(c.Response().Header.Peek(HeaderLink)))
 }
 
 // go test -v  -run=^$ -bench=Benchmark_Ctx_Links -benchmem -count=4
@@ -1549,6 +3092,46 @@ func Test_Ctx_Redirect(t *testing.T) {
 	utils.AssertEqual(t, "http://example.com", string(c.Response().Header.Peek(HeaderLocation)))
 }
 
+// go test -run Test_Ctx_RedirectToRoute
+func Test_Ctx_RedirectToRoute(t *testing.T) {
+	t.Parallel()
+	app := New()
+	app.Get("/users/:id/posts/:post?", testEmptyHandler).Name("posts")
+	app.Get("/files/*", testEmptyHandler).Name("files")
+	api := New()
+	api.Get("/items/:name", testEmptyHandler).Name("item")
+	app.Mount("/api", api)
+
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+
+	tests := []struct {
+		name     string
+		params   Map
+		location string
+	}{
+		{"posts", Map{"id": 42, "post": "first"}, "/users/42/posts/first"},
+		{"posts", Map{"id": 42}, "/users/42/posts"},
+		{"posts", Map{"id": "a b"}, "/users/a%20b/posts"},
+		{"files", Map{"*": "docs/a b.txt"}, "/files/docs/a%20b.txt"},
+		{"files", Map{"*1": "index.html"}, "/files/index.html"},
+		{"item", Map{"name": "box"}, "/api/items/box"},
+	}
+	for _, tt := range tests {
+		utils.AssertEqual(t, nil, c.RedirectToRoute(tt.name, tt.params))
+		utils.AssertEqual(t, StatusFound, c.Response().StatusCode())
+		utils.AssertEqual(t, tt.location, string(c.Response().Header.Peek(HeaderLocation)))
+	}
+
+	utils.AssertEqual(t, nil, c.RedirectToRoute("posts", Map{"id": 1}, StatusSeeOther))
+	utils.AssertEqual(t, StatusSeeOther, c.Response().StatusCode())
+	utils.AssertEqual(t, "/users/1/posts", string(c.Response().Header.Peek(HeaderLocation)))
+
+	// unknown routes and missing parameters
+	utils.AssertEqual(t, "redirect: route unknown not found", c.RedirectToRoute("unknown", nil).Error())
+	utils.AssertEqual(t, "redirect: parameter id of route posts is missing", c.RedirectToRoute("posts", nil).Error())
+}
+
 // go test -run Test_Ctx_Render
 func Test_Ctx_Render(t *testing.T) {
 	t.Parallel()
@@ -1573,6 +3156,44 @@ func Test_Ctx_Render(t *testing.T) {
 	utils.AssertEqual(t, false, err == nil)
 }
 
+// go test -run Test_Ctx_BindVars
+func Test_Ctx_BindVars(t *testing.T) {
+	t.Parallel()
+	app := New()
+
+	app.Use(func(c *Ctx) error {
+		utils.AssertEqual(t, true, c.GetBound() == nil)
+		c.BindVars(Map{"Title": "from middleware", "User": "john"}).BindVars(Map{"Role": "admin"})
+		return c.Next()
+	})
+	app.Get("/", func(c *Ctx) error {
+		c.BindVars(Map{"Title": "from handler"})
+		utils.AssertEqual(t, Map{"Title": "from handler", "User": "john", "Role": "admin"}, c.GetBound())
+		// The bound variables are distinct from the locals
+		utils.AssertEqual(t, nil, c.Locals("User"))
+		return c.SendString(c.GetBound()["Title"].(string))
+	})
+	app.Get("/render", func(c *Ctx) error {
+		c.BindVars(Map{"Title": "bound"})
+		return c.Render("./.github/testdata/template.html", nil)
+	})
+	app.Get("/render/map", func(c *Ctx) error {
+		return c.Render("./.github/testdata/template.html", Map{"Title": "bind"})
+	})
+
+	for path, body := range map[string]string{
+		"/":           "from handler",
+		"/render":     "<h1>bound</h1>",
+		"/render/map": "<h1>bind</h1>",
+	} {
+		resp, err := app.Test(httptest.NewRequest(MethodGet, path, nil))
+		utils.AssertEqual(t, nil, err, "app.Test(req)")
+		text, err := ioutil.ReadAll(resp.Body)
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, body, string(text), path)
+	}
+}
+
 type testTemplateEngine struct {
 	mu        sync.Mutex
 	templates *template.Template
@@ -1701,6 +3322,38 @@ func Test_Ctx_SendStatus(t *testing.T) {
 	utils.AssertEqual(t, "Unsupported Media Type", string(c.Response().Body()))
 }
 
+// go test -run Test_Ctx_SendStatusJSON
+func Test_Ctx_SendStatusJSON(t *testing.T) {
+	t.Parallel()
+	app := New()
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+
+	testCases := []struct {
+		status int
+		body   string
+	}{
+		{StatusBadRequest, `{"error":"Bad Request","code":400}`},
+		{StatusForbidden, `{"error":"Forbidden","code":403}`},
+		{StatusNotFound, `{"error":"Not Found","code":404}`},
+		{StatusTeapot, `{"error":"I'm a teapot","code":418}`},
+		{StatusInternalServerError, `{"error":"Internal Server Error","code":500}`},
+	}
+	for _, tc := range testCases {
+		c.Response().ResetBody()
+		utils.AssertEqual(t, nil, c.SendStatusJSON(tc.status))
+		utils.AssertEqual(t, tc.status, c.Response().StatusCode())
+		utils.AssertEqual(t, tc.body, string(c.Response().Body()))
+		utils.AssertEqual(t, MIMEApplicationJSON, string(c.Response().Header.ContentType()))
+	}
+
+	// an existing body is kept
+	c.Response().SetBodyString("custom")
+	utils.AssertEqual(t, nil, c.SendStatusJSON(StatusForbidden))
+	utils.AssertEqual(t, StatusForbidden, c.Response().StatusCode())
+	utils.AssertEqual(t, "custom", string(c.Response().Body()))
+}
+
 // go test -run Test_Ctx_SendString
 func Test_Ctx_SendString(t *testing.T) {
 	t.Parallel()
@@ -1733,6 +3386,55 @@ func Test_Ctx_SendStream(t *testing.T) {
 	utils.AssertEqual(t, true, (c.Response().Header.ContentLength() > 200))
 }
 
+// go test -run Test_Ctx_SendEventStream
+func Test_Ctx_SendEventStream(t *testing.T) {
+	t.Parallel()
+	app := New()
+
+	app.Get("/events", func(c *Ctx) error {
+		return c.SendEventStream(func(w *bufio.Writer) {
+			events := []Event{
+				{ID: "1", Event: "greeting", Data: "hello"},
+				{ID: "2", Data: "multi\nline", Retry: 3 * time.Second},
+			}
+			for _, event := range events {
+				if err := event.Write(w); err != nil {
+					return
+				}
+			}
+		})
+	})
+
+	resp, err := app.Test(httptest.NewRequest(MethodGet, "/events", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusOK, resp.StatusCode)
+	utils.AssertEqual(t, MIMETextEventStream, resp.Header.Get(HeaderContentType))
+	utils.AssertEqual(t, "no-cache", resp.Header.Get(HeaderCacheControl))
+	utils.AssertEqual(t, "no", resp.Header.Get(HeaderXAccelBuffering))
+	utils.AssertEqual(t, []string{"chunked"}, resp.TransferEncoding)
+
+	// Read the events separated by empty lines
+	reader := bufio.NewReader(resp.Body)
+	readEvent := func() string {
+		var event string
+		for {
+			line, err := reader.ReadString('\n')
+			utils.AssertEqual(t, nil, err)
+			if line == "\n" {
+				return event
+			}
+			event += line
+		}
+	}
+	utils.AssertEqual(t, "id: 1\nevent: greeting\ndata: hello\n", readEvent())
+	utils.AssertEqual(t, "id: 2\nretry: 3000\ndata: multi\ndata: line\n", readEvent())
+	_, err = reader.ReadByte()
+	utils.AssertEqual(t, io.EOF, err)
+
+	// Fields can't be broken by new lines
+	utils.AssertEqual(t, "event: a b\ndata: \n\n", Event{Event: "a\nb"}.String())
+}
+
 // go test -run Test_Ctx_Set
 func Test_Ctx_Set(t *testing.T) {
 	t.Parallel()
@@ -1871,6 +3573,86 @@ func Test_Ctx_Write(t *testing.T) {
 	utils.AssertEqual(t, "Hello, World!", string(c.Response().Body()))
 }
 
+// go test -run Test_Ctx_WriteEarlyHints
+func Test_Ctx_WriteEarlyHints(t *testing.T) {
+	t.Parallel()
+	app := New(Config{DisableStartupMessage: true})
+	app.Get("/", func(c *Ctx) error {
+		if err := c.WriteEarlyHints([]string{
+			"</style.css>; rel=preload; as=style",
+			"</script.js>; rel=preload; as=script",
+		}); err != nil {
+			return err
+		}
+		return c.SendString("Hello, World!")
+	})
+
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	utils.AssertEqual(t, nil, err)
+	go func() {
+		_ = app.Listener(ln)
+	}()
+	defer func() {
+		_ = app.Shutdown()
+	}()
+
+	request := func(proto string) *bufio.Reader {
+		conn, err := net.Dial("tcp4", ln.Addr().String())
+		utils.AssertEqual(t, nil, err)
+		_, err = conn.Write([]byte("GET / " + proto + "\r\nHost: example.com\r\nConnection: close\r\n\r\n"))
+		utils.AssertEqual(t, nil, err)
+		return bufio.NewReader(conn)
+	}
+
+	// the 103 is sent before the final response
+	br := request("HTTP/1.1")
+	resp, err := http.ReadResponse(br, nil)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, StatusEarlyHints, resp.StatusCode)
+	utils.AssertEqual(t, []string{
+		"</style.css>; rel=preload; as=style",
+		"</script.js>; rel=preload; as=script",
+	}, resp.Header.Values(HeaderLink))
+
+	resp, err = http.ReadResponse(br, nil)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, StatusOK, resp.StatusCode)
+	body, err := ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "Hello, World!", string(body))
+
+	// HTTP/1.0 clients only get the final response
+	resp, err = http.ReadResponse(request("HTTP/1.0"), nil)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, StatusOK, resp.StatusCode)
+	body, err = ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "Hello, World!", string(body))
+}
+
+// go test -run Test_Ctx_Write_Writer
+func Test_Ctx_Write_Writer(t *testing.T) {
+	t.Parallel()
+	app := New()
+	app.Get("/", func(c *Ctx) error {
+		c.Status(StatusCreated).Type("txt")
+		c.Set("X-Custom", "kept")
+		var w io.Writer = c
+		fmt.Fprintf(w, "Hello, %s!", "World")
+		_, err := io.WriteString(w, " Bye.")
+		return err
+	})
+
+	resp, err := app.Test(httptest.NewRequest(MethodGet, "/", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusCreated, resp.StatusCode)
+	utils.AssertEqual(t, MIMETextPlain, resp.Header.Get(HeaderContentType))
+	utils.AssertEqual(t, "kept", resp.Header.Get("X-Custom"))
+	body, err := ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "Hello, World! Bye.", string(body))
+}
+
 // go test -v -run=^$ -bench=Benchmark_Ctx_Write -benchmem -count=4
 func Benchmark_Ctx_Write(b *testing.B) {
 	app := New()
@@ -1920,6 +3702,39 @@ func Benchmark_Ctx_XHR(b *testing.B) {
 	utils.AssertEqual(b, true, equal)
 }
 
+// go test -run Test_Ctx_XML
+func Test_Ctx_XML(t *testing.T) {
+	t.Parallel()
+	app := New()
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+
+	type User struct {
+		XMLName xml.Name `xml:"user"`
+		ID      int      `xml:"id,attr"`
+		Name    string   `xml:"name"`
+	}
+
+	utils.AssertEqual(t, nil, c.XML(User{ID: 1, Name: "john"}, StatusCreated))
+	utils.AssertEqual(t, xml.Header+`<user id="1"><name>john</name></user>`, string(c.Response().Body()))
+	utils.AssertEqual(t, MIMEApplicationXML, string(c.Response().Header.ContentType()))
+	utils.AssertEqual(t, StatusCreated, c.Response().StatusCode())
+
+	utils.AssertEqual(t, true, c.XML(complex(1, 1)) != nil)
+
+	// custom encoder with its own XML header
+	app = New(Config{
+		XMLEncoder: func(v interface{}) ([]byte, error) {
+			raw, err := xml.MarshalIndent(v, "", "  ")
+			return append([]byte(`<?xml version="1.0"?>`+"\n"), raw...), err
+		},
+	})
+	c2 := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c2)
+	utils.AssertEqual(t, nil, c2.XML(User{ID: 1, Name: "john"}))
+	utils.AssertEqual(t, "<?xml version=\"1.0\"?>\n<user id=\"1\">\n  <name>john</name>\n</user>", string(c2.Response().Body()))
+}
+
 // go test -v  -run=^$ -bench=Benchmark_Ctx_SendString_B -benchmem -count=4
 func Benchmark_Ctx_SendString_B(b *testing.B) {
 	app := New()
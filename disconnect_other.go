@@ -0,0 +1,17 @@
+// ⚡️ Fiber is an Express inspired web framework written in Go with ☕️
+// 🤖 Github Repository: https://github.com/gofiber/fiber
+// 📌 API Documentation: https://docs.gofiber.io
+
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd,!dragonfly
+
+package fiber
+
+import "syscall"
+
+// canPeekConn reports whether connClosed detects closed connections
+const canPeekConn = false
+
+// connClosed is not supported on this platform
+func connClosed(raw syscall.RawConn) bool {
+	return false
+}
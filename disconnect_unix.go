@@ -0,0 +1,33 @@
+// ⚡️ Fiber is an Express inspired web framework written in Go with ☕️
+// 🤖 Github Repository: https://github.com/gofiber/fiber
+// 📌 API Documentation: https://docs.gofiber.io
+
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+package fiber
+
+import "syscall"
+
+// canPeekConn reports whether connClosed detects closed connections
+const canPeekConn = true
+
+// connClosed peeks at the connection without consuming data
+// and reports whether the client closed it
+func connClosed(raw syscall.RawConn) (closed bool) {
+	var buf [1]byte
+	err := raw.Read(func(fd uintptr) bool {
+		n, _, err := syscall.Recvfrom(int(fd), buf[:], syscall.MSG_PEEK|syscall.MSG_DONTWAIT)
+		switch err {
+		case nil:
+			// EOF
+			closed = n == 0
+		case syscall.EAGAIN, syscall.EINTR:
+			// No data available, the connection is still open
+		default:
+			closed = true
+		}
+		// Do not wait for the connection to become readable
+		return true
+	})
+	return closed || err != nil
+}
@@ -3,7 +3,10 @@ module github.com/gofiber/fiber/v2
 go 1.14
 
 require (
+	github.com/andybalholm/brotli v1.0.0
+	github.com/klauspost/compress v1.10.7
 	github.com/philhofer/fwd v1.1.0
 	github.com/valyala/fasthttp v1.16.0
+	golang.org/x/crypto v0.0.0-20190308221718-c2843e01d9a2
 	golang.org/x/sys v0.0.0-20201020230747-6e5568b54d1a
 )
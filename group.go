@@ -7,25 +7,24 @@ package fiber
 import (
 	"fmt"
 	"reflect"
+	"time"
 )
 
 // Group struct
 type Group struct {
-	app    *App
-	prefix string
+	app          *App
+	parent       *Group
+	prefix       string
+	errorHandler ErrorHandler
 }
 
-// Mount attaches another app instance as a subrouter along a routing path.
+// Mount attaches another app instance as a sub-app along a routing path.
 // It's very useful to split up a large API as many independent routers and
 // compose them as a single service using Mount.
 func (grp *Group) Mount(prefix string, fiber *App) Router {
-	stack := fiber.Stack()
-	for m := range stack {
-		for r := range stack[m] {
-			route := grp.app.copyRoute(stack[m][r])
-			grp.app.addRoute(route.Method, grp.app.addPrefixToRoute(getGroupPath(grp.prefix, prefix), route))
-		}
-	}
+	prefix = getGroupPath(grp.prefix, prefix)
+	grp.app.register(methodUse, prefix, grp.app.mount(prefix, fiber))
+	grp.setGroup()
 	return grp
 }
 
@@ -41,30 +40,40 @@ func (grp *Group) Mount(prefix string, fiber *App) Router {
 //  app.Use("/api", handler, func(c *fiber.Ctx) error {
 //       return c.Next()
 //  })
+//  app.Use([]string{"/api", "/admin"}, func(c *fiber.Ctx) error {
+//       return c.Next()
+//  })
 //
 // This method will match all HTTP verbs: GET, POST, PUT, HEAD etc...
 func (grp *Group) Use(args ...interface{}) Router {
-	var prefix = ""
+	var prefixes = []string{""}
 	var handlers []Handler
 	for i := 0; i < len(args); i++ {
 		switch arg := args[i].(type) {
 		case string:
-			prefix = arg
+			prefixes = []string{arg}
+		case []string:
+			prefixes = arg
 		case Handler:
 			handlers = append(handlers, arg)
 		default:
 			panic(fmt.Sprintf("use: invalid handler %v\n", reflect.TypeOf(arg)))
 		}
 	}
-	grp.app.register(methodUse, getGroupPath(grp.prefix, prefix), handlers...)
+	var routes []*Route
+	for _, prefix := range prefixes {
+		grp.app.register(methodUse, getGroupPath(grp.prefix, prefix), handlers...)
+		grp.setGroup()
+		routes = append(routes, grp.app.latestRoutes...)
+	}
+	grp.app.latestRoutes = routes
 	return grp
 }
 
 // Get registers a route for GET methods that requests a representation
 // of the specified resource. Requests using GET should only retrieve data.
 func (grp *Group) Get(path string, handlers ...Handler) Router {
-	path = getGroupPath(grp.prefix, path)
-	return grp.app.Add(MethodHead, path, handlers...).Add(MethodGet, path, handlers...)
+	return grp.Add(MethodGet, path, handlers...)
 }
 
 // Head registers a route for HEAD methods that asks for a response identical
@@ -116,19 +125,85 @@ func (grp *Group) Patch(path string, handlers ...Handler) Router {
 
 // Add allows you to specify a HTTP method to register a route
 func (grp *Group) Add(method, path string, handlers ...Handler) Router {
-	return grp.app.register(method, getGroupPath(grp.prefix, path), handlers...)
+	router := grp.app.register(method, getGroupPath(grp.prefix, path), handlers...)
+	grp.setGroup()
+	return router
 }
 
 // Static will create a file server serving static files
 func (grp *Group) Static(prefix, root string, config ...Static) Router {
-	return grp.app.registerStatic(getGroupPath(grp.prefix, prefix), root, config...)
+	router := grp.app.registerStatic(getGroupPath(grp.prefix, prefix), root, config...)
+	grp.setGroup()
+	return router
 }
 
 // All will register the handler on all HTTP methods
 func (grp *Group) All(path string, handlers ...Handler) Router {
+	var routes []*Route
 	for _, method := range intMethod {
 		_ = grp.Add(method, path, handlers...)
+		routes = append(routes, grp.app.latestRoutes...)
+	}
+	grp.app.latestRoutes = routes
+	return grp
+}
+
+// ErrorHandler sets the error handler for the routes of the group and its nested groups,
+// e.g. to render errors of different API versions differently.
+// The handler is picked from the matched route, errors of other routes
+// are handled by the ErrorHandler of the app.
+//  v1 := app.Group("/v1")
+//  v1.ErrorHandler(func(c *fiber.Ctx, err error) error {
+//       return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+//  })
+func (grp *Group) ErrorHandler(handler ErrorHandler) Router {
+	grp.errorHandler = handler
+	return grp
+}
+
+// setGroup assigns the routes of the latest registration to the group
+func (grp *Group) setGroup() {
+	for _, route := range grp.app.latestRoutes {
+		// Routes merged into a route of an earlier registration keep their group
+		if route.group == nil {
+			route.group = grp
+		}
 	}
+}
+
+// Set stores metadata on the routes of the latest registration,
+// the metadata is available in handlers with c.Route().Meta.
+//  api.Get("/admin", handler).Set("scope", "admin")
+func (grp *Group) Set(key string, value interface{}) Router {
+	_ = grp.app.Set(key, value)
+	return grp
+}
+
+// Name sets the name of the routes of the latest registration.
+//  api.Get("/users/:id", handler).Name("user")
+func (grp *Group) Name(name string) Router {
+	_ = grp.app.Name(name)
+	return grp
+}
+
+// Timeout limits the routes of the latest registration to the timeout.
+//  api.Get("/report", handler).Timeout(2 * time.Second)
+func (grp *Group) Timeout(timeout time.Duration) Router {
+	_ = grp.app.Timeout(timeout)
+	return grp
+}
+
+// Limit restricts the routes of the latest registration to max requests per duration of every client IP.
+//  api.Post("/login", handler).Limit(10, time.Minute)
+func (grp *Group) Limit(max int, duration time.Duration) Router {
+	_ = grp.app.Limit(max, duration)
+	return grp
+}
+
+// Describe sets the OpenAPI description of the routes of the latest registration.
+//  api.Get("/users/:id", handler).Describe(fiber.OpenAPISpec{Summary: "Get a user"})
+func (grp *Group) Describe(spec OpenAPISpec) Router {
+	_ = grp.app.Describe(spec)
 	return grp
 }
 
@@ -137,8 +212,10 @@ func (grp *Group) All(path string, handlers ...Handler) Router {
 //  api.Get("/users", handler)
 func (grp *Group) Group(prefix string, handlers ...Handler) Router {
 	prefix = getGroupPath(grp.prefix, prefix)
+	sub := &Group{prefix: prefix, app: grp.app, parent: grp}
 	if len(handlers) > 0 {
 		_ = grp.app.register(methodUse, prefix, handlers...)
+		sub.setGroup()
 	}
-	return grp.app.Group(prefix)
+	return sub
 }
@@ -11,9 +11,11 @@ import (
 	"hash/crc32"
 	"io"
 	"net"
+	"net/url"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
 	"unsafe"
@@ -146,6 +148,10 @@ func methodExist(ctx *Ctx) (exist bool) {
 			match := route.match(ctx.path, ctx.pathOriginal, &ctx.values)
 			// No match, next route
 			if match {
+				// The route of the first allowed method handles ErrMethodNotAllowed
+				if !exist {
+					ctx.route = route
+				}
 				// We matched
 				exist = true
 				// Add method to Allow header
@@ -182,6 +188,19 @@ func defaultString(value string, defaultValue []string) string {
 	return value
 }
 
+// unescapeParam decodes the URL encoded characters of a route parameter,
+// an invalid encoding returns the value unchanged
+func unescapeParam(value string) string {
+	if strings.IndexByte(value, '%') == -1 {
+		return value
+	}
+	unescaped, err := url.PathUnescape(value)
+	if err != nil {
+		return value
+	}
+	return unescaped
+}
+
 const normalizedHeaderETag = "Etag"
 
 // Generate and set ETag header to response
@@ -230,6 +249,24 @@ func setETag(c *Ctx, weak bool) {
 	c.setCanonical(normalizedHeaderETag, etag)
 }
 
+// fileETag returns a weak ETag of the size and modification time of a file
+func fileETag(info os.FileInfo) string {
+	return "W/\"" + strconv.FormatInt(info.Size(), 16) + "-" + strconv.FormatInt(info.ModTime().UnixNano(), 16) + "\""
+}
+
+// matchETag reports whether an If-None-Match header matches the etag,
+// the tags are compared weakly, so W/"1" matches "1"
+func matchETag(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(header, ",") {
+		tag = utils.Trim(tag, ' ')
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
 func getGroupPath(prefix, path string) string {
 	if path == "/" {
 		return prefix
@@ -237,6 +274,22 @@ func getGroupPath(prefix, path string) string {
 	return utils.TrimRight(prefix, '/') + path
 }
 
+// trimPathSegments removes the given amount of leading segments from the path
+//  trimPathSegments("/admin/users", 1) == "/users"
+func trimPathSegments(path string, segments int) string {
+	if segments == 0 {
+		return path
+	}
+	for i := 1; i < len(path); i++ {
+		if path[i] == '/' {
+			if segments--; segments == 0 {
+				return path[i:]
+			}
+		}
+	}
+	return "/"
+}
+
 // return valid offer for header negotiation
 func getOffer(header string, offers ...string) string {
 	if len(offers) == 0 {
@@ -273,6 +326,195 @@ func getOffer(header string, offers ...string) string {
 	return ""
 }
 
+// return the acceptable offer with the highest quality for Accept,
+// offers are MIME types or extensions. On equal quality the most specific
+// media range wins, then the order of the header and then the order of the offers.
+func getMediaOffer(header string, offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	} else if header == "" {
+		return offers[0]
+	}
+
+	best, bestQuality, bestSpecificity, bestPos := "", 0.0, 0, 0
+	for _, offer := range offers {
+		if len(offer) == 0 {
+			continue
+		}
+		mimetype := offer
+		if strings.IndexByte(offer, '/') == -1 {
+			mimetype = utils.GetMIME(offer) // extension
+		}
+		quality, specificity, pos := getMediaQuality(header, mimetype)
+		if quality > bestQuality || (quality > 0 && quality == bestQuality &&
+			(specificity > bestSpecificity || (specificity == bestSpecificity && pos < bestPos))) {
+			best, bestQuality, bestSpecificity, bestPos = offer, quality, specificity, pos
+		}
+	}
+
+	return best
+}
+
+// return the quality, specificity and position of the most specific media range
+// in the Accept header that matches the MIME type, a quality of 0 if none matches
+func getMediaQuality(header, mimetype string) (quality float64, specificity, pos int) {
+	if paramSign := strings.IndexByte(mimetype, ';'); paramSign != -1 {
+		mimetype = mimetype[:paramSign]
+	}
+	slash := strings.IndexByte(mimetype, '/')
+	if slash == -1 {
+		return 0, 0, 0
+	}
+	typ, subtype := utils.Trim(mimetype[:slash], ' '), utils.Trim(mimetype[slash+1:], ' ')
+
+	specificity = -1
+	for i := 0; len(header) > 0; i++ {
+		spec := header
+		if commaPos := strings.IndexByte(header, ','); commaPos != -1 {
+			spec, header = header[:commaPos], header[commaPos+1:]
+		} else {
+			header = ""
+		}
+		specQuality := 1.0
+		if factorSign := strings.IndexByte(spec, ';'); factorSign != -1 {
+			specQuality = getQuality(spec[factorSign+1:])
+			spec = spec[:factorSign]
+		}
+		spec = utils.Trim(spec, ' ')
+		if spec == "*" {
+			spec = "*/*"
+		}
+		specSlash := strings.IndexByte(spec, '/')
+		if specSlash == -1 {
+			continue
+		}
+		specType, specSubtype := spec[:specSlash], spec[specSlash+1:]
+
+		// Accept: <MIME_type>/<MIME_subtype>, <MIME_type>/* or */*
+		s := 0
+		if strings.EqualFold(specType, typ) {
+			s += 2
+		} else if specType != "*" && typ != "*" {
+			continue
+		}
+		if strings.EqualFold(specSubtype, subtype) {
+			s++
+		} else if specSubtype != "*" && subtype != "*" {
+			continue
+		}
+
+		if s > specificity || (s == specificity && specQuality > quality) {
+			quality, specificity, pos = specQuality, s, i
+		}
+	}
+
+	if specificity == -1 {
+		return 0, 0, 0
+	}
+	return quality, specificity, pos
+}
+
+// return the acceptable offer with the highest quality for Accept-Encoding,
+// identity is acceptable unless it is rejected explicitly or by "*;q=0"
+func getEncodingOffer(header string, offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	} else if header == "" {
+		return offers[0]
+	}
+
+	best, bestQuality, bestPos := "", 0.0, 0
+	for _, offer := range offers {
+		quality, pos := getEncodingQuality(header, offer)
+		// On equal quality the order of the header decides
+		if quality > bestQuality || (quality > 0 && quality == bestQuality && pos < bestPos) {
+			best, bestQuality, bestPos = offer, quality, pos
+		}
+	}
+
+	return best
+}
+
+// return the quality and position of the encoding in the Accept-Encoding header
+func getEncodingQuality(header, encoding string) (quality float64, pos int) {
+	starQuality, starPos, minQuality := -1.0, 0, 1.0
+	for pos = 0; len(header) > 0; pos++ {
+		spec := header
+		if commaPos := strings.IndexByte(header, ','); commaPos != -1 {
+			spec, header = header[:commaPos], header[commaPos+1:]
+		} else {
+			header = ""
+		}
+		specQuality := 1.0
+		if factorSign := strings.IndexByte(spec, ';'); factorSign != -1 {
+			specQuality = getQuality(spec[factorSign+1:])
+			spec = spec[:factorSign]
+		}
+		spec = utils.Trim(spec, ' ')
+
+		if strings.EqualFold(spec, encoding) {
+			return specQuality, pos
+		} else if spec == "*" {
+			starQuality, starPos = specQuality, pos
+		}
+		if specQuality > 0 && specQuality < minQuality {
+			minQuality = specQuality
+		}
+	}
+
+	if starQuality >= 0 {
+		return starQuality, starPos
+	} else if strings.EqualFold(encoding, "identity") {
+		// Acceptable with the lowest priority
+		return minQuality, pos
+	}
+	return 0, 0
+}
+
+// return the q parameter of a header value, 1 if not present
+func getQuality(params string) float64 {
+	for len(params) > 0 {
+		param := params
+		if semicolonPos := strings.IndexByte(params, ';'); semicolonPos != -1 {
+			param, params = params[:semicolonPos], params[semicolonPos+1:]
+		} else {
+			params = ""
+		}
+		param = utils.Trim(param, ' ')
+		if len(param) < 2 || (param[0] != 'q' && param[0] != 'Q') || param[1] != '=' {
+			continue
+		}
+		quality, err := strconv.ParseFloat(param[2:], 64)
+		if err != nil || quality > 1 {
+			return 1
+		} else if quality < 0 {
+			return 0
+		}
+		return quality
+	}
+	return 1
+}
+
+// normalizeIP returns the canonical form of an IP address, a port, brackets and
+// the zone of IPv6 addresses are removed and IPv4-mapped addresses are converted
+// to IPv4, e.g. "[::1]:8080" to "::1" and "::ffff:127.0.0.1" to "127.0.0.1".
+// Values which are no IP address are returned as is.
+func normalizeIP(raw string) string {
+	ip := utils.Trim(raw, ' ')
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	} else if len(ip) > 1 && ip[0] == '[' && ip[len(ip)-1] == ']' {
+		ip = ip[1 : len(ip)-1]
+	}
+	if zonePos := strings.IndexByte(ip, '%'); zonePos != -1 {
+		ip = ip[:zonePos]
+	}
+	if parsed := net.ParseIP(ip); parsed != nil {
+		return parsed.String()
+	}
+	return raw
+}
+
 func matchEtag(s string, etag string) bool {
 	if s == etag || s == "W/"+etag || "W/"+s == etag {
 		return true
@@ -314,6 +556,31 @@ func parseAddr(raw string) (host, port string) {
 	return raw, ""
 }
 
+// formKey converts the bracket notation of form keys to the dotted
+// notation of the schema decoder, e.g. "user[address][city]" to
+// "user.address.city" and "tags[]" to "tags"
+func formKey(key string) string {
+	if strings.IndexByte(key, '[') == -1 {
+		return key
+	}
+	b := make([]byte, 0, len(key))
+	for i := 0; i < len(key); i++ {
+		switch key[i] {
+		case '[':
+			// Empty brackets of repeated keys
+			if i+1 < len(key) && key[i+1] == ']' {
+				i++
+				continue
+			}
+			b = append(b, '.')
+		case ']':
+		default:
+			b = append(b, key[i])
+		}
+	}
+	return getString(b)
+}
+
 const noCacheValue = "no-cache"
 
 // isNoCache checks if the cacheControl header value is a `no-cache`.
@@ -342,6 +609,75 @@ func isNoCache(cacheControl string) bool {
 	return true
 }
 
+// isLinkRel reports whether rel is a space separated list of relation types,
+// either registered names like "next" or absolute URIs (RFC 5988 section 5.3)
+func isLinkRel(rel string) bool {
+	if len(rel) == 0 {
+		return false
+	}
+	for _, relType := range strings.Split(rel, " ") {
+		if len(relType) == 0 {
+			return false
+		}
+		// Extension relation types are absolute URIs
+		if strings.IndexByte(relType, ':') > 0 {
+			for i := 0; i < len(relType); i++ {
+				if !isLinkURIChar(relType[i]) || relType[i] == '"' {
+					return false
+				}
+			}
+			continue
+		}
+		// Registered relation types start with a letter and are case-insensitive
+		if b := relType[0] | 0x20; b < 'a' || b > 'z' {
+			return false
+		}
+		for i := 1; i < len(relType); i++ {
+			b := relType[i]
+			if !(b|0x20 >= 'a' && b|0x20 <= 'z' || b >= '0' && b <= '9' || b == '.' || b == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isLinkURIChar reports whether b can be used in a link target without escaping
+func isLinkURIChar(b byte) bool {
+	if b <= ' ' || b >= 0x7f {
+		return false
+	}
+	switch b {
+	case '<', '>', '"', '\\', '^', '`', '{', '|', '}':
+		return false
+	}
+	return true
+}
+
+// escapeLinkURL percent-encodes the characters that are not allowed
+// in the URI-Reference of a Link header, e.g. spaces and angle brackets
+func escapeLinkURL(raw string) string {
+	const hex = "0123456789ABCDEF"
+	var buf []byte
+	for i := 0; i < len(raw); i++ {
+		if isLinkURIChar(raw[i]) {
+			if buf != nil {
+				buf = append(buf, raw[i])
+			}
+			continue
+		}
+		if buf == nil {
+			buf = make([]byte, i, len(raw)+8)
+			copy(buf, raw[:i])
+		}
+		buf = append(buf, '%', hex[raw[i]>>4], hex[raw[i]&0x0f])
+	}
+	if buf == nil {
+		return raw
+	}
+	return getString(buf)
+}
+
 // https://golang.org/src/net/net.go#L113
 // Helper methods for application#test
 type testAddr string
@@ -356,6 +692,8 @@ func (a testAddr) String() string {
 type testConn struct {
 	r bytes.Buffer
 	w bytes.Buffer
+
+	remoteAddr net.Addr
 }
 
 func (c *testConn) Read(b []byte) (int, error)  { return c.r.Read(b) }
@@ -363,11 +701,34 @@ func (c *testConn) Write(b []byte) (int, error) { return c.w.Write(b) }
 func (c *testConn) Close() error                { return nil }
 
 func (c *testConn) LocalAddr() net.Addr                { return testAddr("local-addr") }
-func (c *testConn) RemoteAddr() net.Addr               { return testAddr("remote-addr") }
 func (c *testConn) SetDeadline(t time.Time) error      { return nil }
 func (c *testConn) SetReadDeadline(t time.Time) error  { return nil }
 func (c *testConn) SetWriteDeadline(t time.Time) error { return nil }
 
+func (c *testConn) RemoteAddr() net.Addr {
+	if c.remoteAddr != nil {
+		return c.remoteAddr
+	}
+	return testAddr("remote-addr")
+}
+
+// testDefaultRemoteAddr is the RemoteAddr set by httptest.NewRequest
+const testDefaultRemoteAddr = "192.0.2.1:1234"
+
+// parseTestRemoteAddr parses the "ip:port" remote address of a test request
+func parseTestRemoteAddr(raw string) (*net.TCPAddr, error) {
+	host, port, err := net.SplitHostPort(raw)
+	if err != nil {
+		return nil, fmt.Errorf("test: invalid remote address %s", raw)
+	}
+	ip := net.ParseIP(host)
+	portNum, err := strconv.Atoi(port)
+	if ip == nil || err != nil {
+		return nil, fmt.Errorf("test: invalid remote address %s", raw)
+	}
+	return &net.TCPAddr{IP: ip, Port: portNum}, nil
+}
+
 // getString converts byte slice to a string without memory allocation.
 var getString = utils.UnsafeString
 var getStringImmutable = func(b []byte) string {
@@ -435,15 +796,17 @@ const (
 
 // MIME types that are commonly used
 const (
-	MIMETextXML               = "text/xml"
-	MIMETextHTML              = "text/html"
-	MIMETextPlain             = "text/plain"
-	MIMEApplicationXML        = "application/xml"
-	MIMEApplicationJSON       = "application/json"
-	MIMEApplicationJavaScript = "application/javascript"
-	MIMEApplicationForm       = "application/x-www-form-urlencoded"
-	MIMEOctetStream           = "application/octet-stream"
-	MIMEMultipartForm         = "multipart/form-data"
+	MIMETextXML                = "text/xml"
+	MIMETextHTML               = "text/html"
+	MIMETextPlain              = "text/plain"
+	MIMEApplicationXML         = "application/xml"
+	MIMEApplicationJSON        = "application/json"
+	MIMEApplicationProblemJSON = "application/problem+json"
+	MIMEApplicationJavaScript  = "application/javascript"
+	MIMEApplicationForm        = "application/x-www-form-urlencoded"
+	MIMEOctetStream            = "application/octet-stream"
+	MIMEMultipartForm          = "multipart/form-data"
+	MIMETextEventStream        = "text/event-stream"
 
 	MIMETextXMLCharsetUTF8               = "text/xml; charset=utf-8"
 	MIMETextHTMLCharsetUTF8              = "text/html; charset=utf-8"
@@ -683,8 +1046,10 @@ const (
 	HeaderUpgrade                         = "Upgrade"
 	HeaderXDNSPrefetchControl             = "X-DNS-Prefetch-Control"
 	HeaderXPingback                       = "X-Pingback"
+	HeaderXAccelBuffering                 = "X-Accel-Buffering"
 	HeaderXRequestID                      = "X-Request-ID"
 	HeaderXRequestedWith                  = "X-Requested-With"
 	HeaderXRobotsTag                      = "X-Robots-Tag"
 	HeaderXUACompatible                   = "X-UA-Compatible"
 )
+
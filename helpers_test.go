@@ -182,6 +182,15 @@ func Test_Utils_getGroupPath(t *testing.T) {
 	utils.AssertEqual(t, "/v1/api/", res)
 }
 
+// go test -v -run Test_Utils_TrimPathSegments
+func Test_Utils_TrimPathSegments(t *testing.T) {
+	t.Parallel()
+	utils.AssertEqual(t, "/admin/users", trimPathSegments("/admin/users", 0))
+	utils.AssertEqual(t, "/users", trimPathSegments("/admin/users", 1))
+	utils.AssertEqual(t, "/doe/", trimPathSegments("/v1/john/doe/", 2))
+	utils.AssertEqual(t, "/", trimPathSegments("/admin", 1))
+}
+
 // go test -v -run=^$ -bench=Benchmark_Utils_ -benchmem -count=3
 
 func Benchmark_Utils_getGroupPath(b *testing.B) {
@@ -0,0 +1,27 @@
+// Package methods restricts middleware to a list of HTTP methods.
+package methods
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Next returns a Next function of a middleware config that skips the
+// middleware for the methods not listed, in addition to next.
+// next is returned as is if no methods are listed.
+func Next(next func(c *fiber.Ctx) bool, methods []string) func(c *fiber.Ctx) bool {
+	if len(methods) == 0 {
+		return next
+	}
+	allowed := make(map[string]struct{}, len(methods))
+	for _, method := range methods {
+		allowed[strings.ToUpper(method)] = struct{}{}
+	}
+	return func(c *fiber.Ctx) bool {
+		if _, ok := allowed[c.Method()]; !ok {
+			return true
+		}
+		return next != nil && next(c)
+	}
+}
@@ -0,0 +1,36 @@
+package methods
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/valyala/fasthttp"
+)
+
+// go test -run Test_Methods_Next
+func Test_Methods_Next(t *testing.T) {
+	t.Parallel()
+	app := fiber.New()
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+
+	utils.AssertEqual(t, true, Next(nil, nil) == nil)
+
+	next := Next(nil, []string{"get", fiber.MethodPut})
+	c.Method(fiber.MethodGet)
+	utils.AssertEqual(t, false, next(c))
+	c.Method(fiber.MethodPut)
+	utils.AssertEqual(t, false, next(c))
+	c.Method(fiber.MethodPost)
+	utils.AssertEqual(t, true, next(c))
+
+	next = Next(func(c *fiber.Ctx) bool {
+		return c.Path() == "/skip"
+	}, []string{fiber.MethodGet})
+	c.Method(fiber.MethodGet)
+	c.Path("/skip")
+	utils.AssertEqual(t, true, next(c))
+	c.Path("/")
+	utils.AssertEqual(t, false, next(c))
+}
@@ -0,0 +1,63 @@
+// Package pathrule compiles the path rules of the rewrite and redirect middleware.
+package pathrule
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Rule is a compiled path rule
+type Rule struct {
+	pattern *regexp.Regexp
+	to      string
+}
+
+// Compile compiles the rules, a '*' in a key matches any characters.
+// Longer rules are sorted first to apply the most specific rule.
+func Compile(rules map[string]string) []Rule {
+	keys := make([]string, 0, len(rules))
+	for key := range rules {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	compiled := make([]Rule, len(keys))
+	for i, key := range keys {
+		pattern := strings.Replace(regexp.QuoteMeta(key), `\*`, "(.*)", -1)
+		compiled[i] = Rule{
+			pattern: regexp.MustCompile("^" + pattern + "$"),
+			to:      rules[key],
+		}
+	}
+	return compiled
+}
+
+// Match returns the target of the first rule matching the path,
+// with $1, $2, ... replaced by the values matched by the '*'
+func Match(rules []Rule, path string) (string, bool) {
+	for _, r := range rules {
+		if captures := r.pattern.FindStringSubmatch(path); captures != nil {
+			return replaceCaptures(r.to, captures), true
+		}
+	}
+	return "", false
+}
+
+// replaceCaptures replaces $1, $2, ... with the captured values
+func replaceCaptures(to string, captures []string) string {
+	if len(captures) < 2 {
+		return to
+	}
+	// $10 has to be replaced before $1
+	oldnew := make([]string, 0, 2*(len(captures)-1))
+	for i := len(captures) - 1; i > 0; i-- {
+		oldnew = append(oldnew, "$"+strconv.Itoa(i), captures[i])
+	}
+	return strings.NewReplacer(oldnew...).Replace(to)
+}
@@ -0,0 +1,40 @@
+package pathrule
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// go test -run Test_PathRule_Match
+func Test_PathRule_Match(t *testing.T) {
+	t.Parallel()
+	rules := Compile(map[string]string{
+		"/api/*":       "/$1",
+		"/api/users/*": "/users/$1",
+		"/old":         "/new",
+	})
+
+	to, ok := Match(rules, "/api/users/42")
+	utils.AssertEqual(t, true, ok)
+	utils.AssertEqual(t, "/users/42", to)
+
+	to, ok = Match(rules, "/api/items")
+	utils.AssertEqual(t, true, ok)
+	utils.AssertEqual(t, "/items", to)
+
+	to, ok = Match(rules, "/old")
+	utils.AssertEqual(t, true, ok)
+	utils.AssertEqual(t, "/new", to)
+
+	_, ok = Match(rules, "/older")
+	utils.AssertEqual(t, false, ok)
+}
+
+// go test -run Test_PathRule_ReplaceCaptures
+func Test_PathRule_ReplaceCaptures(t *testing.T) {
+	t.Parallel()
+	captures := []string{"", "a", "b", "c", "d", "e", "f", "g", "h", "i", "j"}
+	utils.AssertEqual(t, "/j/a/b", replaceCaptures("/$10/$1/$2", captures))
+	utils.AssertEqual(t, "/static", replaceCaptures("/static", []string{"/static"}))
+}
@@ -63,6 +63,20 @@ func (c *cache) parsePath(p string, t reflect.Type) ([]pathPart, error) {
 		}
 		// Valid field. Append index.
 		path = append(path, field.name)
+		if field.isMap {
+			// Parse a special case: maps of basic types.
+			// i+1 must be the map key and the last key.
+			if i+2 != len(keys) {
+				return nil, invalidPath
+			}
+			parts = append(parts, pathPart{
+				path:   path,
+				field:  field,
+				index:  -1,
+				mapKey: keys[i+1],
+			})
+			return parts, nil
+		}
 		if field.isSliceOfStructs && (!field.unmarshalerInfo.IsValid || (field.unmarshalerInfo.IsValid && field.unmarshalerInfo.IsSliceElement)) {
 			// Parse a special case: slices of structs.
 			// i+1 must be the slice index.
@@ -163,12 +177,26 @@ func (c *cache) createField(field reflect.StructField, parentAlias string) *fiel
 	}
 	// Check if the type is supported and don't cache it if not.
 	// First let's get the basic type.
-	isSlice, isStruct := false, false
+	isSlice, isStruct, isMap := false, false, false
 	ft := field.Type
 	m := isTextUnmarshaler(reflect.Zero(ft))
 	if ft.Kind() == reflect.Ptr {
 		ft = ft.Elem()
 	}
+	if isMap = ft.Kind() == reflect.Map; isMap {
+		// Only maps of basic types with string keys are supported.
+		if ft.Key().Kind() != reflect.String {
+			return nil
+		}
+		ft = ft.Elem()
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		// The map values are decoded by a converter, e.g. arrays have none.
+		if c.converter(ft) == nil && builtinConverters[ft.Kind()] == nil {
+			return nil
+		}
+	}
 	if isSlice = ft.Kind() == reflect.Slice; isSlice {
 		ft = ft.Elem()
 		if ft.Kind() == reflect.Ptr {
@@ -195,6 +223,7 @@ func (c *cache) createField(field reflect.StructField, parentAlias string) *fiel
 		canonicalAlias:   canonicalAlias,
 		unmarshalerInfo:  m,
 		isSliceOfStructs: isSlice && isStruct,
+		isMap:            isMap,
 		isAnonymous:      field.Anonymous,
 		isRequired:       options.Contains("required"),
 	}
@@ -245,6 +274,8 @@ type fieldInfo struct {
 	unmarshalerInfo unmarshaler
 	// isSliceOfStructs indicates if the field type is a slice of structs.
 	isSliceOfStructs bool
+	// isMap indicates if the field type is a map of basic types.
+	isMap bool
 	// isAnonymous indicates whether the field is embedded in the struct.
 	isAnonymous bool
 	isRequired  bool
@@ -258,9 +289,10 @@ func (f *fieldInfo) paths(prefix string) []string {
 }
 
 type pathPart struct {
-	field *fieldInfo
-	path  []string // path to the field: walks structs using field names.
-	index int      // struct index in slices of structs.
+	field  *fieldInfo
+	path   []string // path to the field: walks structs using field names.
+	index  int      // struct index in slices of structs.
+	mapKey string   // key in maps of basic types.
 }
 
 // ----------------------------------------------------------------------------
@@ -178,6 +178,45 @@ func isEmpty(t reflect.Type, value []string) bool {
 	return false
 }
 
+// decodeMapValue sets the value of key in a map of basic types.
+func (d *Decoder) decodeMapValue(v reflect.Value, t reflect.Type, path, key string, values []string) error {
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(t))
+	}
+	elemT := t.Elem()
+	isPtrElem := elemT.Kind() == reflect.Ptr
+	if isPtrElem {
+		elemT = elemT.Elem()
+	}
+	conv := d.cache.converter(elemT)
+	if conv == nil {
+		conv = builtinConverters[elemT.Kind()]
+	}
+	// Use the last value provided if any values were provided
+	val := ""
+	if len(values) > 0 {
+		val = values[len(values)-1]
+	}
+	item := reflect.Zero(elemT)
+	if val != "" {
+		if item = conv(val); !item.IsValid() {
+			return ConversionError{
+				Key:   path,
+				Type:  elemT,
+				Index: -1,
+			}
+		}
+		item = item.Convert(elemT)
+	}
+	if isPtrElem {
+		ptr := reflect.New(elemT)
+		ptr.Elem().Set(item)
+		item = ptr
+	}
+	v.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), item)
+	return nil
+}
+
 // decode fills a struct field using a parsed path.
 func (d *Decoder) decode(v reflect.Value, path string, parts []pathPart, values []string) error {
 	// Get the field walking the struct fields by index.
@@ -216,6 +255,11 @@ func (d *Decoder) decode(v reflect.Value, path string, parts []pathPart, values
 		v = v.Elem()
 	}
 
+	// Map of basic types, keyed by the last part of the path.
+	if len(parts) == 1 && parts[0].field.isMap {
+		return d.decodeMapValue(v, t, path, parts[0].mapKey, values)
+	}
+
 	// Slice of structs. Let's go recursive.
 	if len(parts) > 1 {
 		idx := parts[0].index
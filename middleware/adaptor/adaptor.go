@@ -0,0 +1,95 @@
+package adaptor
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+)
+
+// HTTPHandler wraps a net/http handler to a fiber handler
+func HTTPHandler(h http.Handler) fiber.Handler {
+	handler := fasthttpadaptor.NewFastHTTPHandler(h)
+
+	// Return new handler
+	return func(c *fiber.Ctx) error {
+		handler(c.Context())
+		return nil
+	}
+}
+
+// HTTPMiddleware wraps a net/http middleware to a fiber middleware,
+// the stack continues when the middleware calls the next handler
+func HTTPMiddleware(mw func(http.Handler) http.Handler) fiber.Handler {
+	// Return new handler
+	return func(c *fiber.Ctx) error {
+		var next bool
+		nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next = true
+			// The middleware may have changed the request
+			c.Request().Header.SetMethod(r.Method)
+			c.Request().SetRequestURI(r.RequestURI)
+			c.Request().SetHost(r.Host)
+			for key, values := range r.Header {
+				c.Request().Header.Del(key)
+				for _, value := range values {
+					c.Request().Header.Add(key, value)
+				}
+			}
+		})
+		fasthttpadaptor.NewFastHTTPHandler(mw(nextHandler))(c.Context())
+
+		// The middleware has written the response itself
+		if !next {
+			return nil
+		}
+		return c.Next()
+	}
+}
+
+// FiberHandler wraps a fiber handler to a net/http handler,
+// returned errors are handled by the DefaultErrorHandler
+func FiberHandler(h fiber.Handler) http.HandlerFunc {
+	app := fiber.New()
+	app.Use(h)
+	handler := app.Handler()
+
+	// Return new handler
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := fasthttp.AcquireRequest()
+		defer fasthttp.ReleaseRequest(req)
+
+		// Convert net/http -> fasthttp request
+		if r.Body != nil {
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			req.SetBody(body)
+		}
+		req.Header.SetMethod(r.Method)
+		req.SetRequestURI(r.RequestURI)
+		req.SetHost(r.Host)
+		for key, values := range r.Header {
+			for _, value := range values {
+				req.Header.Add(key, value)
+			}
+		}
+		remoteAddr, _ := net.ResolveTCPAddr("tcp", r.RemoteAddr)
+
+		var fctx fasthttp.RequestCtx
+		fctx.Init(req, remoteAddr, nil)
+		handler(&fctx)
+
+		// Convert fasthttp -> net/http response
+		fctx.Response.Header.VisitAll(func(key, value []byte) {
+			w.Header().Add(string(key), string(value))
+		})
+		w.WriteHeader(fctx.Response.StatusCode())
+		_, _ = w.Write(fctx.Response.Body())
+	}
+}
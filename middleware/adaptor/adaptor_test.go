@@ -0,0 +1,105 @@
+package adaptor
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// go test -run Test_HTTPHandler
+func Test_HTTPHandler(t *testing.T) {
+	app := fiber.New()
+
+	app.Post("/", HTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		utils.AssertEqual(t, nil, err)
+		w.Header().Set("X-Request-Header", r.Header.Get("X-Request-Header"))
+		w.Header().Set(fiber.HeaderContentType, fiber.MIMETextPlain)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("echo: " + string(body)))
+	})))
+
+	req := httptest.NewRequest(fiber.MethodPost, "/", strings.NewReader("hello"))
+	req.Header.Set("X-Request-Header", "fiber")
+	resp, err := app.Test(req)
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, fiber.StatusCreated, resp.StatusCode)
+	utils.AssertEqual(t, "fiber", resp.Header.Get("X-Request-Header"))
+	utils.AssertEqual(t, fiber.MIMETextPlain, resp.Header.Get(fiber.HeaderContentType))
+
+	body, err := ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "echo: hello", string(body))
+}
+
+// go test -run Test_HTTPMiddleware
+func Test_HTTPMiddleware(t *testing.T) {
+	app := fiber.New()
+
+	app.Use(HTTPMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			r.Header.Set("X-User", "john")
+			w.Header().Set("X-Middleware", "net/http")
+			next.ServeHTTP(w, r)
+		})
+	}))
+
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString("hello " + c.Get("X-User"))
+	})
+
+	// the middleware handles the request
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, fiber.StatusUnauthorized, resp.StatusCode)
+	body, err := ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "unauthorized\n", string(body))
+
+	// the middleware passes the request to the next fiber handler
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "secret")
+	resp, err = app.Test(req)
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, fiber.StatusOK, resp.StatusCode)
+	utils.AssertEqual(t, "net/http", resp.Header.Get("X-Middleware"))
+	body, err = ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "hello john", string(body))
+}
+
+// go test -run Test_FiberHandler
+func Test_FiberHandler(t *testing.T) {
+	handler := FiberHandler(func(c *fiber.Ctx) error {
+		if c.Query("fail") != "" {
+			return fiber.ErrTeapot
+		}
+		c.Set("X-Request-Header", c.Get("X-Request-Header"))
+		return c.Status(fiber.StatusCreated).SendString(c.Method() + " " + c.Path() + " " + string(c.Body()))
+	})
+
+	req := httptest.NewRequest(fiber.MethodPut, "/foo", strings.NewReader("hello"))
+	req.Header.Set("X-Request-Header", "net/http")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	utils.AssertEqual(t, fiber.StatusCreated, w.Code)
+	utils.AssertEqual(t, "net/http", w.Header().Get("X-Request-Header"))
+	utils.AssertEqual(t, fiber.MIMETextPlainCharsetUTF8, w.Header().Get(fiber.HeaderContentType))
+	utils.AssertEqual(t, "PUT /foo hello", w.Body.String())
+
+	// errors are handled by the error handler
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(fiber.MethodGet, "/?fail=1", nil))
+	utils.AssertEqual(t, fiber.StatusTeapot, w.Code)
+	utils.AssertEqual(t, "I'm a teapot", w.Body.String())
+}
@@ -1,11 +1,13 @@
 package basicauth
 
 import (
+	"crypto/subtle"
 	"encoding/base64"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/utils"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // Config defines the config for middleware.
@@ -15,7 +17,8 @@ type Config struct {
 	// Optional. Default: nil
 	Next func(c *fiber.Ctx) bool
 
-	// Users defines the allowed credentials
+	// Users defines the allowed credentials with plaintext passwords,
+	// use BcryptAuthorizer to store bcrypt hashes instead
 	//
 	// Required. Default: map[string]string{}
 	Users map[string]string
@@ -32,6 +35,7 @@ type Config struct {
 	// It will be called with a username and password
 	// and is expected to return true or false to indicate
 	// that the credentials were approved or not.
+	// BcryptAuthorizer checks the credentials against bcrypt hashes.
 	//
 	// Optional. Default: nil.
 	Authorizer func(string, string) bool
@@ -84,7 +88,8 @@ func New(config Config) fiber.Handler {
 			if !exist {
 				return false
 			}
-			return user == pass
+			// Compare in constant time to not leak the password by timing
+			return subtle.ConstantTimeCompare(utils.UnsafeBytes(user), utils.UnsafeBytes(pass)) == 1
 		}
 	}
 	if cfg.Unauthorized == nil {
@@ -145,3 +150,33 @@ func New(config Config) fiber.Handler {
 		return cfg.Unauthorized(c)
 	}
 }
+
+// BcryptAuthorizer returns an Authorizer that checks the credentials
+// against the bcrypt hashes of the users passwords.
+//  app.Use(basicauth.New(basicauth.Config{
+//    Authorizer: basicauth.BcryptAuthorizer(map[string]string{
+//      "john": "$2a$10$buimqb9wCXYSnKWo3av/fOr3T1W0aLbgTY7nRbcf2ajBMFBtEoJqy",
+//    }),
+//  }))
+func BcryptAuthorizer(users map[string]string) func(string, string) bool {
+	// The dummy hash is compared for unknown users, so they take as long as
+	// known users. It uses the highest cost of the users hashes.
+	cost := bcrypt.MinCost
+	for _, hash := range users {
+		if hashCost, err := bcrypt.Cost(utils.UnsafeBytes(hash)); err == nil && hashCost > cost {
+			cost = hashCost
+		}
+	}
+	dummyHash, err := bcrypt.GenerateFromPassword([]byte("dummy"), cost)
+	if err != nil {
+		panic(err)
+	}
+	return func(user, pass string) bool {
+		hash, exist := users[user]
+		if !exist {
+			_ = bcrypt.CompareHashAndPassword(dummyHash, utils.UnsafeBytes(pass))
+			return false
+		}
+		return bcrypt.CompareHashAndPassword(utils.UnsafeBytes(hash), utils.UnsafeBytes(pass)) == nil
+	}
+}
@@ -92,6 +92,50 @@ func Test_Middleware_BasicAuth(t *testing.T) {
 	}
 }
 
+// go test -run Test_BasicAuth_Bcrypt
+func Test_BasicAuth_Bcrypt(t *testing.T) {
+	t.Parallel()
+	app := fiber.New()
+
+	app.Use(New(Config{
+		Authorizer: BcryptAuthorizer(map[string]string{
+			"john": "$2a$04$QZT1osBH9qj6xsBt8xyFbO9UnSs2KSnywU.RiwlBg8TZ3pme8Zpdm", // doe
+		}),
+	}))
+
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(c.Locals("username").(string))
+	})
+
+	tests := []struct {
+		username   string
+		password   string
+		statusCode int
+	}{
+		{"john", "doe", fiber.StatusOK},
+		{"john", "wrong", fiber.StatusUnauthorized},
+		{"john", "$2a$04$QZT1osBH9qj6xsBt8xyFbO9UnSs2KSnywU.RiwlBg8TZ3pme8Zpdm", fiber.StatusUnauthorized},
+		{"john", "", fiber.StatusUnauthorized},
+		{"doe", "doe", fiber.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		creds := b64.StdEncoding.EncodeToString([]byte(tt.username + ":" + tt.password))
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Add("Authorization", "Basic "+creds)
+		resp, err := app.Test(req)
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, tt.statusCode, resp.StatusCode, tt.username+":"+tt.password)
+
+		if tt.statusCode == fiber.StatusOK {
+			body, err := ioutil.ReadAll(resp.Body)
+			utils.AssertEqual(t, nil, err)
+			utils.AssertEqual(t, tt.username, string(body))
+		}
+	}
+}
+
 // go test -v -run=^$ -bench=Benchmark_Middleware_BasicAuth -benchmem -count=4
 func Benchmark_Middleware_BasicAuth(b *testing.B) {
 	app := fiber.New()
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
 )
 
 // Config defines the config for middleware.
@@ -26,6 +27,12 @@ type Config struct {
 	//
 	// Optional. Default: false
 	CacheControl bool
+
+	// Storage holds the cached responses, a shared storage allows
+	// to invalidate entries with Storage.Delete when data changes
+	//
+	// Optional. Default: NewStorage()
+	Storage *Storage
 }
 
 // ConfigDefault is the default config
@@ -33,13 +40,39 @@ var ConfigDefault = Config{
 	Next:         nil,
 	Expiration:   1 * time.Minute,
 	CacheControl: false,
+	Storage:      nil,
 }
 
-// cache is the manager to store the cached responses
-type cache struct {
+// Cache status values of the X-Cache response header
+const (
+	headerXCache = "X-Cache"
+	cacheHit     = "HIT"
+	cacheMiss    = "MISS"
+)
+
+// Storage stores the cached responses by request path
+type Storage struct {
 	sync.RWMutex
-	entries    map[string]entry
-	expiration int64
+	entries map[string]entry
+}
+
+// NewStorage creates an empty storage for cached responses
+func NewStorage() *Storage {
+	return &Storage{entries: make(map[string]entry)}
+}
+
+// Delete invalidates the cached response of the request path
+func (s *Storage) Delete(key string) {
+	s.Lock()
+	delete(s.entries, key)
+	s.Unlock()
+}
+
+// Reset invalidates all cached responses
+func (s *Storage) Reset() {
+	s.Lock()
+	s.entries = make(map[string]entry)
+	s.Unlock()
 }
 
 // entry defines the cached response
@@ -76,10 +109,11 @@ func New(config ...Config) fiber.Handler {
 	}
 
 	// Initialize db
-	db := &cache{
-		entries:    make(map[string]entry),
-		expiration: int64(cfg.Expiration.Seconds()),
+	db := cfg.Storage
+	if db == nil {
+		db = NewStorage()
 	}
+	expiration := int64(cfg.Expiration.Seconds())
 	// Remove expired entries
 	go func() {
 		for {
@@ -130,22 +164,24 @@ func New(config ...Config) fiber.Handler {
 					maxAge := strconv.FormatInt(resp.expiration-time.Now().Unix(), 10)
 					c.Set(fiber.HeaderCacheControl, "public, max-age="+maxAge)
 				}
+				c.Set(headerXCache, cacheHit)
 				return nil
 			}
 		}
 
 		// Continue stack, return err to Fiber if exist
+		c.Set(headerXCache, cacheMiss)
 		if err := c.Next(); err != nil {
 			return err
 		}
 
-		// Cache response
+		// Cache response, the response buffers are reused after the request
 		db.Lock()
 		db.entries[key] = entry{
-			body:        c.Response().Body(),
+			body:        utils.SafeBytes(c.Response().Body()),
 			statusCode:  c.Response().StatusCode(),
-			contentType: c.Response().Header.ContentType(),
-			expiration:  time.Now().Unix() + db.expiration,
+			contentType: utils.SafeBytes(c.Response().Header.ContentType()),
+			expiration:  time.Now().Unix() + expiration,
 		}
 		db.Unlock()
 
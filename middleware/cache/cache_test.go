@@ -181,6 +181,44 @@ func Test_Cache_NothingToCache(t *testing.T) {
 	}
 }
 
+// go test -run Test_Cache_Invalidate
+func Test_Cache_Invalidate(t *testing.T) {
+	app := fiber.New()
+
+	storage := NewStorage()
+	app.Use(New(Config{Storage: storage}))
+
+	var count int
+	app.Get("/", func(c *fiber.Ctx) error {
+		count++
+		return c.SendString(fmt.Sprintf("%d", count))
+	})
+
+	expect := func(xCache, body string) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, xCache, resp.Header.Get("X-Cache"))
+		b, err := ioutil.ReadAll(resp.Body)
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, body, string(b))
+	}
+
+	expect("MISS", "1")
+	expect("HIT", "1")
+
+	// Invalidated entries are cached again
+	storage.Delete("/")
+	expect("MISS", "2")
+	expect("HIT", "2")
+
+	storage.Delete("/unknown")
+	expect("HIT", "2")
+
+	storage.Reset()
+	expect("MISS", "3")
+	expect("HIT", "3")
+}
+
 // go test -v -run=^$ -bench=Benchmark_Cache -benchmem -count=4
 func Benchmark_Cache(b *testing.B) {
 	app := fiber.New()
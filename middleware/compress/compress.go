@@ -1,7 +1,14 @@
 package compress
 
 import (
+	"bytes"
+	"strconv"
+	"strings"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/internal/bytebufferpool"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/klauspost/compress/zstd"
 	"github.com/valyala/fasthttp"
 )
 
@@ -12,6 +19,13 @@ type Config struct {
 	// Optional. Default: nil
 	Next func(c *fiber.Ctx) bool
 
+	// ExcludePaths lists the paths whose responses are never compressed,
+	// e.g. event streams or already compressed downloads. A path ending
+	// with '*' excludes every path with that prefix, e.g. "/downloads/*".
+	//
+	// Optional. Default: nil
+	ExcludePaths []string
+
 	// Level determines the compression algorithm
 	//
 	// Optional. Default: LevelDefault
@@ -20,6 +34,22 @@ type Config struct {
 	// LevelBestSpeed:        1
 	// LevelBestCompression:  2
 	Level Level
+
+	// EnableZstd compresses responses with zstd for clients that prefer it by
+	// q-value, on equal q-values brotli is still preferred over zstd.
+	// HEAD requests and responses with another status than 200 are not compressed.
+	//
+	// Optional. Default: false
+	EnableZstd bool
+
+	// ZstdLevel determines the zstd compression level if EnableZstd is set
+	//
+	// Optional. Default: LevelDefault
+	// LevelDisabled:         -1
+	// LevelDefault:          0
+	// LevelBestSpeed:        1
+	// LevelBestCompression:  2
+	ZstdLevel Level
 }
 
 // Level is numeric representation of compression level
@@ -35,10 +65,15 @@ const (
 
 // ConfigDefault is the default config
 var ConfigDefault = Config{
-	Next:  nil,
-	Level: LevelDefault,
+	Next:       nil,
+	Level:      LevelDefault,
+	EnableZstd: false,
+	ZstdLevel:  LevelDefault,
 }
 
+// Bodies smaller than this are not compressed, same as fasthttp
+const minCompressLen = 200
+
 // New creates a new middleware handler
 func New(config ...Config) fiber.Handler {
 	// Set default config
@@ -52,6 +87,9 @@ func New(config ...Config) fiber.Handler {
 		if cfg.Level < LevelDisabled || cfg.Level > LevelBestCompression {
 			cfg.Level = ConfigDefault.Level
 		}
+		if cfg.ZstdLevel < LevelDisabled || cfg.ZstdLevel > LevelBestCompression {
+			cfg.ZstdLevel = ConfigDefault.ZstdLevel
+		}
 	}
 
 	// Setup request handlers
@@ -87,6 +125,23 @@ func New(config ...Config) fiber.Handler {
 		}
 	}
 
+	// Setup zstd encoder, a single encoder is safe for concurrent
+	// use with EncodeAll and keeps a pool of its internal encoders
+	var encoder *zstd.Encoder
+	if cfg.EnableZstd && cfg.ZstdLevel != LevelDisabled {
+		zstdLevel := zstd.SpeedDefault
+		switch cfg.ZstdLevel {
+		case LevelBestSpeed:
+			zstdLevel = zstd.SpeedFastest
+		case LevelBestCompression:
+			zstdLevel = zstd.SpeedBestCompression
+		}
+		var err error
+		if encoder, err = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstdLevel)); err != nil {
+			panic(err)
+		}
+	}
+
 	// Return new handler
 	return func(c *fiber.Ctx) error {
 		// Don't execute middleware if Next returns true
@@ -94,15 +149,112 @@ func New(config ...Config) fiber.Handler {
 			return c.Next()
 		}
 
+		// Don't compress excluded paths
+		if excluded(cfg.ExcludePaths, c.Path()) {
+			return c.Next()
+		}
+
 		// Continue stack
 		if err := c.Next(); err != nil {
 			return err
 		}
 
-		// Compress response
+		// Event streams are never compressed, every event is sent as written
+		if bytes.HasPrefix(c.Context().Response.Header.ContentType(), []byte(fiber.MIMETextEventStream)) {
+			return nil
+		}
+
+		// Compress response, preferring br > zstd > gzip > deflate.
+		// Streamed bodies are left to fasthttp, which compresses them on the fly
+		// flushing every write, so they are never buffered as a whole
+		if encoder != nil && !c.Context().Response.IsBodyStream() && acceptsZstd(c) {
+			compressZstd(c.Context(), encoder)
+			return nil
+		}
 		compressor(c.Context())
 
 		// Return from handler
 		return nil
 	}
 }
+
+// excluded reports whether the path matches one of the excluded paths
+func excluded(paths []string, path string) bool {
+	for _, exclude := range paths {
+		if strings.HasSuffix(exclude, "*") {
+			if strings.HasPrefix(path, exclude[:len(exclude)-1]) {
+				return true
+			}
+		} else if path == exclude {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptsZstd reports whether the client prefers zstd over the other encodings,
+// on equal q-values brotli is preferred over zstd and zstd over gzip and deflate
+func acceptsZstd(c *fiber.Ctx) bool {
+	header := c.Get(fiber.HeaderAcceptEncoding)
+	if header == "" {
+		return false
+	}
+	zstdQuality := encodingQuality(header, "zstd")
+	return zstdQuality > 0 &&
+		zstdQuality > encodingQuality(header, "br") &&
+		zstdQuality >= encodingQuality(header, "gzip") &&
+		zstdQuality >= encodingQuality(header, "deflate")
+}
+
+// encodingQuality returns the q-value of the encoding in the Accept-Encoding header,
+// encodings without q-value have the quality 1 and unlisted encodings match '*'
+func encodingQuality(header, encoding string) float64 {
+	starQuality := 0.0
+	for _, spec := range strings.Split(header, ",") {
+		quality := 1.0
+		if factorSign := strings.IndexByte(spec, ';'); factorSign != -1 {
+			param := utils.Trim(spec[factorSign+1:], ' ')
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					quality = q
+				}
+			}
+			spec = spec[:factorSign]
+		}
+		spec = utils.Trim(spec, ' ')
+		if strings.EqualFold(spec, encoding) {
+			return quality
+		} else if spec == "*" {
+			starQuality = quality
+		}
+	}
+	return starQuality
+}
+
+// compressZstd compresses the response body like fasthttp does for the other encodings
+func compressZstd(fctx *fasthttp.RequestCtx, encoder *zstd.Encoder) {
+	resp := &fctx.Response
+	// Responses without body and partial responses are not compressed
+	if fctx.IsHead() || resp.StatusCode() != fiber.StatusOK {
+		return
+	}
+	if len(resp.Header.Peek(fiber.HeaderContentEncoding)) > 0 {
+		// The body is already compressed
+		return
+	}
+	// Only text and application content types are compressible
+	contentType := resp.Header.ContentType()
+	if !bytes.HasPrefix(contentType, []byte("text/")) && !bytes.HasPrefix(contentType, []byte("application/")) {
+		return
+	}
+	body := resp.Body()
+	if len(body) < minCompressLen {
+		return
+	}
+	bb := bytebufferpool.Get()
+	bb.B = encoder.EncodeAll(body, bb.B)
+	resp.SetBody(bb.B)
+	bytebufferpool.Put(bb)
+	resp.Header.Set(fiber.HeaderContentEncoding, "zstd")
+	resp.Header.Add(fiber.HeaderVary, fiber.HeaderAcceptEncoding)
+}
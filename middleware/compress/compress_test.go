@@ -1,14 +1,22 @@
 package compress
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/utils"
+	"github.com/klauspost/compress/zstd"
+	"github.com/valyala/fasthttp/fasthttputil"
 )
 
 var filedata []byte
@@ -46,6 +54,147 @@ func Test_Compress_Gzip(t *testing.T) {
 	utils.AssertEqual(t, true, len(body) < len(filedata))
 }
 
+// go test -run Test_Compress_Gzip_Stream
+func Test_Compress_Gzip_Stream(t *testing.T) {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+
+	app.Use(New())
+
+	chunk := bytes.Repeat([]byte("Hello, World! "), 1024)
+	chunks := 64
+	proceed := make(chan struct{})
+	app.Get("/", func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			for i := 0; i < chunks; i++ {
+				_, _ = w.Write(chunk)
+				_ = w.Flush()
+				if i == 0 {
+					// Wait until the client received the first chunk
+					<-proceed
+				}
+			}
+		})
+		return nil
+	})
+
+	ln := fasthttputil.NewInmemoryListener()
+	go func() {
+		utils.AssertEqual(t, nil, app.Listener(ln))
+	}()
+	defer func() {
+		utils.AssertEqual(t, nil, app.Shutdown())
+	}()
+
+	conn, err := ln.Dial()
+	utils.AssertEqual(t, nil, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("GET / HTTP/1.1\r\nHost: example.com\r\nAccept-Encoding: gzip\r\n\r\n"))
+	utils.AssertEqual(t, nil, err)
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 200, resp.StatusCode, "Status code")
+	utils.AssertEqual(t, "gzip", resp.Header.Get(fiber.HeaderContentEncoding))
+	utils.AssertEqual(t, []string{"chunked"}, resp.TransferEncoding)
+	utils.AssertEqual(t, int64(-1), resp.ContentLength)
+
+	gr, err := gzip.NewReader(resp.Body)
+	utils.AssertEqual(t, nil, err)
+
+	// The first chunk is decompressed while the handler is still writing
+	first := make([]byte, len(chunk))
+	_, err = io.ReadFull(gr, first)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, chunk, first)
+	close(proceed)
+
+	rest, err := ioutil.ReadAll(gr)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, bytes.Repeat(chunk, chunks-1), rest)
+}
+
+// go test -run Test_Compress_ExcludePaths
+func Test_Compress_ExcludePaths(t *testing.T) {
+	app := fiber.New()
+
+	app.Use(New(Config{
+		ExcludePaths: []string{"/events", "/downloads/*"},
+		EnableZstd:   true,
+	}))
+
+	app.Get("/events", func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, "text/event-stream")
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			for i := 0; i < 3; i++ {
+				_, _ = w.Write(filedata)
+				_ = w.Flush()
+			}
+		})
+		return nil
+	})
+	app.Get("/*", func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+		return c.Send(filedata)
+	})
+
+	tests := []struct {
+		path     string
+		encoding string
+	}{
+		{"/events", ""},
+		{"/downloads/file.txt", ""},
+		{"/events/other", "gzip"},
+		{"/", "gzip"},
+	}
+
+	for _, tt := range tests {
+		for _, accept := range []string{"gzip", "br", "zstd"} {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.Header.Set("Accept-Encoding", accept)
+
+			resp, err := app.Test(req)
+			utils.AssertEqual(t, nil, err, "app.Test(req)")
+			utils.AssertEqual(t, 200, resp.StatusCode, "Status code")
+			encoding := tt.encoding
+			if encoding != "" {
+				encoding = accept
+			}
+			utils.AssertEqual(t, encoding, resp.Header.Get(fiber.HeaderContentEncoding), tt.path+" "+accept)
+
+			// Excluded responses are sent as is
+			if encoding == "" {
+				body, err := ioutil.ReadAll(resp.Body)
+				utils.AssertEqual(t, nil, err)
+				utils.AssertEqual(t, true, bytes.HasPrefix(body, filedata))
+			}
+		}
+	}
+}
+
+// go test -run Test_Compress_EventStream
+func Test_Compress_EventStream(t *testing.T) {
+	app := fiber.New()
+
+	app.Use(New())
+
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendEventStream(func(w *bufio.Writer) {
+			_ = fiber.Event{Data: string(filedata)}.Write(w)
+		})
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	resp, err := app.Test(req)
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, "", resp.Header.Get(fiber.HeaderContentEncoding))
+	body, err := ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, bytes.HasPrefix(body, []byte("data: ")))
+}
+
 // go test -run Test_Compress_Different_Level
 func Test_Compress_Different_Level(t *testing.T) {
 	levels := []Level{LevelBestSpeed, LevelBestCompression}
@@ -122,6 +271,131 @@ func Test_Compress_Brotli(t *testing.T) {
 	utils.AssertEqual(t, true, len(body) < len(filedata))
 }
 
+// go test -run Test_Compress_Zstd
+func Test_Compress_Zstd(t *testing.T) {
+	app := fiber.New()
+
+	app.Use(New(Config{EnableZstd: true}))
+
+	app.Get("/", func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+		return c.Send(filedata)
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate, zstd")
+
+	resp, err := app.Test(req)
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, 200, resp.StatusCode, "Status code")
+	utils.AssertEqual(t, "zstd", resp.Header.Get(fiber.HeaderContentEncoding))
+	utils.AssertEqual(t, fiber.HeaderAcceptEncoding, resp.Header.Get(fiber.HeaderVary))
+
+	// Validate that the file size has shrunk and decompresses to the original
+	body, err := ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, len(body) < len(filedata))
+
+	decoder, err := zstd.NewReader(nil)
+	utils.AssertEqual(t, nil, err)
+	defer decoder.Close()
+	decoded, err := decoder.DecodeAll(body, nil)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, filedata, decoded)
+
+	// Brotli is preferred over zstd
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "zstd, br")
+
+	resp, err = app.Test(req, 10000)
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, "br", resp.Header.Get(fiber.HeaderContentEncoding))
+
+	// Rejected brotli doesn't prevent zstd
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "br;q=0, zstd")
+
+	resp, err = app.Test(req)
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, "zstd", resp.Header.Get(fiber.HeaderContentEncoding))
+
+	// Rejected zstd is not used
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "zstd;q=0, gzip")
+
+	resp, err = app.Test(req)
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, "gzip", resp.Header.Get(fiber.HeaderContentEncoding))
+
+	// The q-values of the client are compared
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip, zstd;q=0.1")
+
+	resp, err = app.Test(req)
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, "gzip", resp.Header.Get(fiber.HeaderContentEncoding))
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "br;q=0.5, gzip;q=0.8, zstd")
+
+	resp, err = app.Test(req)
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, "zstd", resp.Header.Get(fiber.HeaderContentEncoding))
+}
+
+// go test -run Test_Compress_Zstd_Skip
+func Test_Compress_Zstd_Skip(t *testing.T) {
+	app := fiber.New()
+
+	app.Use(New(Config{EnableZstd: true}))
+
+	app.All("/:status", func(c *fiber.Ctx) error {
+		status, err := strconv.Atoi(c.Params("status"))
+		if err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+		return c.Status(status).Send(filedata)
+	})
+
+	testCases := []struct {
+		method string
+		status int
+	}{
+		{fiber.MethodHead, fiber.StatusOK},
+		{fiber.MethodGet, fiber.StatusPartialContent},
+		{fiber.MethodGet, fiber.StatusNotFound},
+	}
+	for _, tc := range testCases {
+		req := httptest.NewRequest(tc.method, "/"+strconv.Itoa(tc.status), nil)
+		req.Header.Set("Accept-Encoding", "zstd")
+
+		resp, err := app.Test(req)
+		utils.AssertEqual(t, nil, err, "app.Test(req)")
+		utils.AssertEqual(t, tc.status, resp.StatusCode, "Status code")
+		utils.AssertEqual(t, "", resp.Header.Get(fiber.HeaderContentEncoding), tc.method)
+	}
+}
+
+// go test -run Test_Compress_Zstd_Disabled
+func Test_Compress_Zstd_Disabled(t *testing.T) {
+	app := fiber.New()
+
+	app.Use(New())
+
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.Send(filedata)
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "zstd, gzip")
+
+	resp, err := app.Test(req)
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, 200, resp.StatusCode, "Status code")
+	utils.AssertEqual(t, "gzip", resp.Header.Get(fiber.HeaderContentEncoding))
+}
+
 func Test_Compress_Disabled(t *testing.T) {
 	app := fiber.New()
 
@@ -16,6 +16,9 @@ type Config struct {
 	Next func(c *fiber.Ctx) bool
 
 	// AllowOrigin defines a list of origins that may access the resource.
+	// A "*" label in the host matches exactly one subdomain level,
+	// e.g. "https://*.example.com" allows "https://a.example.com"
+	// but not "https://a.b.example.com".
 	//
 	// Optional. Default value "*"
 	AllowOrigins string
@@ -47,7 +50,9 @@ type Config struct {
 	ExposeHeaders string
 
 	// MaxAge indicates how long (in seconds) the results of a preflight request
-	// can be cached.
+	// can be cached. With 0 the header is not sent and the browser default is
+	// used, a negative value sends "Access-Control-Max-Age: 0" to disable the
+	// caching.
 	//
 	// Optional. Default value 0.
 	MaxAge int
@@ -97,8 +102,11 @@ func New(config ...Config) fiber.Handler {
 	allowHeaders := strings.Replace(cfg.AllowHeaders, " ", "", -1)
 	exposeHeaders := strings.Replace(cfg.ExposeHeaders, " ", "", -1)
 
-	// Convert int to string
+	// Convert int to string, a negative MaxAge disables the caching
 	maxAge := strconv.Itoa(cfg.MaxAge)
+	if cfg.MaxAge < 0 {
+		maxAge = "0"
+	}
 
 	// Return new handler
 	return func(c *fiber.Ctx) error {
@@ -163,8 +171,8 @@ func New(config ...Config) fiber.Handler {
 			}
 		}
 
-		// Set MaxAge is set
-		if cfg.MaxAge > 0 {
+		// Set MaxAge if set
+		if cfg.MaxAge != 0 {
 			c.Set(fiber.HeaderAccessControlMaxAge, maxAge)
 		}
 
@@ -86,6 +86,49 @@ func Test_CORS_Wildcard(t *testing.T) {
 
 }
 
+// go test -run Test_CORS_MaxAge_ExposeHeaders
+func Test_CORS_MaxAge_ExposeHeaders(t *testing.T) {
+	testCases := []struct {
+		maxAge int
+		header string
+	}{
+		{3600, "3600"},
+		{0, ""},
+		{-1, "0"},
+	}
+	for _, tc := range testCases {
+		app := fiber.New()
+		app.Use(New(Config{
+			MaxAge:        tc.maxAge,
+			ExposeHeaders: "X-Request-ID, Content-Length",
+		}))
+		app.Get("/", func(c *fiber.Ctx) error {
+			return c.SendString("Hello, World!")
+		})
+		handler := app.Handler()
+
+		// Preflight request
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI("/")
+		ctx.Request.Header.SetMethod(fiber.MethodOptions)
+		handler(ctx)
+
+		utils.AssertEqual(t, fiber.StatusNoContent, ctx.Response.StatusCode())
+		utils.AssertEqual(t, tc.header, string(ctx.Response.Header.Peek(fiber.HeaderAccessControlMaxAge)))
+		utils.AssertEqual(t, "", string(ctx.Response.Header.Peek(fiber.HeaderAccessControlExposeHeaders)))
+
+		// Actual request
+		ctx = &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI("/")
+		ctx.Request.Header.SetMethod(fiber.MethodGet)
+		handler(ctx)
+
+		utils.AssertEqual(t, fiber.StatusOK, ctx.Response.StatusCode())
+		utils.AssertEqual(t, "X-Request-ID,Content-Length", string(ctx.Response.Header.Peek(fiber.HeaderAccessControlExposeHeaders)))
+		utils.AssertEqual(t, "", string(ctx.Response.Header.Peek(fiber.HeaderAccessControlMaxAge)))
+	}
+}
+
 // go test -run -v Test_CORS_Subdomain
 func Test_CORS_Subdomain(t *testing.T) {
 	// New fiber instance
@@ -121,6 +164,36 @@ func Test_CORS_Subdomain(t *testing.T) {
 	utils.AssertEqual(t, "http://test.example.com", string(ctx.Response.Header.Peek(fiber.HeaderAccessControlAllowOrigin)))
 }
 
+// go test -run Test_CORS_Subdomain_Single_Label
+func Test_CORS_Subdomain_Single_Label(t *testing.T) {
+	app := fiber.New()
+	app.Use(New(Config{AllowOrigins: "https://example.com, https://*.example.com"}))
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	tests := []struct {
+		origin, allowOrigin string
+	}{
+		{"https://a.example.com", "https://a.example.com"},
+		{"https://example.com", "https://example.com"},
+		// the wildcard matches a single subdomain level
+		{"https://a.b.example.com", ""},
+		{"https://evil.com", ""},
+		{"https://a.example.com.evil.com", ""},
+		{"http://a.example.com", ""},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+		req.Header.Set(fiber.HeaderOrigin, tt.origin)
+		resp, err := app.Test(req)
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, tt.allowOrigin, resp.Header.Get(fiber.HeaderAccessControlAllowOrigin), tt.origin)
+		utils.AssertEqual(t, fiber.HeaderOrigin, resp.Header.Get(fiber.HeaderVary), tt.origin)
+	}
+}
+
 func Test_CORS_AllowOriginScheme(t *testing.T) {
 	tests := []struct {
 		reqOrigin, pattern string
@@ -149,7 +222,7 @@ func Test_CORS_AllowOriginScheme(t *testing.T) {
 		{
 			pattern:           "http://*.example.com",
 			reqOrigin:         "http://bbb.aaa.example.com",
-			shouldAllowOrigin: true,
+			shouldAllowOrigin: false,
 		},
 		{
 			pattern:           "http://*.aaa.example.com",
@@ -192,8 +265,23 @@ func Test_CORS_AllowOriginScheme(t *testing.T) {
 		{
 			pattern:           "http://*.example.com",
 			reqOrigin:         "http://ccc.bbb.example.com",
+			shouldAllowOrigin: false,
+		},
+		{
+			pattern:           "http://api.*.example.com",
+			reqOrigin:         "http://api.eu.example.com",
 			shouldAllowOrigin: true,
 		},
+		{
+			pattern:           "http://*.example.com",
+			reqOrigin:         "http://example.com",
+			shouldAllowOrigin: false,
+		},
+		{
+			pattern:           "http://*.example.com",
+			reqOrigin:         "http://.example.com",
+			shouldAllowOrigin: false,
+		},
 		{
 			pattern:           "http://foo.[a-z]*.example.com",
 			reqOrigin:         "http://ccc.bbb.example.com",
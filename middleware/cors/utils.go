@@ -8,7 +8,8 @@ func matchScheme(domain, pattern string) bool {
 	return didx != -1 && pidx != -1 && domain[:didx] == pattern[:pidx]
 }
 
-// matchSubdomain compares authority with wildcard
+// matchSubdomain compares authority with wildcard,
+// a "*" label matches exactly one label of the domain
 func matchSubdomain(domain, pattern string) bool {
 	if !matchScheme(domain, pattern) {
 		return false
@@ -27,26 +28,23 @@ func matchSubdomain(domain, pattern string) bool {
 
 	domComp := strings.Split(domAuth, ".")
 	patComp := strings.Split(patAuth, ".")
-	for i := len(domComp)/2 - 1; i >= 0; i-- {
-		opp := len(domComp) - 1 - i
-		domComp[i], domComp[opp] = domComp[opp], domComp[i]
-	}
-	for i := len(patComp)/2 - 1; i >= 0; i-- {
-		opp := len(patComp) - 1 - i
-		patComp[i], patComp[opp] = patComp[opp], patComp[i]
+	if len(domComp) != len(patComp) {
+		return false
 	}
 
-	for i, v := range domComp {
-		if len(patComp) <= i {
-			return false
-		}
-		p := patComp[i]
+	wildcard := false
+	for i, p := range patComp {
 		if p == "*" {
-			return true
+			// the label must not be empty or contain the port
+			if domComp[i] == "" || strings.IndexByte(domComp[i], ':') != -1 {
+				return false
+			}
+			wildcard = true
+			continue
 		}
-		if p != v {
+		if !strings.EqualFold(p, domComp[i]) {
 			return false
 		}
 	}
-	return false
+	return wildcard
 }
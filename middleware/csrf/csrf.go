@@ -47,6 +47,13 @@ type Config struct {
 	//
 	// Optional. Default value "csrf".
 	ContextKey string
+
+	// SingleUseToken issues a fresh token in the cookie and context of the response
+	// after a successful validation. A validated token is invalidated in any case,
+	// without SingleUseToken a new token has to be requested with a GET request.
+	//
+	// Optional. Default: false
+	SingleUseToken bool
 }
 
 // ConfigDefault is the default config
@@ -195,7 +202,15 @@ func New(config ...Config) fiber.Handler {
 			delete(db.tokens, csrf)
 			db.Unlock()
 
-			return c.Next()
+			if !cfg.SingleUseToken {
+				return c.Next()
+			}
+
+			// Replace the consumed token with a new CSRF token
+			token = utils.UUID()
+			db.Lock()
+			db.tokens[token] = time.Now().Unix() + expiration
+			db.Unlock()
 		}
 
 		// Create new cookie to send new CSRF token
@@ -57,6 +57,86 @@ func Test_CSRF(t *testing.T) {
 	utils.AssertEqual(t, 200, ctx.Response.StatusCode())
 }
 
+// go test -run Test_CSRF_SingleUseToken
+func Test_CSRF_SingleUseToken(t *testing.T) {
+	app := fiber.New()
+
+	app.Use(New(Config{SingleUseToken: true}))
+
+	app.Post("/", func(c *fiber.Ctx) error {
+		return c.SendString(c.Locals("csrf").(string))
+	})
+
+	h := app.Handler()
+	ctx := &fasthttp.RequestCtx{}
+
+	// Generate CSRF token
+	ctx.Request.Header.SetMethod("GET")
+	h(ctx)
+	token := string(ctx.Response.Header.Peek(fiber.HeaderSetCookie))
+	token = strings.Split(strings.Split(token, ";")[0], "=")[1]
+
+	// Valid CSRF token issues a new token
+	ctx.Request.Reset()
+	ctx.Response.Reset()
+	ctx.Request.Header.SetMethod("POST")
+	ctx.Request.Header.Set("X-CSRF-Token", token)
+	ctx.Request.Header.SetCookie("_csrf", token)
+	h(ctx)
+	utils.AssertEqual(t, 200, ctx.Response.StatusCode())
+	newToken := string(ctx.Response.Header.Peek(fiber.HeaderSetCookie))
+	newToken = strings.Split(strings.Split(newToken, ";")[0], "=")[1]
+	utils.AssertEqual(t, true, newToken != token)
+	utils.AssertEqual(t, newToken, string(ctx.Response.Body()))
+
+	// Consumed CSRF token
+	ctx.Request.Reset()
+	ctx.Response.Reset()
+	ctx.Request.Header.SetMethod("POST")
+	ctx.Request.Header.Set("X-CSRF-Token", token)
+	h(ctx)
+	utils.AssertEqual(t, 403, ctx.Response.StatusCode())
+
+	// New CSRF token
+	ctx.Request.Reset()
+	ctx.Response.Reset()
+	ctx.Request.Header.SetMethod("POST")
+	ctx.Request.Header.Set("X-CSRF-Token", newToken)
+	h(ctx)
+	utils.AssertEqual(t, 200, ctx.Response.StatusCode())
+}
+
+// go test -run Test_CSRF_ConsumedToken
+func Test_CSRF_ConsumedToken(t *testing.T) {
+	app := fiber.New()
+
+	app.Use(New())
+
+	app.Post("/", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	h := app.Handler()
+	ctx := &fasthttp.RequestCtx{}
+
+	// Generate CSRF token
+	ctx.Request.Header.SetMethod("GET")
+	h(ctx)
+	token := string(ctx.Response.Header.Peek(fiber.HeaderSetCookie))
+	token = strings.Split(strings.Split(token, ";")[0], "=")[1]
+
+	// The token is valid once, no new token is issued
+	for _, status := range []int{200, 403} {
+		ctx.Request.Reset()
+		ctx.Response.Reset()
+		ctx.Request.Header.SetMethod("POST")
+		ctx.Request.Header.Set("X-CSRF-Token", token)
+		h(ctx)
+		utils.AssertEqual(t, status, ctx.Response.StatusCode())
+		utils.AssertEqual(t, "", string(ctx.Response.Header.Peek(fiber.HeaderSetCookie)))
+	}
+}
+
 // go test -run Test_CSRF_Next
 func Test_CSRF_Next(t *testing.T) {
 	app := fiber.New()
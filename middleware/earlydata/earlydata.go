@@ -0,0 +1,94 @@
+package earlydata
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// Config defines the config for middleware.
+type Config struct {
+	// Next defines a function to skip this middleware when returned true.
+	//
+	// Optional. Default: nil
+	Next func(c *fiber.Ctx) bool
+
+	// IsEarlyData reports whether the request was sent as TLS 1.3 early data (0-RTT),
+	// based on the header the TLS terminator sets
+	//
+	// Optional. Default: func(c *fiber.Ctx) bool {
+	//   return c.Get(fiber.HeaderEarlyData) == "1"
+	// }
+	IsEarlyData func(c *fiber.Ctx) bool
+
+	// AllowEarlyData reports whether an early data request may be processed,
+	// early data can be replayed so only safe methods are allowed by default
+	//
+	// Optional. Default: func(c *fiber.Ctx) bool {
+	//   return isSafeMethod(c.Method())
+	// }
+	AllowEarlyData func(c *fiber.Ctx) bool
+
+	// Error is returned when an early data request is not allowed
+	//
+	// Optional. Default: fiber.ErrTooEarly
+	Error error
+}
+
+// ConfigDefault is the default config
+var ConfigDefault = Config{
+	Next: nil,
+	IsEarlyData: func(c *fiber.Ctx) bool {
+		return c.Get(fiber.HeaderEarlyData) == "1"
+	},
+	AllowEarlyData: func(c *fiber.Ctx) bool {
+		return isSafeMethod(c.Method())
+	},
+	Error: fiber.ErrTooEarly,
+}
+
+// isSafeMethod reports whether the method is safe (RFC 7231 section 4.2.1)
+func isSafeMethod(method string) bool {
+	switch method {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions, fiber.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// New creates a new middleware handler
+func New(config ...Config) fiber.Handler {
+	// Set default config
+	cfg := ConfigDefault
+
+	// Override config if provided
+	if len(config) > 0 {
+		cfg = config[0]
+
+		// Set default values
+		if cfg.IsEarlyData == nil {
+			cfg.IsEarlyData = ConfigDefault.IsEarlyData
+		}
+		if cfg.AllowEarlyData == nil {
+			cfg.AllowEarlyData = ConfigDefault.AllowEarlyData
+		}
+		if cfg.Error == nil {
+			cfg.Error = ConfigDefault.Error
+		}
+	}
+
+	// Return new handler
+	return func(c *fiber.Ctx) error {
+		// Don't execute middleware if Next returns true
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		// Reject early data that is not allowed, the client retries after the handshake
+		// https://tools.ietf.org/html/rfc8470#section-5.2
+		if cfg.IsEarlyData(c) && !cfg.AllowEarlyData(c) {
+			return cfg.Error
+		}
+
+		// Continue stack
+		return c.Next()
+	}
+}
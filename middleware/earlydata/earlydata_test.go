@@ -0,0 +1,88 @@
+package earlydata
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// go test -run Test_EarlyData
+func Test_EarlyData(t *testing.T) {
+	app := fiber.New()
+
+	app.Use(New())
+
+	app.All("/", func(c *fiber.Ctx) error {
+		return c.SendString("Hello, World 👋!")
+	})
+
+	testCases := []struct {
+		method    string
+		earlyData string
+		status    int
+	}{
+		{fiber.MethodGet, "1", fiber.StatusOK},
+		{fiber.MethodHead, "1", fiber.StatusOK},
+		{fiber.MethodPost, "1", fiber.StatusTooEarly},
+		{fiber.MethodDelete, "1", fiber.StatusTooEarly},
+		{fiber.MethodPost, "", fiber.StatusOK},
+		{fiber.MethodPost, "0", fiber.StatusOK},
+	}
+	for _, tc := range testCases {
+		req := httptest.NewRequest(tc.method, "/", nil)
+		if tc.earlyData != "" {
+			req.Header.Set(fiber.HeaderEarlyData, tc.earlyData)
+		}
+		resp, err := app.Test(req)
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, tc.status, resp.StatusCode, tc.method+" "+tc.earlyData)
+	}
+}
+
+// go test -run Test_EarlyData_AllowEarlyData
+func Test_EarlyData_AllowEarlyData(t *testing.T) {
+	app := fiber.New()
+
+	app.Use(New(Config{
+		AllowEarlyData: func(c *fiber.Ctx) bool {
+			return c.Path() == "/idempotent"
+		},
+	}))
+
+	app.Post("/*", func(c *fiber.Ctx) error {
+		return c.SendString("Hello, World 👋!")
+	})
+
+	req := httptest.NewRequest(fiber.MethodPost, "/idempotent", nil)
+	req.Header.Set(fiber.HeaderEarlyData, "1")
+	resp, err := app.Test(req)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, fiber.StatusOK, resp.StatusCode)
+
+	req = httptest.NewRequest(fiber.MethodPost, "/", nil)
+	req.Header.Set(fiber.HeaderEarlyData, "1")
+	resp, err = app.Test(req)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, fiber.StatusTooEarly, resp.StatusCode)
+}
+
+// go test -run Test_EarlyData_Next
+func Test_EarlyData_Next(t *testing.T) {
+	app := fiber.New()
+	app.Use(New(Config{
+		Next: func(_ *fiber.Ctx) bool {
+			return true
+		},
+	}))
+	app.Post("/", func(c *fiber.Ctx) error {
+		return c.SendString("Hello, World 👋!")
+	})
+
+	req := httptest.NewRequest(fiber.MethodPost, "/", nil)
+	req.Header.Set(fiber.HeaderEarlyData, "1")
+	resp, err := app.Test(req)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, fiber.StatusOK, resp.StatusCode)
+}
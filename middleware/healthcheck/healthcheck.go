@@ -0,0 +1,116 @@
+package healthcheck
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// Config defines the config for middleware.
+type Config struct {
+	// Next defines a function to skip this middleware when returned true.
+	//
+	// Optional. Default: nil
+	Next func(c *fiber.Ctx) bool
+
+	// LivenessProbe reports whether the application is running,
+	// it responds with 200 OK when true and 503 Service Unavailable otherwise
+	//
+	// Optional. Default: func(c *fiber.Ctx) bool { return true }
+	LivenessProbe func(c *fiber.Ctx) bool
+
+	// LivenessEndpoint is the path of the liveness probe
+	//
+	// Optional. Default: "/livez"
+	LivenessEndpoint string
+
+	// ReadinessProbe reports whether the application is ready to serve requests,
+	// it responds with 200 OK when true and 503 Service Unavailable otherwise
+	//
+	// Optional. Default: func(c *fiber.Ctx) bool { return true }
+	ReadinessProbe func(c *fiber.Ctx) bool
+
+	// ReadinessEndpoint is the path of the readiness probe
+	//
+	// Optional. Default: "/readyz"
+	ReadinessEndpoint string
+
+	// ReportDraining makes the readiness probe respond with 503 Service Unavailable
+	// while the app is shutting down and drains the requests in flight
+	//
+	// Optional. Default: false
+	ReportDraining bool
+}
+
+// defaultProbe is always healthy
+func defaultProbe(c *fiber.Ctx) bool {
+	return true
+}
+
+// ConfigDefault is the default config
+var ConfigDefault = Config{
+	Next:              nil,
+	LivenessProbe:     defaultProbe,
+	LivenessEndpoint:  "/livez",
+	ReadinessProbe:    defaultProbe,
+	ReadinessEndpoint: "/readyz",
+	ReportDraining:    false,
+}
+
+// New creates a new middleware handler
+func New(config ...Config) fiber.Handler {
+	// Set default config
+	cfg := ConfigDefault
+
+	// Override config if provided
+	if len(config) > 0 {
+		cfg = config[0]
+
+		// Set default values
+		if cfg.Next == nil {
+			cfg.Next = ConfigDefault.Next
+		}
+		if cfg.LivenessProbe == nil {
+			cfg.LivenessProbe = ConfigDefault.LivenessProbe
+		}
+		if cfg.LivenessEndpoint == "" {
+			cfg.LivenessEndpoint = ConfigDefault.LivenessEndpoint
+		}
+		if cfg.ReadinessProbe == nil {
+			cfg.ReadinessProbe = ConfigDefault.ReadinessProbe
+		}
+		if cfg.ReadinessEndpoint == "" {
+			cfg.ReadinessEndpoint = ConfigDefault.ReadinessEndpoint
+		}
+	}
+
+	// Return new handler
+	return func(c *fiber.Ctx) error {
+		// Don't execute middleware if Next returns true
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		// Only respond to GET and HEAD requests
+		if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+			return c.Next()
+		}
+
+		// Select the probe of the requested endpoint
+		var probe func(c *fiber.Ctx) bool
+		switch c.Path() {
+		case cfg.LivenessEndpoint:
+			probe = cfg.LivenessProbe
+		case cfg.ReadinessEndpoint:
+			if cfg.ReportDraining && c.App().Draining() {
+				return c.SendStatus(fiber.StatusServiceUnavailable)
+			}
+			probe = cfg.ReadinessProbe
+		default:
+			return c.Next()
+		}
+
+		if probe(c) {
+			return c.SendStatus(fiber.StatusOK)
+		}
+		return c.SendStatus(fiber.StatusServiceUnavailable)
+	}
+}
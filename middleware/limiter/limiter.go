@@ -1,16 +1,19 @@
 package limiter
 
 import (
+	"math/rand"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/internal/methods"
 )
 
 //go:generate msgp -unexported
-//msgp:ignore Config
+//msgp:ignore Config Tier
 
 // Config defines the config for middleware.
 type Config struct {
@@ -19,16 +22,55 @@ type Config struct {
 	// Optional. Default: nil
 	Next func(c *fiber.Ctx) bool
 
+	// Methods restricts the middleware to the listed methods, e.g. only GET
+	// requests with []string{fiber.MethodGet}. Other methods skip it like Next.
+	//
+	// Optional. Default: nil (all methods)
+	Methods []string
+
 	// Max number of recent connections during `Duration` seconds before sending a 429 response
 	//
 	// Default: 5
 	Max int
 
+	// MaxCalculator returns the max number of connections of a request,
+	// e.g. a higher limit for a premium plan. Max is used if it returns 0 or less.
+	// MaxCalculator is ignored if tiers are provided.
+	//
+	// Optional. Default: nil
+	MaxCalculator func(*fiber.Ctx) int
+
 	// Duration is the time on how long to keep records of requests in memory
 	//
 	// Default: 1 * time.Minute
 	Duration time.Duration
 
+	// Tiers defines multiple limits a request has to satisfy, e.g. 100 requests
+	// per minute and 1000 requests per hour. Every tier is tracked separately,
+	// Max and Duration are ignored if tiers are provided.
+	//
+	// Optional. Default: nil
+	Tiers []Tier
+
+	// Jitter adds a random duration up to Jitter to the reset of every window,
+	// so the windows of many clients don't reset at the same time.
+	// It's rounded up to whole seconds like the reset.
+	// The X-RateLimit-Reset header reports the reset with the jitter.
+	//
+	// Optional. Default: 0
+	Jitter time.Duration
+
+	// MaxDelay enables the leaky bucket mode: after a burst of Max requests,
+	// requests are delayed to the rate of Max per Duration instead of rejected.
+	// Requests that would be delayed longer than MaxDelay call LimitReached.
+	// The delay ends early without a response if the user context of the request is canceled,
+	// e.g. when the client closes the connection unless fiber.Config.DisableClientDisconnectCancel
+	// is set, and the request gives its place in the bucket back.
+	// Tiers, MaxCalculator, Store and Jitter are not supported in this mode and panic.
+	//
+	// Optional. Default: 0 (disabled)
+	MaxDelay time.Duration
+
 	// Key allows you to generate custom keys, by default c.IP() is used
 	//
 	// Default: func(c *fiber.Ctx) string {
@@ -43,14 +85,24 @@ type Config struct {
 	// }
 	LimitReached fiber.Handler
 
+	// DraftHeaders emits the RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset
+	// and RateLimit-Policy headers of the IETF draft instead of X-RateLimit-*.
+	// https://tools.ietf.org/html/draft-ietf-httpapi-ratelimit-headers
+	//
+	// Optional. Default: false
+	DraftHeaders bool
+
+	// WindowHeader adds the X-RateLimit-Window header with the duration of
+	// the window in seconds, so clients can throttle themselves.
+	// It reports the same tier as the limit headers.
+	//
+	// Optional. Default: false
+	WindowHeader bool
+
 	// Store is used to store the state of the middleware
 	//
 	// Default: an in memory store for this process only
 	Store fiber.Storage
-
-	// Internally used - if true, the simpler method of two maps is used in order to keep
-	// execution time down.
-	usingCustomStore bool
 }
 
 // ConfigDefault is the default config
@@ -66,6 +118,15 @@ var ConfigDefault = Config{
 	},
 }
 
+// Tier defines a limit of Max requests during Duration
+type Tier struct {
+	// Max number of recent connections during `Duration` seconds before sending a 429 response
+	Max int
+
+	// Duration is the time on how long to keep records of requests
+	Duration time.Duration
+}
+
 // trackedSession is the type used for session tracking
 type trackedSession struct {
 	Hits      int
@@ -77,6 +138,15 @@ const (
 	xRateLimitLimit     = "X-RateLimit-Limit"
 	xRateLimitRemaining = "X-RateLimit-Remaining"
 	xRateLimitReset     = "X-RateLimit-Reset"
+	xRateLimitWindow    = "X-RateLimit-Window"
+)
+
+// RateLimit-* headers of the IETF draft
+const (
+	rateLimitLimit     = "RateLimit-Limit"
+	rateLimitRemaining = "RateLimit-Remaining"
+	rateLimitReset     = "RateLimit-Reset"
+	rateLimitPolicy    = "RateLimit-Policy"
 )
 
 // New creates a new middleware handler
@@ -104,16 +174,65 @@ func New(config ...Config) fiber.Handler {
 		if cfg.LimitReached == nil {
 			cfg.LimitReached = ConfigDefault.LimitReached
 		}
-		if cfg.Store != nil {
-			cfg.usingCustomStore = true
+	}
+
+	// Skip the methods not listed
+	cfg.Next = methods.Next(cfg.Next, cfg.Methods)
+
+	if cfg.MaxDelay > 0 {
+		if cfg.Store != nil || len(cfg.Tiers) > 0 || cfg.MaxCalculator != nil || cfg.Jitter > 0 {
+			panic("limiter: MaxDelay can't be used with Store, Tiers, MaxCalculator or Jitter")
+		}
+		return newLeakyBucket(cfg)
+	}
+
+	// Limiter settings, a single tier is created from Max and Duration
+	tiers := append([]Tier(nil), cfg.Tiers...)
+	if len(tiers) == 0 {
+		tiers = []Tier{{Max: cfg.Max, Duration: cfg.Duration}}
+	}
+	// Jitter in seconds like the timestamps, rounded up so a jitter under a second has an effect
+	var jitter = int64((cfg.Jitter + time.Second - 1) / time.Second)
+
+	var expiration time.Duration
+	var maxs = make([]string, len(tiers))
+	var suffixes = make([]string, len(tiers))
+	var policies = make([]string, len(tiers))
+	var windows = make([]string, len(tiers))
+	for i := range tiers {
+		if tiers[i].Max <= 0 {
+			tiers[i].Max = ConfigDefault.Max
+		}
+		if int(tiers[i].Duration.Seconds()) <= 0 {
+			tiers[i].Duration = ConfigDefault.Duration
 		}
+		// All tiers expire with the longest tier, so Reset finds every tier
+		if window := tiers[i].Duration + time.Duration(jitter)*time.Second; window > expiration {
+			expiration = window
+		}
+		maxs[i] = strconv.Itoa(tiers[i].Max)
+		windows[i] = strconv.Itoa(int(tiers[i].Duration.Seconds()))
+		policies[i] = maxs[i] + ";w=" + windows[i]
+		// Every tier is stored with its own key
+		if len(tiers) > 1 {
+			suffixes[i] = "_" + strconv.Itoa(i)
+		}
+	}
+	// The policy lists the quota of every tier, e.g. "100;w=60, 1000;w=3600"
+	var policy = strings.Join(policies, ", ")
+
+	// Header names
+	var headerLimit, headerRemaining, headerReset = xRateLimitLimit, xRateLimitRemaining, xRateLimitReset
+	if cfg.DraftHeaders {
+		headerLimit, headerRemaining, headerReset = rateLimitLimit, rateLimitRemaining, rateLimitReset
+	}
+
+	// Keep the state in memory without Store, the memory is freed once a minute
+	if cfg.Store == nil {
+		cfg.Store = newDefaultStore(time.Minute)
 	}
 
-	// Limiter settings
-	var max = strconv.Itoa(cfg.Max)
-	var sessions = make(map[string]trackedSession)
 	var timestamp = uint64(time.Now().Unix())
-	var duration = uint64(cfg.Duration.Seconds())
 
 	// mutex for parallel read and write access
 	mux := &sync.Mutex{}
@@ -126,6 +245,29 @@ func New(config ...Config) fiber.Handler {
 		}
 	}()
 
+	// Load session from store
+	load := func(key string) (session trackedSession, err error) {
+		fromStore, err := cfg.Store.Get(key)
+		if err != nil || len(fromStore) == 0 {
+			// Assume an empty value means item not found.
+			return session, err
+		}
+		// Decode bytes using msgp
+		_, err = session.UnmarshalMsg(fromStore)
+		return session, err
+	}
+
+	// Save session to store
+	save := func(key string, session trackedSession, expiration time.Duration) error {
+		// Convert session struct into bytes
+		data, err := session.MarshalMsg(nil)
+		if err != nil {
+			return err
+		}
+		// Store those bytes
+		return cfg.Store.Set(key, data, expiration)
+	}
+
 	// Return new handler
 	return func(c *fiber.Ctx) error {
 		// Don't execute middleware if Next returns true
@@ -136,89 +278,219 @@ func New(config ...Config) fiber.Handler {
 		// Get key (default is the remote IP)
 		key := cfg.Key(c)
 
+		// Get the max of the request, overriding the single tier
+		var max int
+		if cfg.MaxCalculator != nil && len(cfg.Tiers) == 0 {
+			max = cfg.MaxCalculator(c)
+		}
+
 		// Lock mux (prevents values changing between retrieval and reassignment, which can and does
 		// break things)
 		mux.Lock()
 
-		var session trackedSession
+		ts := atomic.LoadUint64(&timestamp)
 
-		if cfg.usingCustomStore {
-			// Load data from store
-			fromStore, err := cfg.Store.Get(key)
+		// Values of the most restrictive tier
+		var tier, remaining int
+		var resetTime, retryAfter uint64
+		var exceeded bool
+
+		for i := range tiers {
+			session, err := load(key + suffixes[i])
 			if err != nil {
+				mux.Unlock()
+				return err
+			}
+
+			// Set unix timestamp if not exist
+			duration := uint64(tiers[i].Duration.Seconds())
+			if jitter > 0 && (session.ResetTime == 0 || ts >= session.ResetTime) {
+				duration += uint64(rand.Int63n(jitter + 1))
+			}
+			if session.ResetTime == 0 {
+				session.ResetTime = ts + duration
+			} else if ts >= session.ResetTime {
+				session.Hits = 0
+				session.ResetTime = ts + duration
+			}
+
+			// Increment key hits
+			session.Hits++
+
+			if err = save(key+suffixes[i], session, expiration); err != nil {
+				mux.Unlock()
 				return err
 			}
 
-			if len(fromStore) == 0 {
-				// Assume this means item not found.
-				session = trackedSession{}
-			} else {
-				// Decode bytes using msgp
-				_, err := session.UnmarshalMsg(fromStore)
-				if err != nil {
-					return err
+			// Calculate when it resets in seconds and how many hits we have left
+			tierReset := session.ResetTime - ts
+			tierMax := tiers[i].Max
+			if max > 0 {
+				tierMax = max
+			}
+			tierRemaining := tierMax - session.Hits
+
+			// A request is rejected until all exceeded tiers are reset
+			if tierRemaining < 0 {
+				exceeded = true
+				if tierReset > retryAfter {
+					retryAfter = tierReset
 				}
 			}
-		} else {
-			// Load data from in-memory map
-			session = sessions[key]
+			if i == 0 || tierRemaining < remaining || (tierRemaining == remaining && tierReset > resetTime) {
+				tier, remaining, resetTime = i, tierRemaining, tierReset
+			}
 		}
 
-		// Set unix timestamp if not exist
-		ts := atomic.LoadUint64(&timestamp)
-		if session.ResetTime == 0 {
-			session.ResetTime = ts + duration
-		} else if ts >= session.ResetTime {
-			session.Hits = 0
-			session.ResetTime = ts + duration
+		mux.Unlock()
+
+		// Check if hits exceed the max of any tier
+		if exceeded {
+			// Return response with Retry-After header
+			// https://tools.ietf.org/html/rfc6584
+			c.Set(fiber.HeaderRetryAfter, strconv.FormatUint(retryAfter, 10))
+
+			// Call LimitReached handler
+			return cfg.LimitReached(c)
 		}
 
-		// Increment key hits
-		session.Hits++
+		// We can continue, update RateLimit headers
+		limit, limitPolicy := maxs[tier], policy
+		if max > 0 {
+			limit = strconv.Itoa(max)
+			limitPolicy = limit + ";w=" + windows[0]
+		}
+		c.Set(headerLimit, limit)
+		c.Set(headerRemaining, strconv.Itoa(remaining))
+		c.Set(headerReset, strconv.FormatUint(resetTime, 10))
+		if cfg.DraftHeaders {
+			c.Set(rateLimitPolicy, limitPolicy)
+		}
+		if cfg.WindowHeader {
+			c.Set(xRateLimitWindow, windows[tier])
+		}
 
-		if cfg.usingCustomStore {
-			// Convert session struct into bytes
+		// Continue stack
+		return c.Next()
+	}
+}
 
-			data, err := session.MarshalMsg(nil)
+// Reset deletes the state of a key from the Store of the limiter, e.g. to
+// unblock a client after a false positive. A nil store resets the key in the
+// in-memory stores of all limiters without Store, except the MaxDelay mode.
+func Reset(store fiber.Storage, key string) error {
+	if store != nil {
+		return resetKey(store, key)
+	}
+	defaultStores.Lock()
+	stores := append([]*defaultStore(nil), defaultStores.stores...)
+	defaultStores.Unlock()
+	for _, s := range stores {
+		if err := resetKey(s, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-			if err != nil {
-				return err
-			}
+// resetKey deletes the key and the keys of its tiers from the store
+func resetKey(store fiber.Storage, key string) error {
+	if err := store.Delete(key); err != nil {
+		return err
+	}
+	// Every tier of multiple tiers is stored with its own key
+	for i := 0; ; i++ {
+		tierKey := key + "_" + strconv.Itoa(i)
+		val, err := store.Get(tierKey)
+		if err != nil {
+			return err
+		}
+		if len(val) == 0 {
+			return nil
+		}
+		if err = store.Delete(tierKey); err != nil {
+			return err
+		}
+	}
+}
 
-			// Store those bytes
-			err = cfg.Store.Set(key, data, cfg.Duration)
-			if err != nil {
-				return err
+// newLeakyBucket creates a handler that delays requests to the rate of
+// Max per Duration after a burst of Max requests
+func newLeakyBucket(cfg Config) fiber.Handler {
+	// Every request takes an interval of the bucket
+	interval := cfg.Duration / time.Duration(cfg.Max)
+
+	// Theoretical arrival time of the next request by key
+	var arrivals = make(map[string]time.Time)
+
+	// mutex for parallel read and write access
+	mux := &sync.Mutex{}
+
+	// Delete the keys of drained buckets every Duration
+	go func() {
+		for {
+			time.Sleep(cfg.Duration)
+			now := time.Now()
+			mux.Lock()
+			for key, arrival := range arrivals {
+				if !arrival.After(now) {
+					delete(arrivals, key)
+				}
 			}
-		} else {
-			sessions[key] = session
+			mux.Unlock()
 		}
+	}()
 
-		// Get current hits
-		hitCount := session.Hits
-
-		// Calculate when it resets in seconds
-		resetTime := session.ResetTime - ts
+	// Return new handler
+	return func(c *fiber.Ctx) error {
+		// Don't execute middleware if Next returns true
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
 
-		// Set how many hits we have left
-		remaining := cfg.Max - hitCount
+		// Get key (default is the remote IP)
+		key := cfg.Key(c)
 
-		mux.Unlock()
+		mux.Lock()
+		now := time.Now()
+		arrival := arrivals[key]
+		if arrival.Before(now) {
+			arrival = now
+		}
+		arrival = arrival.Add(interval)
+		// The bucket holds a burst of Max requests without delay
+		delay := arrival.Sub(now) - cfg.Duration
+		if delay > cfg.MaxDelay {
+			mux.Unlock()
 
-		// Check if hits exceed the cfg.Max
-		if remaining < 0 {
 			// Return response with Retry-After header
 			// https://tools.ietf.org/html/rfc6584
-			c.Set(fiber.HeaderRetryAfter, strconv.FormatUint(resetTime, 10))
+			retryAfter := (delay - cfg.MaxDelay + time.Second - 1) / time.Second
+			c.Set(fiber.HeaderRetryAfter, strconv.FormatInt(int64(retryAfter), 10))
 
 			// Call LimitReached handler
 			return cfg.LimitReached(c)
 		}
+		arrivals[key] = arrival
+		mux.Unlock()
 
-		// We can continue, update RateLimit headers
-		c.Set(xRateLimitLimit, max)
-		c.Set(xRateLimitRemaining, strconv.Itoa(remaining))
-		c.Set(xRateLimitReset, strconv.FormatUint(resetTime, 10))
+		// Wait for the turn of the request
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-c.UserContext().Done():
+				// The request is abandoned, there is no one to respond to
+				timer.Stop()
+				// Release the interval of the request for the next requests
+				mux.Lock()
+				if arrival, ok := arrivals[key]; ok {
+					arrivals[key] = arrival.Add(-interval)
+				}
+				mux.Unlock()
+				return nil
+			}
+		}
 
 		// Continue stack
 		return c.Next()
@@ -4,6 +4,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
@@ -149,6 +150,34 @@ func Benchmark_Limiter_Custom_Store(b *testing.B) {
 	}
 }
 
+// go test -run Test_Limiter_Methods
+func Test_Limiter_Methods(t *testing.T) {
+	app := fiber.New()
+	app.Use(New(Config{
+		Max:      1,
+		Duration: time.Minute,
+		Methods:  []string{fiber.MethodGet},
+	}))
+
+	app.All("/", func(c *fiber.Ctx) error {
+		return c.SendString("Hello tester!")
+	})
+
+	for i, want := range []int{fiber.StatusOK, fiber.StatusTooManyRequests} {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, want, resp.StatusCode, strconv.Itoa(i))
+	}
+
+	// POST requests are not limited
+	for i := 0; i < 3; i++ {
+		resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/", nil))
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, fiber.StatusOK, resp.StatusCode)
+		utils.AssertEqual(t, "", resp.Header.Get(xRateLimitLimit))
+	}
+}
+
 // go test -run Test_Limiter_Next
 func Test_Limiter_Next(t *testing.T) {
 	app := fiber.New()
@@ -189,6 +218,451 @@ func Test_Limiter_Headers(t *testing.T) {
 	}
 }
 
+// go test -run Test_Limiter_DraftHeaders
+func Test_Limiter_DraftHeaders(t *testing.T) {
+	app := fiber.New()
+
+	app.Use(New(Config{
+		Tiers: []Tier{
+			{Max: 10, Duration: time.Minute},
+			{Max: 100, Duration: time.Hour},
+		},
+		DraftHeaders: true,
+	}))
+
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString("Hello tester!")
+	})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, fiber.StatusOK, resp.StatusCode)
+	utils.AssertEqual(t, "10", resp.Header.Get("RateLimit-Limit"))
+	utils.AssertEqual(t, "9", resp.Header.Get("RateLimit-Remaining"))
+	utils.AssertEqual(t, "60", resp.Header.Get("RateLimit-Reset"))
+	utils.AssertEqual(t, "10;w=60, 100;w=3600", resp.Header.Get("RateLimit-Policy"))
+	utils.AssertEqual(t, "", resp.Header.Get("X-RateLimit-Limit"))
+	utils.AssertEqual(t, "", resp.Header.Get("X-RateLimit-Remaining"))
+	utils.AssertEqual(t, "", resp.Header.Get("X-RateLimit-Reset"))
+
+	// the legacy header set is emitted by default
+	app = fiber.New()
+	app.Use(New())
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "5", resp.Header.Get("X-RateLimit-Limit"))
+	utils.AssertEqual(t, "4", resp.Header.Get("X-RateLimit-Remaining"))
+	utils.AssertEqual(t, "60", resp.Header.Get("X-RateLimit-Reset"))
+	utils.AssertEqual(t, "", resp.Header.Get("RateLimit-Limit"))
+	utils.AssertEqual(t, "", resp.Header.Get("RateLimit-Policy"))
+}
+
+// go test -run Test_Limiter_WindowHeader
+func Test_Limiter_WindowHeader(t *testing.T) {
+	handler := func(c *fiber.Ctx) error {
+		return c.SendString("Hello tester!")
+	}
+
+	app := fiber.New()
+	app.Use(New(Config{
+		Duration:     90 * time.Second,
+		WindowHeader: true,
+	}))
+	app.Get("/", handler)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, fiber.StatusOK, resp.StatusCode)
+	utils.AssertEqual(t, "90", resp.Header.Get(xRateLimitWindow))
+
+	// the window of the reported tier is sent
+	app = fiber.New()
+	app.Use(New(Config{
+		Tiers: []Tier{
+			{Max: 10, Duration: time.Minute},
+			{Max: 3, Duration: time.Hour},
+		},
+		WindowHeader: true,
+	}))
+	app.Get("/", handler)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "3", resp.Header.Get(xRateLimitLimit))
+	utils.AssertEqual(t, "3600", resp.Header.Get(xRateLimitWindow))
+
+	// the header is not sent by default
+	app = fiber.New()
+	app.Use(New())
+	app.Get("/", handler)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "", resp.Header.Get(xRateLimitWindow))
+}
+
+// go test -run Test_Limiter_MaxCalculator
+func Test_Limiter_MaxCalculator(t *testing.T) {
+	app := fiber.New()
+
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("plan", c.Get("X-Plan"))
+		return c.Next()
+	})
+	app.Use(New(Config{
+		Max: 2,
+		MaxCalculator: func(c *fiber.Ctx) int {
+			if c.Locals("plan") == "premium" {
+				return 5
+			}
+			return 0
+		},
+		Key: func(c *fiber.Ctx) string {
+			return c.Get("X-Plan")
+		},
+		DraftHeaders: true,
+	}))
+
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString("Hello tester!")
+	})
+
+	request := func(plan string) *http.Response {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Plan", plan)
+		resp, err := app.Test(req)
+		utils.AssertEqual(t, nil, err)
+		return resp
+	}
+
+	// free requests fall back to Max
+	for i := 1; i >= 0; i-- {
+		resp := request("free")
+		utils.AssertEqual(t, fiber.StatusOK, resp.StatusCode)
+		utils.AssertEqual(t, "2", resp.Header.Get("RateLimit-Limit"))
+		utils.AssertEqual(t, strconv.Itoa(i), resp.Header.Get("RateLimit-Remaining"))
+		utils.AssertEqual(t, "2;w=60", resp.Header.Get("RateLimit-Policy"))
+	}
+	utils.AssertEqual(t, fiber.StatusTooManyRequests, request("free").StatusCode)
+
+	// premium requests get the calculated max
+	for i := 4; i >= 0; i-- {
+		resp := request("premium")
+		utils.AssertEqual(t, fiber.StatusOK, resp.StatusCode)
+		utils.AssertEqual(t, "5", resp.Header.Get("RateLimit-Limit"))
+		utils.AssertEqual(t, strconv.Itoa(i), resp.Header.Get("RateLimit-Remaining"))
+		utils.AssertEqual(t, "5;w=60", resp.Header.Get("RateLimit-Policy"))
+	}
+	utils.AssertEqual(t, fiber.StatusTooManyRequests, request("premium").StatusCode)
+}
+
+// go test -run Test_Limiter_Tiers
+func Test_Limiter_Tiers(t *testing.T) {
+	store := testStore{stmap: map[string][]byte{}, mutex: new(sync.Mutex)}
+
+	app := fiber.New()
+	app.Use(New(Config{
+		Tiers: []Tier{
+			{Max: 10, Duration: time.Minute},
+			{Max: 3, Duration: time.Hour},
+		},
+		Store: store,
+	}))
+
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString("Hello tester!")
+	})
+
+	// the burst stays within the per-minute tier, the per-hour tier is reported
+	for i := 2; i >= 0; i-- {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, fiber.StatusOK, resp.StatusCode)
+		utils.AssertEqual(t, "3", resp.Header.Get("X-RateLimit-Limit"))
+		utils.AssertEqual(t, strconv.Itoa(i), resp.Header.Get("X-RateLimit-Remaining"))
+	}
+
+	// the per-hour tier is exceeded and reports its reset
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, fiber.StatusTooManyRequests, resp.StatusCode)
+	retryAfter, err := strconv.Atoi(resp.Header.Get(fiber.HeaderRetryAfter))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, retryAfter > 60 && retryAfter <= 3600)
+
+	// every tier is stored with its own key
+	utils.AssertEqual(t, 2, len(store.stmap))
+	utils.AssertEqual(t, true, len(store.stmap["0.0.0.0_0"]) > 0)
+	utils.AssertEqual(t, true, len(store.stmap["0.0.0.0_1"]) > 0)
+}
+
+// go test -run Test_Limiter_MaxDelay
+func Test_Limiter_MaxDelay(t *testing.T) {
+	app := fiber.New()
+
+	// A burst of 4 requests, then one request every 250ms
+	app.Use(New(Config{
+		Max:      4,
+		Duration: time.Second,
+		MaxDelay: 300 * time.Millisecond,
+	}))
+
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString("Hello tester!")
+	})
+
+	request := func() (int, time.Duration) {
+		start := time.Now()
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil), -1)
+		utils.AssertEqual(t, nil, err)
+		return resp.StatusCode, time.Since(start)
+	}
+
+	for i := 0; i < 4; i++ {
+		status, elapsed := request()
+		utils.AssertEqual(t, fiber.StatusOK, status)
+		utils.AssertEqual(t, true, elapsed < 100*time.Millisecond, elapsed.String())
+	}
+
+	// the request over the rate is delayed but served
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		status, elapsed := request()
+		utils.AssertEqual(t, fiber.StatusOK, status)
+		utils.AssertEqual(t, true, elapsed >= 200*time.Millisecond && elapsed < 400*time.Millisecond, elapsed.String())
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	// the next request would be delayed by 450ms
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, fiber.StatusTooManyRequests, resp.StatusCode)
+	utils.AssertEqual(t, "1", resp.Header.Get(fiber.HeaderRetryAfter))
+	wg.Wait()
+
+	// the bucket leaks over time
+	time.Sleep(400 * time.Millisecond)
+	status, elapsed := request()
+	utils.AssertEqual(t, fiber.StatusOK, status)
+	utils.AssertEqual(t, true, elapsed < 100*time.Millisecond, elapsed.String())
+}
+
+// go test -run Test_Limiter_MaxDelay_Canceled
+func Test_Limiter_MaxDelay_Canceled(t *testing.T) {
+	app := fiber.New()
+
+	app.Use(func(c *fiber.Ctx) error {
+		c.SetDeadline(time.Now().Add(50 * time.Millisecond))
+		return c.Next()
+	})
+	app.Use(New(Config{
+		Max:      1,
+		Duration: time.Second,
+		MaxDelay: time.Second,
+	}))
+
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString("Hello tester!")
+	})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, fiber.StatusOK, resp.StatusCode)
+
+	// the delay ends with the deadline of the request
+	start := time.Now()
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/", nil), -1)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, fiber.StatusOK, resp.StatusCode)
+	utils.AssertEqual(t, true, time.Since(start) < 500*time.Millisecond)
+	body, err := ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "", string(body))
+
+	// the canceled request released its place, so the next one is delayed instead of rejected
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/", nil), -1)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, fiber.StatusOK, resp.StatusCode)
+}
+
+// go test -run Test_Limiter_MaxDelay_Conflicts
+func Test_Limiter_MaxDelay_Conflicts(t *testing.T) {
+	t.Parallel()
+	configs := []Config{
+		{MaxDelay: time.Second, Store: testStore{stmap: map[string][]byte{}, mutex: new(sync.Mutex)}},
+		{MaxDelay: time.Second, Tiers: []Tier{{Max: 1, Duration: time.Second}}},
+		{MaxDelay: time.Second, MaxCalculator: func(*fiber.Ctx) int { return 1 }},
+		{MaxDelay: time.Second, Jitter: time.Second},
+	}
+	for _, cfg := range configs {
+		func() {
+			defer func() {
+				utils.AssertEqual(t, "limiter: MaxDelay can't be used with Store, Tiers, MaxCalculator or Jitter", recover())
+			}()
+			New(cfg)
+		}()
+	}
+}
+
+// go test -run Test_Limiter_Jitter
+func Test_Limiter_Jitter(t *testing.T) {
+	t.Parallel()
+	app := fiber.New()
+	app.Use(New(Config{
+		Max:      1,
+		Duration: time.Minute,
+		Jitter:   30 * time.Second,
+		Key: func(c *fiber.Ctx) string {
+			return utils.ImmutableString(c.Get("X-Client"))
+		},
+	}))
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString("Hello tester!")
+	})
+
+	resets := make(map[int]bool)
+	for i := 0; i < 50; i++ {
+		client := strconv.Itoa(i)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Client", client)
+		resp, err := app.Test(req)
+		utils.AssertEqual(t, nil, err)
+		reset, err := strconv.Atoi(resp.Header.Get(xRateLimitReset))
+		utils.AssertEqual(t, nil, err)
+		// with the timestamp updated in between the reset can be a second shorter
+		utils.AssertEqual(t, true, reset >= 59 && reset <= 90, strconv.Itoa(reset))
+		resets[reset] = true
+
+		// the reset of the limited request is the same
+		resp, err = app.Test(req)
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, fiber.StatusTooManyRequests, resp.StatusCode)
+		retryAfter, err := strconv.Atoi(resp.Header.Get(fiber.HeaderRetryAfter))
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, true, retryAfter >= reset-1 && retryAfter <= reset, client)
+	}
+	// the resets are distributed
+	utils.AssertEqual(t, true, len(resets) > 5, strconv.Itoa(len(resets)))
+
+	// a jitter under a second is rounded up to a second
+	app = fiber.New()
+	app.Use(New(Config{
+		Max:      1,
+		Duration: time.Minute,
+		Jitter:   100 * time.Millisecond,
+		Key: func(c *fiber.Ctx) string {
+			return utils.ImmutableString(c.Get("X-Client"))
+		},
+	}))
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString("Hello tester!")
+	})
+	resets = make(map[int]bool)
+	for i := 0; i < 50; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Client", strconv.Itoa(i))
+		resp, err := app.Test(req)
+		utils.AssertEqual(t, nil, err)
+		reset, err := strconv.Atoi(resp.Header.Get(xRateLimitReset))
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, true, reset >= 59 && reset <= 61, strconv.Itoa(reset))
+		resets[reset] = true
+	}
+	utils.AssertEqual(t, true, resets[61])
+}
+
+// go test -run Test_Limiter_Reset
+func Test_Limiter_Reset(t *testing.T) {
+	t.Parallel()
+	configs := []Config{
+		{Max: 1, Duration: time.Minute},
+		{Tiers: []Tier{{Max: 1, Duration: time.Minute}, {Max: 10, Duration: time.Hour}}},
+	}
+	for i, cfg := range configs {
+		store := testStore{stmap: map[string][]byte{}, mutex: new(sync.Mutex)}
+		cfg.Store = store
+		cfg.Key = func(c *fiber.Ctx) string {
+			return "client"
+		}
+
+		app := fiber.New()
+		app.Use(New(cfg))
+		app.Get("/", func(c *fiber.Ctx) error {
+			return c.SendString("Hello tester!")
+		})
+
+		for _, want := range []int{fiber.StatusOK, fiber.StatusTooManyRequests} {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+			utils.AssertEqual(t, nil, err)
+			utils.AssertEqual(t, want, resp.StatusCode, strconv.Itoa(i))
+		}
+
+		utils.AssertEqual(t, nil, Reset(store, "client"))
+		utils.AssertEqual(t, 0, len(store.stmap))
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, fiber.StatusOK, resp.StatusCode, strconv.Itoa(i))
+	}
+}
+
+// go test -run Test_Limiter_Reset_DefaultStore
+func Test_Limiter_Reset_DefaultStore(t *testing.T) {
+	t.Parallel()
+	app := fiber.New()
+	app.Use(New(Config{
+		Max:      1,
+		Duration: time.Minute,
+		Key: func(c *fiber.Ctx) string {
+			return "default-client"
+		},
+	}))
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString("Hello tester!")
+	})
+
+	for _, want := range []int{fiber.StatusOK, fiber.StatusTooManyRequests} {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, want, resp.StatusCode)
+	}
+
+	// the in-memory stores are reset without a store
+	utils.AssertEqual(t, nil, Reset(nil, "default-client"))
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, fiber.StatusOK, resp.StatusCode)
+}
+
+// go test -run Test_Limiter_DefaultStore_Expiration
+func Test_Limiter_DefaultStore_Expiration(t *testing.T) {
+	t.Parallel()
+	store := newDefaultStore(10 * time.Millisecond)
+	utils.AssertEqual(t, nil, store.Set("expiring", []byte("1"), 20*time.Millisecond))
+	utils.AssertEqual(t, nil, store.Set("kept", []byte("2"), 0))
+
+	val, err := store.Get("expiring")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, []byte("1"), val)
+
+	time.Sleep(50 * time.Millisecond)
+	val, err = store.Get("expiring")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 0, len(val))
+	val, err = store.Get("kept")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, []byte("2"), val)
+
+	// expired values are deleted
+	store.mutex.Lock()
+	utils.AssertEqual(t, 1, len(store.stmap))
+	store.mutex.Unlock()
+}
+
 // testStore is used for testing custom stores
 type testStore struct {
 	stmap map[string][]byte
@@ -219,5 +693,9 @@ func (s testStore) Clear() error {
 }
 
 func (s testStore) Delete(id string) error {
+	s.mutex.Lock()
+	delete(s.stmap, id)
+	s.mutex.Unlock()
+
 	return nil
 }
@@ -18,44 +18,79 @@ type Storage interface {
 	Clear() error
 }
 
+// defaultStore is the in-memory store of limiters without Store
 type defaultStore struct {
-	stmap map[string][]byte
+	stmap map[string]storeEntry
 	mutex sync.Mutex
 }
 
-func (s defaultStore) Get(id string) ([]byte, error) {
+// storeEntry is a value of the defaultStore with its expiration
+type storeEntry struct {
+	val     []byte
+	expires time.Time
+}
+
+// defaultStores are the stores of all limiters without Store, see Reset
+var defaultStores = struct {
+	sync.Mutex
+	stores []*defaultStore
+}{}
+
+// newDefaultStore creates an in-memory store that deletes expired values every gcInterval
+func newDefaultStore(gcInterval time.Duration) *defaultStore {
+	s := &defaultStore{stmap: make(map[string]storeEntry)}
+	go func() {
+		for {
+			time.Sleep(gcInterval)
+			now := time.Now()
+			s.mutex.Lock()
+			for id, entry := range s.stmap {
+				if !entry.expires.IsZero() && !entry.expires.After(now) {
+					delete(s.stmap, id)
+				}
+			}
+			s.mutex.Unlock()
+		}
+	}()
+	defaultStores.Lock()
+	defaultStores.stores = append(defaultStores.stores, s)
+	defaultStores.Unlock()
+	return s
+}
+
+func (s *defaultStore) Get(id string) ([]byte, error) {
 	s.mutex.Lock()
-	val, ok := s.stmap[id]
+	entry, ok := s.stmap[id]
 	s.mutex.Unlock()
-	if !ok {
+	if !ok || (!entry.expires.IsZero() && !entry.expires.After(time.Now())) {
 		return []byte{}, nil
-	} else {
-		return val, nil
 	}
+	return entry.val, nil
 }
 
-func (s defaultStore) Set(id string, val []byte, _ time.Duration) error {
+func (s *defaultStore) Set(id string, val []byte, exp time.Duration) error {
+	entry := storeEntry{val: val}
+	if exp > 0 {
+		entry.expires = time.Now().Add(exp)
+	}
 	s.mutex.Lock()
-	s.stmap[id] = val
+	s.stmap[id] = entry
 	s.mutex.Unlock()
 
 	return nil
 }
 
-func (s defaultStore) Clear() error {
+func (s *defaultStore) Clear() error {
 	s.mutex.Lock()
-	s.stmap = map[string][]byte{}
+	s.stmap = make(map[string]storeEntry)
 	s.mutex.Unlock()
 
 	return nil
 }
 
-func (s defaultStore) Delete(id string) error {
+func (s *defaultStore) Delete(id string) error {
 	s.mutex.Lock()
-	_, ok := s.stmap[id]
-	if ok {
-		delete(s.stmap, id)
-	}
+	delete(s.stmap, id)
 	s.mutex.Unlock()
 
 	return nil
@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Percentiles holds the latency percentiles of the latest logged requests
+type Percentiles struct {
+	P50 time.Duration
+	P95 time.Duration
+	P99 time.Duration
+	// Count is the amount of latencies the percentiles are computed of,
+	// all percentiles are 0 if no request was logged yet
+	Count int
+}
+
+// histogram is a rolling window of the latest latencies
+type histogram struct {
+	mutex   sync.Mutex
+	samples []time.Duration
+	next    int
+	full    bool
+}
+
+func newHistogram(size int) *histogram {
+	return &histogram{samples: make([]time.Duration, size)}
+}
+
+// add records a latency, replacing the oldest one if the window is full
+func (h *histogram) add(latency time.Duration) {
+	h.mutex.Lock()
+	h.samples[h.next] = latency
+	h.next++
+	if h.next == len(h.samples) {
+		h.next = 0
+		h.full = true
+	}
+	h.mutex.Unlock()
+}
+
+// percentiles computes the percentiles of the recorded latencies
+// using the nearest-rank method
+func (h *histogram) percentiles() Percentiles {
+	h.mutex.Lock()
+	n := h.next
+	if h.full {
+		n = len(h.samples)
+	}
+	sorted := make([]time.Duration, n)
+	copy(sorted, h.samples[:n])
+	h.mutex.Unlock()
+
+	if n == 0 {
+		return Percentiles{}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := func(p int) time.Duration {
+		// ceil(p / 100 * n) - 1
+		return sorted[(p*n+99)/100-1]
+	}
+	return Percentiles{
+		P50:   rank(50),
+		P95:   rank(95),
+		P99:   rank(99),
+		Count: n,
+	}
+}
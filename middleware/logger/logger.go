@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
 	"sync"
@@ -14,7 +15,7 @@ import (
 	"github.com/gofiber/fiber/v2/internal/bytebufferpool"
 	"github.com/gofiber/fiber/v2/internal/colorable"
 	"github.com/gofiber/fiber/v2/internal/fasttemplate"
-	"github.com/gofiber/fiber/v2/internal/isatty"
+	"github.com/gofiber/fiber/v2/internal/methods"
 	"github.com/valyala/fasthttp"
 )
 
@@ -25,6 +26,12 @@ type Config struct {
 	// Optional. Default: nil
 	Next func(c *fiber.Ctx) bool
 
+	// Methods restricts the middleware to the listed methods, e.g. only GET
+	// requests with []string{fiber.MethodGet}. Other methods skip it like Next.
+	//
+	// Optional. Default: nil (all methods)
+	Methods []string
+
 	// Format defines the logging tags
 	//
 	// Optional. Default: [${time}] ${status} - ${latency} ${method} ${path}\n
@@ -44,18 +51,71 @@ type Config struct {
 	// Default: os.Stderr
 	Output io.Writer
 
-	enableColors     bool
-	enableLatency    bool
-	timeZoneLocation *time.Location
+	// DisableColors disables the colors in the log output,
+	// even if the output is a terminal.
+	//
+	// Optional. Default: false
+	DisableColors bool
+
+	// ForceColors enables the colors in the log output,
+	// even if the output is not a terminal, e.g. a file.
+	// DisableColors takes precedence over ForceColors.
+	//
+	// Optional. Default: false
+	ForceColors bool
+
+	// Percentiles enables the aggregation of the latencies, it is called
+	// with the p50, p95 and p99 of the latest requests every PercentilesInterval
+	//
+	// Optional. Default: nil
+	Percentiles func(p Percentiles)
+
+	// PercentilesInterval is the interval of the Percentiles callback
+	//
+	// Optional. Default: 1 * time.Second
+	PercentilesInterval time.Duration
+
+	// PercentilesWindow is the amount of latest requests the percentiles are computed of
+	//
+	// Optional. Default: 1024
+	PercentilesWindow int
+
+	// FlushInterval buffers the log lines and writes them to the Output
+	// every interval or when the buffer is full, instead of every line.
+	//
+	// Optional. Default: 0 (unbuffered)
+	FlushInterval time.Duration
+
+	// ReopenSignal reopens the Output file by its name when the signal is
+	// received, e.g. syscall.SIGHUP after logrotate moved the file.
+	// The buffered log lines are written to the old file before.
+	// The Output has to be an *os.File, other writers are not reopened.
+	//
+	// Optional. Default: nil
+	ReopenSignal os.Signal
+
+	// Done is called after the log line of a request is written to the Output,
+	// with the Ctx and the rendered log line, e.g. to push metrics.
+	// The log line is only valid within the callback, make a copy to keep it.
+	//
+	// Optional. Default: nil
+	Done func(c *fiber.Ctx, logLine []byte)
+
+	enableDefaultFormat bool
+	colors              colors
+	enableLatency       bool
+	timeZoneLocation    *time.Location
 }
 
 // ConfigDefault is the default config
 var ConfigDefault = Config{
-	Next:       nil,
-	Format:     "[${time}] ${status} - ${latency} ${method} ${path}\n",
-	TimeFormat: "15:04:05",
-	TimeZone:   "Local",
-	Output:     os.Stderr,
+	Next:                nil,
+	Format:              "[${time}] ${status} - ${latency} ${method} ${path}\n",
+	TimeFormat:          "15:04:05",
+	TimeZone:            "Local",
+	Output:              os.Stderr,
+	PercentilesInterval: 1 * time.Second,
+	PercentilesWindow:   1024,
 }
 
 // Logger variables
@@ -115,9 +175,9 @@ func New(config ...Config) fiber.Handler {
 	if len(config) > 0 {
 		cfg = config[0]
 
-		// Enable colors if no custom format or output is given
+		// Use the default format if no custom format or output is given
 		if cfg.Format == "" && cfg.Output == nil {
-			cfg.enableColors = true
+			cfg.enableDefaultFormat = true
 		}
 
 		// Set default values
@@ -136,10 +196,19 @@ func New(config ...Config) fiber.Handler {
 		if cfg.Output == nil {
 			cfg.Output = ConfigDefault.Output
 		}
+		if cfg.PercentilesInterval <= 0 {
+			cfg.PercentilesInterval = ConfigDefault.PercentilesInterval
+		}
+		if cfg.PercentilesWindow <= 0 {
+			cfg.PercentilesWindow = ConfigDefault.PercentilesWindow
+		}
 	} else {
-		cfg.enableColors = true
+		cfg.enableDefaultFormat = true
 	}
 
+	// Skip the methods not listed
+	cfg.Next = methods.Next(cfg.Next, cfg.Methods)
+
 	// Get timezone location
 	tz, err := time.LoadLocation(cfg.TimeZone)
 	if err != nil || tz == nil {
@@ -168,6 +237,18 @@ func New(config ...Config) fiber.Handler {
 		}()
 	}
 
+	// Report the latency percentiles every interval in a separate go routine
+	var latencies *histogram
+	if cfg.Percentiles != nil {
+		latencies = newHistogram(cfg.PercentilesWindow)
+		go func() {
+			for {
+				time.Sleep(cfg.PercentilesInterval)
+				cfg.Percentiles(latencies.percentiles())
+			}
+		}()
+	}
+
 	// Set PID once
 	pid := strconv.Itoa(os.Getpid())
 
@@ -178,13 +259,40 @@ func New(config ...Config) fiber.Handler {
 		errHandler  fiber.ErrorHandler
 	)
 
-	// If colors are enabled, check terminal compatibility
-	if cfg.enableColors {
-		cfg.Output = colorable.NewColorableStderr()
-		if os.Getenv("TERM") == "dumb" || (!isatty.IsTerminal(os.Stderr.Fd()) && !isatty.IsCygwinTerminal(os.Stderr.Fd())) {
-			cfg.Output = colorable.NewNonColorable(os.Stderr)
+	// Only write colors if the output is a terminal, unless overridden
+	cfg.colors = disabledColors
+	if !cfg.DisableColors && (cfg.ForceColors || isTerminal(cfg.Output)) {
+		cfg.colors = enabledColors
+		// Translate escape sequences for terminals that don't support them, i.e. windows
+		if f, ok := cfg.Output.(*os.File); ok {
+			cfg.Output = colorable.NewColorable(f)
+		}
+	}
+	// Serialize, buffer and reopen the writes to the output if enabled
+	if cfg.FlushInterval > 0 || cfg.ReopenSignal != nil {
+		out := newOutput(cfg.Output, cfg.FlushInterval > 0)
+		cfg.Output = out
+		if cfg.FlushInterval > 0 {
+			go func() {
+				for {
+					time.Sleep(cfg.FlushInterval)
+					_ = out.flush()
+				}
+			}()
+		}
+		if cfg.ReopenSignal != nil {
+			signals := make(chan os.Signal, 1)
+			signal.Notify(signals, cfg.ReopenSignal)
+			go func() {
+				for range signals {
+					if err := out.reopen(); err != nil {
+						fmt.Fprintf(os.Stderr, "logger: failed to reopen the output: %v\n", err)
+					}
+				}
+			}()
 		}
 	}
+
 	var errPadding = 15
 	var errPaddingStr = strconv.Itoa(errPadding)
 	// Return new handler
@@ -213,6 +321,10 @@ func New(config ...Config) fiber.Handler {
 		if cfg.enableLatency {
 			start = time.Now()
 		}
+		var received time.Time
+		if latencies != nil {
+			received = time.Now()
+		}
 
 		// Handle request, store err for logging
 		chainErr := c.Next()
@@ -228,25 +340,28 @@ func New(config ...Config) fiber.Handler {
 		if cfg.enableLatency {
 			stop = time.Now()
 		}
+		if latencies != nil {
+			latencies.add(time.Since(received))
+		}
 
 		// Get new buffer
 		buf := bytebufferpool.Get()
 
 		// Default output when no custom Format or io.Writer is given
-		if cfg.enableColors {
+		if cfg.enableDefaultFormat {
 			// Format error if exist
 			formatErr := ""
 			if chainErr != nil {
-				formatErr = cRed + " | " + chainErr.Error() + cReset
+				formatErr = cfg.colors.red + " | " + chainErr.Error() + cfg.colors.reset
 			}
 
 			// Format log to buffer
 			_, _ = buf.WriteString(fmt.Sprintf("%s |%s %3d %s| %7v | %15s |%s %-7s %s| %-"+errPaddingStr+"s %s\n",
 				timestamp.Load().(string),
-				statusColor(c.Response().StatusCode()), c.Response().StatusCode(), cReset,
+				cfg.colors.status(c.Response().StatusCode()), c.Response().StatusCode(), cfg.colors.reset,
 				stop.Sub(start).Round(time.Millisecond),
 				c.IP(),
-				methodColor(c.Method()), c.Method(), cReset,
+				cfg.colors.method(c.Method()), c.Method(), cfg.colors.reset,
 				c.Path(),
 				formatErr,
 			))
@@ -254,6 +369,10 @@ func New(config ...Config) fiber.Handler {
 			// Write buffer to output
 			_, _ = cfg.Output.Write(buf.Bytes())
 
+			if cfg.Done != nil {
+				cfg.Done(c, buf.Bytes())
+			}
+
 			// Put buffer back to pool
 			bytebufferpool.Put(buf)
 
@@ -299,23 +418,23 @@ func New(config ...Config) fiber.Handler {
 			case TagMethod:
 				return buf.WriteString(c.Method())
 			case TagBlack:
-				return buf.WriteString(cBlack)
+				return buf.WriteString(cfg.colors.black)
 			case TagRed:
-				return buf.WriteString(cRed)
+				return buf.WriteString(cfg.colors.red)
 			case TagGreen:
-				return buf.WriteString(cGreen)
+				return buf.WriteString(cfg.colors.green)
 			case TagYellow:
-				return buf.WriteString(cYellow)
+				return buf.WriteString(cfg.colors.yellow)
 			case TagBlue:
-				return buf.WriteString(cBlue)
+				return buf.WriteString(cfg.colors.blue)
 			case TagMagenta:
-				return buf.WriteString(cMagenta)
+				return buf.WriteString(cfg.colors.magenta)
 			case TagCyan:
-				return buf.WriteString(cCyan)
+				return buf.WriteString(cfg.colors.cyan)
 			case TagWhite:
-				return buf.WriteString(cWhite)
+				return buf.WriteString(cfg.colors.white)
 			case TagReset:
-				return buf.WriteString(cReset)
+				return buf.WriteString(cfg.colors.reset)
 			case TagError:
 				if chainErr != nil {
 					return buf.WriteString(chainErr.Error())
@@ -348,6 +467,11 @@ func New(config ...Config) fiber.Handler {
 				// TODO: What should we do here?
 			}
 		}
+
+		if cfg.Done != nil {
+			cfg.Done(c, buf.Bytes())
+		}
+
 		// Put buffer back to pool
 		bytebufferpool.Put(buf)
 
@@ -1,12 +1,20 @@
 package logger
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/internal/bytebufferpool"
@@ -50,6 +58,144 @@ func Test_Logger_Next(t *testing.T) {
 	utils.AssertEqual(t, fiber.StatusNotFound, resp.StatusCode)
 }
 
+// go test -run Test_Logger_Methods
+func Test_Logger_Methods(t *testing.T) {
+	app := fiber.New()
+
+	buf := bytebufferpool.Get()
+	defer bytebufferpool.Put(buf)
+
+	app.Use(New(Config{
+		Format:  "${method} ",
+		Output:  buf,
+		Methods: []string{fiber.MethodGet},
+	}))
+
+	for _, method := range []string{"GET", "POST", "GET"} {
+		_, err := app.Test(httptest.NewRequest(method, "/", nil))
+		utils.AssertEqual(t, nil, err)
+	}
+	utils.AssertEqual(t, "GET GET ", buf.String())
+}
+
+// go test -run Test_Logger_Done
+func Test_Logger_Done(t *testing.T) {
+	app := fiber.New()
+
+	var status int
+	var logLine string
+	app.Use(New(Config{
+		Format: "${status} ${latency}",
+		Output: ioutil.Discard,
+		Done: func(c *fiber.Ctx, line []byte) {
+			status = c.Response().StatusCode()
+			logLine = string(line)
+		},
+	}))
+
+	app.Get("/", func(c *fiber.Ctx) error {
+		time.Sleep(20 * time.Millisecond)
+		return c.SendStatus(fiber.StatusTeapot)
+	})
+
+	_, err := app.Test(httptest.NewRequest("GET", "/", nil))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, fiber.StatusTeapot, status)
+
+	fields := strings.Fields(logLine)
+	utils.AssertEqual(t, 2, len(fields), logLine)
+	utils.AssertEqual(t, "418", fields[0])
+	latency, err := time.ParseDuration(fields[1])
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, latency >= 20*time.Millisecond, latency.String())
+}
+
+// syncBuffer is a bytes.Buffer that can be read while the logger writes to it
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// go test -run Test_Logger_FlushInterval
+func Test_Logger_FlushInterval(t *testing.T) {
+	app := fiber.New()
+
+	out := &syncBuffer{}
+	app.Use(New(Config{
+		Format:        "${status} ${path}\n",
+		Output:        out,
+		FlushInterval: 100 * time.Millisecond,
+	}))
+
+	for _, path := range []string{"/a", "/b"} {
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, fiber.StatusNotFound, resp.StatusCode)
+	}
+	// the lines are buffered until the interval passed
+	utils.AssertEqual(t, "", out.String())
+
+	time.Sleep(300 * time.Millisecond)
+	utils.AssertEqual(t, "404 /a\n404 /b\n", out.String())
+}
+
+// go test -run Test_Logger_ReopenSignal
+func Test_Logger_ReopenSignal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("signals can't be sent on windows")
+	}
+	dir, err := ioutil.TempDir("", "logger")
+	utils.AssertEqual(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "access.log")
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	utils.AssertEqual(t, nil, err)
+
+	app := fiber.New()
+	app.Use(New(Config{
+		Format:        "${path}\n",
+		Output:        file,
+		FlushInterval: time.Hour,
+		ReopenSignal:  syscall.SIGHUP,
+	}))
+
+	_, err = app.Test(httptest.NewRequest("GET", "/before", nil))
+	utils.AssertEqual(t, nil, err)
+
+	// rotate the file and signal the logger to reopen it
+	utils.AssertEqual(t, nil, os.Rename(name, name+".1"))
+	proc, err := os.FindProcess(os.Getpid())
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, nil, proc.Signal(syscall.SIGHUP))
+	time.Sleep(100 * time.Millisecond)
+
+	_, err = app.Test(httptest.NewRequest("GET", "/after", nil))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, nil, proc.Signal(syscall.SIGHUP))
+	time.Sleep(100 * time.Millisecond)
+
+	// the buffered line is flushed to the rotated file before reopening
+	rotated, err := ioutil.ReadFile(name + ".1")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "/before\n", string(rotated))
+	current, err := ioutil.ReadFile(name)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "/after\n", string(current))
+}
+
 // go test -run Test_Logger_ErrorTimeZone
 func Test_Logger_ErrorTimeZone(t *testing.T) {
 	app := fiber.New()
@@ -91,8 +237,9 @@ func Test_Logger_All(t *testing.T) {
 
 	app := fiber.New()
 	app.Use(New(Config{
-		Format: "${pid}${referer}${protocol}${ip}${ips}${host}${url}${ua}${body}${route}${black}${red}${green}${yellow}${blue}${magenta}${cyan}${white}${reset}${error}${header:test}${query:test}${form:test}${cookie:test}${non}",
-		Output: buf,
+		Format:      "${pid}${referer}${protocol}${ip}${ips}${host}${url}${ua}${body}${route}${black}${red}${green}${yellow}${blue}${magenta}${cyan}${white}${reset}${error}${header:test}${query:test}${form:test}${cookie:test}${non}",
+		Output:      buf,
+		ForceColors: true,
 	}))
 
 	resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
@@ -103,6 +250,44 @@ func Test_Logger_All(t *testing.T) {
 	utils.AssertEqual(t, expected, buf.String())
 }
 
+// go test -run Test_Logger_Colors
+func Test_Logger_Colors(t *testing.T) {
+	format := "${red}${status}${reset} ${method}"
+
+	test := func(cfg Config, expected string) {
+		buf := bytebufferpool.Get()
+		defer bytebufferpool.Put(buf)
+
+		cfg.Format = format
+		cfg.Output = buf
+
+		app := fiber.New()
+		app.Use(New(cfg))
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, fiber.StatusNotFound, resp.StatusCode)
+		utils.AssertEqual(t, expected, buf.String())
+	}
+
+	// No colors if the output is not a terminal
+	test(Config{}, "404 GET")
+	// Force colors
+	test(Config{ForceColors: true}, cRed+"404"+cReset+" GET")
+	// DisableColors takes precedence
+	test(Config{ForceColors: true, DisableColors: true}, "404 GET")
+}
+
+// go test -run Test_Logger_StatusColor
+func Test_Logger_StatusColor(t *testing.T) {
+	utils.AssertEqual(t, cGreen, enabledColors.status(fiber.StatusOK))
+	utils.AssertEqual(t, cBlue, enabledColors.status(fiber.StatusFound))
+	utils.AssertEqual(t, cYellow, enabledColors.status(fiber.StatusNotFound))
+	utils.AssertEqual(t, cRed, enabledColors.status(fiber.StatusInternalServerError))
+	utils.AssertEqual(t, "", disabledColors.status(fiber.StatusOK))
+	utils.AssertEqual(t, "", disabledColors.method(fiber.MethodGet))
+}
+
 // go test -run Test_Logger_AppendUint
 func Test_Logger_AppendUint(t *testing.T) {
 	app := fiber.New()
@@ -125,6 +310,67 @@ func Test_Logger_AppendUint(t *testing.T) {
 	utils.AssertEqual(t, "0 5 200", buf.String())
 }
 
+// go test -run Test_Logger_Percentiles
+func Test_Logger_Percentiles(t *testing.T) {
+	h := newHistogram(100)
+	utils.AssertEqual(t, Percentiles{}, h.percentiles())
+
+	// 1ms ... 100ms in reverse order
+	for i := 100; i > 0; i-- {
+		h.add(time.Duration(i) * time.Millisecond)
+	}
+	utils.AssertEqual(t, Percentiles{
+		P50:   50 * time.Millisecond,
+		P95:   95 * time.Millisecond,
+		P99:   99 * time.Millisecond,
+		Count: 100,
+	}, h.percentiles())
+
+	// the oldest latencies are replaced
+	for i := 0; i < 50; i++ {
+		h.add(time.Second)
+	}
+	p := h.percentiles()
+	utils.AssertEqual(t, 100, p.Count)
+	utils.AssertEqual(t, 50*time.Millisecond, p.P50)
+	utils.AssertEqual(t, time.Second, p.P95)
+}
+
+// go test -run Test_Logger_Percentiles_Callback
+func Test_Logger_Percentiles_Callback(t *testing.T) {
+	app := fiber.New()
+
+	reports := make(chan Percentiles, 1)
+	app.Use(New(Config{
+		Output: ioutil.Discard,
+		Percentiles: func(p Percentiles) {
+			if p.Count == 2 {
+				select {
+				case reports <- p:
+				default:
+				}
+			}
+		},
+		PercentilesInterval: 10 * time.Millisecond,
+	}))
+
+	app.Get("/:ms", func(c *fiber.Ctx) error {
+		ms, _ := strconv.Atoi(c.Params("ms"))
+		time.Sleep(time.Duration(ms) * time.Millisecond)
+		return nil
+	})
+
+	for _, path := range []string{"/10", "/50"} {
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, fiber.StatusOK, resp.StatusCode)
+	}
+
+	p := <-reports
+	utils.AssertEqual(t, true, p.P50 >= 10*time.Millisecond && p.P50 < 40*time.Millisecond, p.P50.String())
+	utils.AssertEqual(t, true, p.P99 >= 50*time.Millisecond && p.P99 < 80*time.Millisecond, p.P99.String())
+}
+
 // go test -v -run=^$ -bench=Benchmark_Logger -benchmem -count=4
 func Benchmark_Logger(b *testing.B) {
 	app := fiber.New()
@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"sync"
+)
+
+// output serializes the writes of the log lines, it buffers them if
+// FlushInterval is set and can reopen a file after it was rotated
+type output struct {
+	mu   sync.Mutex
+	w    io.Writer
+	buf  *bufio.Writer
+	file *os.File
+}
+
+func newOutput(w io.Writer, buffered bool) *output {
+	o := &output{w: w}
+	o.file, _ = w.(*os.File)
+	if buffered {
+		o.buf = bufio.NewWriter(w)
+	}
+	return o
+}
+
+func (o *output) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.buf != nil {
+		return o.buf.Write(p)
+	}
+	return o.w.Write(p)
+}
+
+// flush writes the buffered log lines to the output
+func (o *output) flush() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.buf == nil {
+		return nil
+	}
+	return o.buf.Flush()
+}
+
+// reopen flushes the log lines and opens the file of the output again by
+// its name, e.g. after the file was moved by logrotate
+func (o *output) reopen() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.file == nil {
+		return nil
+	}
+	if o.buf != nil {
+		if err := o.buf.Flush(); err != nil {
+			return err
+		}
+	}
+	// #nosec G302 G304
+	file, err := os.OpenFile(o.file.Name(), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	_ = o.file.Close()
+	o.w, o.file = file, file
+	if o.buf != nil {
+		o.buf.Reset(file)
+	}
+	return nil
+}
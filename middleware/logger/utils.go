@@ -1,39 +1,71 @@
 package logger
 
 import (
+	"io"
+	"os"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/internal/isatty"
+)
+
+// colors holds the escape codes written to the output,
+// all codes are empty if colors are disabled
+type colors struct {
+	black   string
+	red     string
+	green   string
+	yellow  string
+	blue    string
+	magenta string
+	cyan    string
+	white   string
+	reset   string
+}
+
+var (
+	enabledColors  = colors{cBlack, cRed, cGreen, cYellow, cBlue, cMagenta, cCyan, cWhite, cReset}
+	disabledColors = colors{}
 )
 
-func methodColor(method string) string {
+func (col colors) method(method string) string {
 	switch method {
 	case fiber.MethodGet:
-		return cCyan
+		return col.cyan
 	case fiber.MethodPost:
-		return cGreen
+		return col.green
 	case fiber.MethodPut:
-		return cYellow
+		return col.yellow
 	case fiber.MethodDelete:
-		return cRed
+		return col.red
 	case fiber.MethodPatch:
-		return cWhite
+		return col.white
 	case fiber.MethodHead:
-		return cMagenta
+		return col.magenta
 	case fiber.MethodOptions:
-		return cBlue
+		return col.blue
 	default:
-		return cReset
+		return col.reset
 	}
 }
 
-func statusColor(code int) string {
+func (col colors) status(code int) string {
 	switch {
 	case code >= fiber.StatusOK && code < fiber.StatusMultipleChoices:
-		return cGreen
+		return col.green
 	case code >= fiber.StatusMultipleChoices && code < fiber.StatusBadRequest:
-		return cBlue
+		return col.blue
 	case code >= fiber.StatusBadRequest && code < fiber.StatusInternalServerError:
-		return cYellow
+		return col.yellow
 	default:
-		return cRed
+		return col.red
+	}
+}
+
+// isTerminal reports whether the writer is a terminal that supports colors
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok || os.Getenv("TERM") == "dumb" {
+		return false
 	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
 }
@@ -24,12 +24,14 @@ type Config struct {
 	// Required
 	Servers []string
 
-	// ModifyRequest allows you to alter the request
+	// ModifyRequest allows you to alter the request before it is forwarded,
+	// the hop-by-hop headers like Connection are already removed.
 	//
 	// Optional. Default: nil
 	ModifyRequest fiber.Handler
 
-	// ModifyResponse allows you to alter the response
+	// ModifyResponse allows you to alter the response of the upstream server,
+	// the hop-by-hop headers like Connection are already removed.
 	//
 	// Optional. Default: nil
 	ModifyResponse fiber.Handler
@@ -86,8 +88,8 @@ func Balancer(config Config) fiber.Handler {
 		req := c.Request()
 		res := c.Response()
 
-		// Don't proxy "Connection" header
-		req.Header.Del(fiber.HeaderConnection)
+		// Don't proxy hop-by-hop headers
+		stripHopHeaders(&req.Header)
 
 		// Modify request
 		if cfg.ModifyRequest != nil {
@@ -104,8 +106,8 @@ func Balancer(config Config) fiber.Handler {
 			return err
 		}
 
-		// Don't proxy "Connection" header
-		res.Header.Del(fiber.HeaderConnection)
+		// Don't proxy hop-by-hop headers
+		stripHopHeaders(&res.Header)
 
 		// Modify response
 		if cfg.ModifyResponse != nil {
@@ -138,10 +140,45 @@ func Do(c *fiber.Ctx, addr string) error {
 	req := c.Request()
 	res := c.Response()
 	req.SetRequestURI(addr)
-	req.Header.Del(fiber.HeaderConnection)
+	stripHopHeaders(&req.Header)
 	if err := client.Do(req, res); err != nil {
 		return err
 	}
-	res.Header.Del(fiber.HeaderConnection)
+	stripHopHeaders(&res.Header)
 	return nil
 }
+
+// hopHeaders are only valid for a single connection and are not forwarded
+// https://tools.ietf.org/html/rfc7230#section-6.1
+var hopHeaders = []string{
+	fiber.HeaderConnection,
+	fiber.HeaderKeepAlive,
+	fiber.HeaderProxyAuthenticate,
+	fiber.HeaderProxyAuthorization,
+	fiber.HeaderTE,
+	fiber.HeaderTrailer,
+	fiber.HeaderTransferEncoding,
+	fiber.HeaderUpgrade,
+	"Proxy-Connection",
+}
+
+// header is implemented by the request and response headers of fasthttp
+type header interface {
+	Peek(key string) []byte
+	Del(key string)
+}
+
+// stripHopHeaders removes the hop-by-hop headers and the headers listed in
+// the Connection header
+func stripHopHeaders(h header) {
+	if connection := h.Peek(fiber.HeaderConnection); len(connection) > 0 {
+		for _, key := range strings.Split(string(connection), ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				h.Del(key)
+			}
+		}
+	}
+	for _, key := range hopHeaders {
+		h.Del(key)
+	}
+}
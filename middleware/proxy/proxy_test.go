@@ -158,3 +158,54 @@ func Test_Proxy_Modify_Request(t *testing.T) {
 	utils.AssertEqual(t, nil, err)
 	utils.AssertEqual(t, "modified request", string(b))
 }
+
+// go test -run Test_Proxy_Hop_Headers
+func Test_Proxy_Hop_Headers(t *testing.T) {
+	target := fiber.New(fiber.Config{DisableStartupMessage: true})
+	target.Get("/", func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderKeepAlive, "timeout=5")
+		c.Set(fiber.HeaderProxyAuthenticate, "Basic")
+		c.Set("X-Upstream", "kept")
+		return c.Status(fiber.StatusInternalServerError).SendString(strings.Join([]string{
+			c.Get("X-Client-Hop"),
+			c.Get(fiber.HeaderProxyAuthorization),
+			c.Get("X-Real-IP"),
+			c.Get("X-Kept"),
+		}, "|"))
+	})
+	go func() {
+		utils.AssertEqual(t, nil, target.Listen(":50004"))
+	}()
+	time.Sleep(1 * time.Second)
+
+	app := fiber.New()
+	app.Use(Balancer(Config{
+		Servers: []string{"127.0.0.1:50004"},
+		ModifyRequest: func(c *fiber.Ctx) error {
+			c.Request().Header.Set("X-Real-IP", "1.2.3.4")
+			return nil
+		},
+		ModifyResponse: func(c *fiber.Ctx) error {
+			// the hop-by-hop headers are removed before the response is modified
+			utils.AssertEqual(t, "", string(c.Response().Header.Peek(fiber.HeaderKeepAlive)))
+			c.Response().SetStatusCode(fiber.StatusOK)
+			return nil
+		},
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(fiber.HeaderConnection, "X-Client-Hop")
+	req.Header.Set("X-Client-Hop", "removed")
+	req.Header.Set(fiber.HeaderProxyAuthorization, "Basic Zm9vOmJhcg==")
+	req.Header.Set("X-Kept", "kept")
+	resp, err := app.Test(req, 2000)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, fiber.StatusOK, resp.StatusCode)
+	utils.AssertEqual(t, "", resp.Header.Get(fiber.HeaderKeepAlive))
+	utils.AssertEqual(t, "", resp.Header.Get(fiber.HeaderProxyAuthenticate))
+	utils.AssertEqual(t, "kept", resp.Header.Get("X-Upstream"))
+
+	b, err := ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "||1.2.3.4|kept", string(b))
+}
@@ -0,0 +1,77 @@
+package redirect
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/internal/pathrule"
+)
+
+// Config defines the config for middleware.
+type Config struct {
+	// Next defines a function to skip this middleware when returned true.
+	//
+	// Optional. Default: nil
+	Next func(c *fiber.Ctx) bool
+
+	// Rules defines the URL path redirect rules. A '*' in the key matches any
+	// characters, the matched values are available as $1, $2, ... in the value.
+	// Longer rules are tried first, only the first matching rule is applied.
+	//
+	// Required. Example:
+	// "/old":              "/new",
+	// "/api/*":            "/$1",
+	// "/js/*":             "/public/javascripts/$1",
+	// "/users/*/orders/*": "/user/$1/order/$2",
+	Rules map[string]string
+
+	// StatusCode is the status code of the redirect
+	//
+	// Optional. Default: 302 Found
+	StatusCode int
+}
+
+// ConfigDefault is the default config
+var ConfigDefault = Config{
+	Next:       nil,
+	StatusCode: fiber.StatusFound,
+}
+
+// New creates a new middleware handler
+func New(config ...Config) fiber.Handler {
+	// Set default config
+	cfg := ConfigDefault
+
+	// Override config if provided
+	if len(config) > 0 {
+		cfg = config[0]
+
+		// Set default values
+		if cfg.StatusCode == 0 {
+			cfg.StatusCode = ConfigDefault.StatusCode
+		}
+	}
+
+	// Compile rules, longer rules first to apply the most specific rule
+	rules := pathrule.Compile(cfg.Rules)
+
+	// Return new handler
+	return func(c *fiber.Ctx) error {
+		// Don't execute middleware if Next returns true
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		// Redirect to the target of the first matching rule
+		if location, ok := pathrule.Match(rules, c.Path()); ok {
+			// Keep the query string unless the target has its own
+			if query := c.Context().URI().QueryString(); len(query) > 0 && !strings.Contains(location, "?") {
+				location += "?" + string(query)
+			}
+			return c.Redirect(location, cfg.StatusCode)
+		}
+
+		// Continue stack
+		return c.Next()
+	}
+}
@@ -0,0 +1,83 @@
+package redirect
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// go test -run Test_Redirect
+func Test_Redirect(t *testing.T) {
+	app := fiber.New()
+
+	app.Use(New(Config{
+		Rules: map[string]string{
+			"/old":              "/new",
+			"/old/*":            "/new/$1",
+			"/users/*/orders/*": "/user/$1/order/$2",
+			"/search":           "https://example.com/search?source=old",
+		},
+	}))
+
+	app.Get("/other", func(c *fiber.Ctx) error {
+		return c.SendString("other")
+	})
+
+	testCases := []struct {
+		url      string
+		location string
+	}{
+		{"/old", "/new"},
+		{"/old/123", "/new/123"},
+		{"/old/123?query=1", "/new/123?query=1"},
+		{"/users/john/orders/42", "/user/john/order/42"},
+		{"/search?q=fiber", "https://example.com/search?source=old"},
+	}
+	for _, tc := range testCases {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, tc.url, nil))
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, fiber.StatusFound, resp.StatusCode, tc.url)
+		utils.AssertEqual(t, tc.location, resp.Header.Get(fiber.HeaderLocation), tc.url)
+	}
+
+	// unmatched paths fall through
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/other", nil))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, fiber.StatusOK, resp.StatusCode)
+	utils.AssertEqual(t, "", resp.Header.Get(fiber.HeaderLocation))
+}
+
+// go test -run Test_Redirect_StatusCode
+func Test_Redirect_StatusCode(t *testing.T) {
+	app := fiber.New()
+	app.Use(New(Config{
+		Rules: map[string]string{
+			"/old/*": "/new/$1",
+		},
+		StatusCode: fiber.StatusMovedPermanently,
+	}))
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/old/123", nil))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, fiber.StatusMovedPermanently, resp.StatusCode)
+	utils.AssertEqual(t, "/new/123", resp.Header.Get(fiber.HeaderLocation))
+}
+
+// go test -run Test_Redirect_Next
+func Test_Redirect_Next(t *testing.T) {
+	app := fiber.New()
+	app.Use(New(Config{
+		Next: func(_ *fiber.Ctx) bool {
+			return true
+		},
+		Rules: map[string]string{
+			"/old": "/new",
+		},
+	}))
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/old", nil))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, fiber.StatusNotFound, resp.StatusCode)
+}
@@ -1,6 +1,10 @@
 package requestid
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/utils"
 )
@@ -17,7 +21,8 @@ type Config struct {
 	// Optional. Default: "X-Request-ID"
 	Header string
 
-	// Generator defines a function to generate the unique identifier.
+	// Generator defines a function to generate the unique identifier,
+	// e.g. the presets UUIDv4 or Base62(length).
 	//
 	// Optional. Default: utils.UUID
 	Generator func() string
@@ -29,11 +34,17 @@ type Config struct {
 	ContextKey string
 }
 
+// contextKey is unexported to prevent collisions with context keys of other packages
+type contextKey int
+
+// ContextKey is the key used when storing the request ID in the UserContext
+const ContextKey contextKey = 0
+
 // ConfigDefault is the default config
 var ConfigDefault = Config{
-	Next:      nil,
-	Header:    fiber.HeaderXRequestID,
-	Generator: utils.UUID,
+	Next:       nil,
+	Header:     fiber.HeaderXRequestID,
+	Generator:  utils.UUID,
 	ContextKey: "requestid",
 }
 
@@ -73,7 +84,71 @@ func New(config ...Config) fiber.Handler {
 		// Add the request ID to locals
 		c.Locals(cfg.ContextKey, rid)
 
+		// Add the request ID to the user context for downstream libraries
+		c.SetUserContext(context.WithValue(c.UserContext(), ContextKey, rid))
+
 		// Continue stack
 		return c.Next()
 	}
 }
+
+// UUIDv4 generates a random RFC 4122 version 4 UUID, unlike utils.UUID
+// every ID is read from crypto/rand
+func UUIDv4() string {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[:]); err != nil {
+		return utils.UUID()
+	}
+	uuid[6] = (uuid[6] & 0x0f) | 0x40
+	uuid[8] = (uuid[8] & 0x3f) | 0x80
+
+	b := make([]byte, 36)
+	hex.Encode(b[0:8], uuid[0:4])
+	b[8] = '-'
+	hex.Encode(b[9:13], uuid[4:6])
+	b[13] = '-'
+	hex.Encode(b[14:18], uuid[6:8])
+	b[18] = '-'
+	hex.Encode(b[19:23], uuid[8:10])
+	b[23] = '-'
+	hex.Encode(b[24:], uuid[10:])
+	return utils.UnsafeString(b)
+}
+
+const base62Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// Base62 returns a generator of random IDs of length characters of [0-9A-Za-z],
+// a length of 21 is used if length is 0 or less
+func Base62(length int) func() string {
+	if length <= 0 {
+		length = 21
+	}
+	return func() string {
+		id := make([]byte, length)
+		buf := make([]byte, length+length/4)
+		for i := 0; i < length; {
+			if _, err := rand.Read(buf); err != nil {
+				return utils.UUID()
+			}
+			for _, b := range buf {
+				// Skip the bytes above the last multiple of 62 to keep the characters uniform
+				if b >= 248 {
+					continue
+				}
+				id[i] = base62Chars[b%62]
+				if i++; i == length {
+					break
+				}
+			}
+		}
+		return utils.UnsafeString(id)
+	}
+}
+
+// FromContext returns the request ID stored in the context, or an empty string
+func FromContext(ctx context.Context) string {
+	if rid, ok := ctx.Value(ContextKey).(string); ok {
+		return rid
+	}
+	return ""
+}
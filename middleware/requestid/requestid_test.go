@@ -1,7 +1,9 @@
 package requestid
 
 import (
+	"context"
 	"net/http/httptest"
+	"regexp"
 	"testing"
 
 	"github.com/gofiber/fiber/v2"
@@ -64,7 +66,7 @@ func Test_RequestID_Locals(t *testing.T) {
 
 	var ctxVal string
 
-	app.Use(func (c *fiber.Ctx) error {
+	app.Use(func(c *fiber.Ctx) error {
 		ctxVal = c.Locals(ctxKey).(string)
 		return c.Next()
 	})
@@ -72,4 +74,60 @@ func Test_RequestID_Locals(t *testing.T) {
 	_, err := app.Test(httptest.NewRequest("GET", "/", nil))
 	utils.AssertEqual(t, nil, err)
 	utils.AssertEqual(t, reqId, ctxVal)
-}
\ No newline at end of file
+}
+
+// go test -run Test_RequestID_FromContext
+func Test_RequestID_FromContext(t *testing.T) {
+	reqId := "ThisIsARequestId"
+
+	app := fiber.New()
+	app.Use(New(Config{
+		Generator: func() string {
+			return reqId
+		},
+	}))
+
+	var ctxVal string
+
+	app.Use(func(c *fiber.Ctx) error {
+		ctxVal = FromContext(c.UserContext())
+		return c.Next()
+	})
+
+	_, err := app.Test(httptest.NewRequest("GET", "/", nil))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, reqId, ctxVal)
+
+	utils.AssertEqual(t, "", FromContext(context.Background()))
+}
+
+// go test -run Test_RequestID_Generators
+func Test_RequestID_Generators(t *testing.T) {
+	uuidv4 := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	base62 := regexp.MustCompile(`^[0-9A-Za-z]+$`)
+
+	tests := []struct {
+		generator func() string
+		length    int
+		format    *regexp.Regexp
+	}{
+		{UUIDv4, 36, uuidv4},
+		{Base62(16), 16, base62},
+		{Base62(64), 64, base62},
+		{Base62(0), 21, base62},
+	}
+
+	for _, tt := range tests {
+		app := fiber.New()
+		app.Use(New(Config{Generator: tt.generator}))
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+		utils.AssertEqual(t, nil, err)
+		reqid := resp.Header.Get(fiber.HeaderXRequestID)
+		utils.AssertEqual(t, tt.length, len(reqid))
+		utils.AssertEqual(t, true, tt.format.MatchString(reqid), reqid)
+
+		// Every ID is different
+		utils.AssertEqual(t, false, tt.generator() == tt.generator())
+	}
+}
@@ -0,0 +1,53 @@
+package rewrite
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/internal/pathrule"
+)
+
+// Config defines the config for middleware.
+type Config struct {
+	// Next defines a function to skip this middleware when returned true.
+	//
+	// Optional. Default: nil
+	Next func(c *fiber.Ctx) bool
+
+	// Rules defines the URL path rewrite rules. A '*' in the key matches any
+	// characters, the matched values are available as $1, $2, ... in the value.
+	// Longer rules are tried first, only the first matching rule is applied.
+	//
+	// Required. Example:
+	// "/old":              "/new",
+	// "/api/*":            "/$1",
+	// "/js/*":             "/public/javascripts/$1",
+	// "/users/*/orders/*": "/user/$1/order/$2",
+	Rules map[string]string
+}
+
+// New creates a new middleware handler
+func New(config ...Config) fiber.Handler {
+	// Init config
+	var cfg Config
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+
+	// Compile rules, longer rules first to apply the most specific rule
+	rules := pathrule.Compile(cfg.Rules)
+
+	// Return new handler
+	return func(c *fiber.Ctx) error {
+		// Don't execute middleware if Next returns true
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		// Rewrite the path of the first matching rule, routing continues with the new path
+		if to, ok := pathrule.Match(rules, c.Path()); ok {
+			c.Path(to)
+		}
+
+		// Continue stack
+		return c.Next()
+	}
+}
@@ -0,0 +1,78 @@
+package rewrite
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// go test -run Test_Rewrite
+func Test_Rewrite(t *testing.T) {
+	app := fiber.New()
+
+	app.Use(New(Config{
+		Rules: map[string]string{
+			"/old":              "/new",
+			"/old/*":            "/new/$1",
+			"/users/*/orders/*": "/user/$1/order/$2",
+		},
+	}))
+
+	app.Get("/new", func(c *fiber.Ctx) error {
+		return c.SendString("new")
+	})
+	app.Get("/new/:id", func(c *fiber.Ctx) error {
+		return c.SendString("new " + c.Params("id"))
+	})
+	app.Get("/user/:user/order/:order", func(c *fiber.Ctx) error {
+		return c.SendString(c.Params("user") + " " + c.Params("order"))
+	})
+	app.Get("/other", func(c *fiber.Ctx) error {
+		return c.SendString("other")
+	})
+
+	testCases := []struct {
+		url  string
+		body string
+	}{
+		{"/old", "new"},
+		{"/old/123", "new 123"},
+		{"/old/123?query=1", "new 123"},
+		{"/users/john/orders/42", "john 42"},
+		// non-matching paths are passed through
+		{"/other", "other"},
+		{"/new/123", "new 123"},
+	}
+	for _, tc := range testCases {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, tc.url, nil))
+		utils.AssertEqual(t, nil, err)
+		// rewrites are transparent to the client
+		utils.AssertEqual(t, fiber.StatusOK, resp.StatusCode, tc.url)
+		body, err := ioutil.ReadAll(resp.Body)
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, tc.body, string(body), tc.url)
+	}
+}
+
+// go test -run Test_Rewrite_Next
+func Test_Rewrite_Next(t *testing.T) {
+	app := fiber.New()
+	app.Use(New(Config{
+		Next: func(_ *fiber.Ctx) bool {
+			return true
+		},
+		Rules: map[string]string{
+			"/old": "/new",
+		},
+	}))
+	app.Get("/new", func(c *fiber.Ctx) error {
+		return c.SendString("new")
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/old", nil))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, fiber.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,19 @@
+package skip
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// New wraps a handler, e.g. an existing middleware, so that it is skipped
+// when exclude returns true. A skipped handler continues with c.Next().
+func New(handler fiber.Handler, exclude func(c *fiber.Ctx) bool) fiber.Handler {
+	if exclude == nil {
+		return handler
+	}
+	return func(c *fiber.Ctx) error {
+		if exclude(c) {
+			return c.Next()
+		}
+		return handler(c)
+	}
+}
@@ -0,0 +1,64 @@
+package skip
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/basicauth"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// go test -run Test_Skip
+func Test_Skip(t *testing.T) {
+	app := fiber.New()
+	app.Use(New(basicauth.New(basicauth.Config{
+		Users: map[string]string{
+			"john": "doe",
+		},
+	}), func(c *fiber.Ctx) bool {
+		return strings.HasPrefix(c.Path(), "/public")
+	}))
+	app.Get("/public/info", func(c *fiber.Ctx) error {
+		return c.SendString("public")
+	})
+	app.Get("/private", func(c *fiber.Ctx) error {
+		return c.SendString("private")
+	})
+
+	// the authentication is skipped
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/public/info", nil))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, fiber.StatusOK, resp.StatusCode)
+	body, err := ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "public", string(body))
+
+	// the authentication is required
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/private", nil))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, fiber.StatusUnauthorized, resp.StatusCode)
+
+	req := httptest.NewRequest(fiber.MethodGet, "/private", nil)
+	req.SetBasicAuth("john", "doe")
+	resp, err = app.Test(req)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, fiber.StatusOK, resp.StatusCode)
+	body, err = ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "private", string(body))
+}
+
+// go test -run Test_Skip_Nil
+func Test_Skip_Nil(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", New(func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusTeapot)
+	}, nil))
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, fiber.StatusTeapot, resp.StatusCode)
+}
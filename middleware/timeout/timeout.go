@@ -1,43 +1,79 @@
 package timeout
 
 import (
-	"fmt"
-	"sync"
+	"context"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-var once sync.Once
+// Config defines the config for middleware.
+type Config struct {
+	// Status is the status code of the error that is returned when the timeout is reached
+	//
+	// Optional. Default: 408
+	Status int
 
-// New wraps a handler and aborts the process of the handler if the timeout is reached
-func New(handler fiber.Handler, timeout time.Duration) fiber.Handler {
-	once.Do(func() {
-		fmt.Println("[Warning] timeout contains data race issues, not ready for production!")
-	})
+	// OnTimeout renders a fallback response when the timeout is reached,
+	// e.g. a cached or default response instead of the error of Status.
+	// The UserContext of the handler is still cancelled.
+	//
+	// Optional. Default: nil
+	OnTimeout func(*fiber.Ctx) error
+}
+
+// ConfigDefault is the default config
+var ConfigDefault = Config{
+	Status: fiber.StatusRequestTimeout,
+}
 
+// New wraps a handler with a timeout. The UserContext of the handler is cancelled
+// when the timeout is reached, so long running operations using it are stopped.
+// The handler runs in the goroutine of the request and must return once its
+// UserContext is done, the timeout error is returned after it has returned.
+func New(handler fiber.Handler, timeout time.Duration, config ...Config) fiber.Handler {
 	if timeout <= 0 {
 		return handler
 	}
 
-	// logic is from fasthttp.TimeoutWithCodeHandler https://github.com/valyala/fasthttp/blob/master/server.go#L418
+	// Set default config
+	cfg := ConfigDefault
+
+	// Override config if provided
+	if len(config) > 0 {
+		cfg = config[0]
+
+		// Set default values
+		if cfg.Status == 0 {
+			cfg.Status = ConfigDefault.Status
+		}
+	}
+
 	return func(ctx *fiber.Ctx) error {
-		ch := make(chan struct{}, 1)
-
-		go func() {
-			defer func() {
-				_ = recover()
-			}()
-			_ = handler(ctx)
-			ch <- struct{}{}
-		}()
-
-		select {
-		case <-ch:
-		case <-time.After(timeout):
+		// Cancel the context of the handler when the timeout is reached
+		parent := ctx.UserContext()
+		timeoutContext, cancel := context.WithTimeout(parent, timeout)
+		defer cancel()
+		ctx.SetUserContext(timeoutContext)
+
+		// The handler is not run in a separate goroutine, so it never uses
+		// the pooled ctx after the request has been released
+		err := handler(ctx)
+		ctx.SetUserContext(parent)
+
+		// Return the error of the handler to the calling middleware
+		if timeoutContext.Err() != context.DeadlineExceeded {
+			return err
+		}
+		if cfg.OnTimeout != nil {
+			// Discard the partial response of the handler
+			ctx.Response().ResetBody()
+			ctx.Status(fiber.StatusOK)
+			return cfg.OnTimeout(ctx)
+		}
+		if cfg.Status == fiber.StatusRequestTimeout {
 			return fiber.ErrRequestTimeout
 		}
-
-		return nil
+		return fiber.NewError(cfg.Status)
 	}
 }
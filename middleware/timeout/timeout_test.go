@@ -1,5 +1,16 @@
 package timeout
 
+import (
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
 // // go test -run Test_Middleware_Timeout
 // func Test_Middleware_Timeout(t *testing.T) {
 // 	app := fiber.New(fiber.Config{DisableStartupMessage: true})
@@ -53,3 +64,152 @@ package timeout
 // 	utils.AssertEqual(t, nil, err)
 // 	utils.AssertEqual(t, "Request Timeout", string(body))
 // }
+
+// go test -run Test_Timeout_Error
+func Test_Timeout_Error(t *testing.T) {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+
+	app.Get("/error", func(c *fiber.Ctx) error {
+		err := c.Next()
+		utils.AssertEqual(t, fiber.ErrForbidden, err)
+		return err
+	}, New(func(c *fiber.Ctx) error {
+		return fiber.ErrForbidden
+	}, time.Second))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/error", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, fiber.StatusForbidden, resp.StatusCode, "Status code")
+}
+
+// go test -run Test_Timeout_Route
+func Test_Timeout_Route(t *testing.T) {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+
+	// the previous user context is restored after the timeout
+	app.Use(func(c *fiber.Ctx) error {
+		parent := c.UserContext()
+		err := c.Next()
+		utils.AssertEqual(t, true, parent == c.UserContext())
+		return err
+	})
+
+	cancelled := make(chan error, 1)
+	app.Get("/slow", New(func(c *fiber.Ctx) error {
+		ctx := c.UserContext()
+		select {
+		case <-ctx.Done():
+			cancelled <- ctx.Err()
+			return ctx.Err()
+		case <-time.After(time.Second):
+			return c.SendString("too late")
+		}
+	}, 10*time.Millisecond))
+	app.Get("/fast", func(c *fiber.Ctx) error {
+		return c.SendString("fast")
+	})
+	app.Get("/custom", New(func(c *fiber.Ctx) error {
+		<-c.UserContext().Done()
+		return nil
+	}, 10*time.Millisecond, Config{Status: fiber.StatusServiceUnavailable}))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/slow", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, fiber.StatusRequestTimeout, resp.StatusCode, "Status code")
+	utils.AssertEqual(t, context.DeadlineExceeded, <-cancelled)
+
+	// other routes are not affected by the timeout
+	resp, err = app.Test(httptest.NewRequest("GET", "/fast", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, fiber.StatusOK, resp.StatusCode, "Status code")
+	body, err := ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "fast", string(body))
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/custom", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, fiber.StatusServiceUnavailable, resp.StatusCode, "Status code")
+}
+
+// go test -run Test_Timeout_OnTimeout
+func Test_Timeout_OnTimeout(t *testing.T) {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+
+	cancelled := make(chan error, 1)
+	app.Get("/", New(func(c *fiber.Ctx) error {
+		// the partial response is discarded
+		c.Status(fiber.StatusInternalServerError).SendString("partial")
+		<-c.UserContext().Done()
+		cancelled <- c.UserContext().Err()
+		return nil
+	}, 10*time.Millisecond, Config{
+		OnTimeout: func(c *fiber.Ctx) error {
+			return c.SendString("stub")
+		},
+	}))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, fiber.StatusOK, resp.StatusCode, "Status code")
+	body, err := ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "stub", string(body))
+	utils.AssertEqual(t, context.DeadlineExceeded, <-cancelled)
+}
+
+// fakeDB simulates a database/sql query that respects the cancellation of its context
+type fakeDB struct {
+	latency time.Duration
+}
+
+func (db fakeDB) QueryContext(ctx context.Context, query string) (string, error) {
+	select {
+	case <-time.After(db.latency):
+		return "rows of " + query, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// go test -run Test_Timeout_QueryContext
+func Test_Timeout_QueryContext(t *testing.T) {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+
+	db := fakeDB{latency: time.Second}
+	queryErr := make(chan error, 1)
+	app.Get("/slow", New(func(c *fiber.Ctx) error {
+		// the deadline of the timeout is set on the UserContext
+		deadline, ok := c.UserContext().Deadline()
+		utils.AssertEqual(t, true, ok)
+		utils.AssertEqual(t, true, time.Until(deadline) <= 20*time.Millisecond)
+
+		rows, err := db.QueryContext(c.UserContext(), "SELECT 1")
+		queryErr <- err
+		if err != nil {
+			return err
+		}
+		return c.SendString(rows)
+	}, 20*time.Millisecond))
+	app.Get("/fast", New(func(c *fiber.Ctx) error {
+		rows, err := fakeDB{}.QueryContext(c.UserContext(), "SELECT 1")
+		if err != nil {
+			return err
+		}
+		return c.SendString(rows)
+	}, time.Second))
+
+	// the query is cancelled and returns promptly
+	start := time.Now()
+	resp, err := app.Test(httptest.NewRequest("GET", "/slow", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, fiber.StatusRequestTimeout, resp.StatusCode, "Status code")
+	utils.AssertEqual(t, context.DeadlineExceeded, <-queryErr)
+	utils.AssertEqual(t, true, time.Since(start) < 500*time.Millisecond, time.Since(start).String())
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/fast", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, fiber.StatusOK, resp.StatusCode, "Status code")
+	body, err := ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "rows of SELECT 1", string(body))
+}
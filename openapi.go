@@ -0,0 +1,206 @@
+// ⚡️ Fiber is an Express inspired web framework written in Go with ☕️
+// 🤖 Github Repository: https://github.com/gofiber/fiber
+// 📌 API Documentation: https://docs.gofiber.io
+
+package fiber
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2/internal/encoding/json"
+)
+
+// OpenAPI specification: https://spec.openapis.org/oas/v3.0.3
+const openAPIVersion = "3.0.3"
+
+// OpenAPISpec describes the operation of a route in the document of app.OpenAPI
+type OpenAPISpec struct {
+	Summary     string
+	Description string
+	OperationID string
+	Tags        []string
+	Deprecated  bool
+	// Responses maps status codes like "200" to their description
+	Responses map[string]string
+}
+
+type openAPIDocument struct {
+	OpenAPI string                                  `json:"openapi"`
+	Info    openAPIInfo                             `json:"info"`
+	Paths   map[string]map[string]*openAPIOperation `json:"paths"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIOperation struct {
+	Summary     string                     `json:"summary,omitempty"`
+	Description string                     `json:"description,omitempty"`
+	OperationID string                     `json:"operationId,omitempty"`
+	Tags        []string                   `json:"tags,omitempty"`
+	Deprecated  bool                       `json:"deprecated,omitempty"`
+	Parameters  []openAPIParameter         `json:"parameters,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name     string        `json:"name"`
+	In       string        `json:"in"`
+	Required bool          `json:"required"`
+	Schema   openAPISchema `json:"schema"`
+}
+
+type openAPISchema struct {
+	Type   string `json:"type"`
+	Format string `json:"format,omitempty"`
+}
+
+type openAPIResponse struct {
+	Description string `json:"description"`
+}
+
+// Describe sets the OpenAPI description of the routes of the latest registration,
+// only described routes are part of the document of app.OpenAPI.
+// The HEAD routes of GET routes are not described.
+//  app.Get("/users/:id", handler).Describe(fiber.OpenAPISpec{
+//    Summary:   "Get a user",
+//    Responses: map[string]string{"200": "The user"},
+//  })
+func (app *App) Describe(spec OpenAPISpec) Router {
+	for _, route := range app.latestRoutes {
+		if route.autoHead {
+			continue
+		}
+		route.openAPI = &spec
+	}
+	return app
+}
+
+// OpenAPI returns a minimal OpenAPI document in JSON of the described routes.
+// Path parameters are listed with the types of their constraints, routes with
+// optional parameters are documented with a path for every combination.
+func (app *App) OpenAPI() []byte {
+	doc := openAPIDocument{
+		OpenAPI: openAPIVersion,
+		Info:    openAPIInfo{Title: "Fiber", Version: "1.0.0"},
+		Paths:   make(map[string]map[string]*openAPIOperation),
+	}
+	if app.config.ServerHeader != "" {
+		doc.Info.Title = app.config.ServerHeader
+	}
+	stack := app.Stack()
+	for m := range stack {
+		for _, route := range stack[m] {
+			if route.openAPI == nil || route.autoHead {
+				continue
+			}
+			for i, variant := range openAPIPaths(route.Path) {
+				if doc.Paths[variant.path] == nil {
+					doc.Paths[variant.path] = make(map[string]*openAPIOperation)
+				}
+				op := newOpenAPIOperation(route.openAPI, variant.params)
+				// Operation ids must be unique, only the path of all parameters has it
+				if i > 0 {
+					op.OperationID = ""
+				}
+				doc.Paths[variant.path][strings.ToLower(route.Method)] = op
+			}
+		}
+	}
+	// Marshaling the document of known types does not fail
+	raw, _ := json.Marshal(doc)
+	return raw
+}
+
+// newOpenAPIOperation creates the operation of a route description
+func newOpenAPIOperation(spec *OpenAPISpec, params []openAPIParameter) *openAPIOperation {
+	op := &openAPIOperation{
+		Summary:     spec.Summary,
+		Description: spec.Description,
+		OperationID: spec.OperationID,
+		Tags:        spec.Tags,
+		Deprecated:  spec.Deprecated,
+		Parameters:  params,
+		Responses:   make(map[string]openAPIResponse, len(spec.Responses)),
+	}
+	for code, description := range spec.Responses {
+		op.Responses[code] = openAPIResponse{Description: description}
+	}
+	// Every operation needs at least one response
+	if len(op.Responses) == 0 {
+		op.Responses["default"] = openAPIResponse{Description: "Default response"}
+	}
+	return op
+}
+
+// openAPIPathVariant is an OpenAPI path of a route with its parameters
+type openAPIPathVariant struct {
+	path   string
+	params []openAPIParameter
+}
+
+// openAPIPaths converts the route path to OpenAPI paths with their parameters,
+// e.g. "/users/:id<int>" to "/users/{id}". OpenAPI path parameters are always
+// required, so there is a path for every combination of the optional parameters,
+// starting with the path of all parameters. Wildcards are named "wildcard1" and
+// plus parameters "plus1".
+func openAPIPaths(routePath string) []openAPIPathVariant {
+	variants := []openAPIPathVariant{{}}
+	segs := parseRoute(routePath).segs
+	for i, seg := range segs {
+		if !seg.IsParam {
+			for j := range variants {
+				variants[j].path += seg.Const
+			}
+			continue
+		}
+		param := newOpenAPIParameter(seg)
+		next := make([]openAPIPathVariant, 0, 2*len(variants))
+		for _, variant := range variants {
+			next = append(next, openAPIPathVariant{
+				path:   variant.path + "{" + param.Name + "}",
+				params: append(append([]openAPIParameter(nil), variant.params...), param),
+			})
+			if !seg.IsOptional {
+				continue
+			}
+			// The slash in front of an omitted optional parameter is optional as well
+			if i > 0 && segs[i-1].HasOptionalSlash && len(variant.path) > 1 && variant.path[len(variant.path)-1] == '/' {
+				variant.path = variant.path[:len(variant.path)-1]
+			}
+			next = append(next, variant)
+		}
+		variants = next
+	}
+	return variants
+}
+
+// newOpenAPIParameter creates the required path parameter of a route segment
+func newOpenAPIParameter(seg *routeSegment) openAPIParameter {
+	name := seg.ParamName
+	switch name[0] {
+	case wildcardParam:
+		name = "wildcard" + name[1:]
+	case plusParam:
+		name = "plus" + name[1:]
+	}
+	param := openAPIParameter{
+		Name:     name,
+		In:       "path",
+		Required: true,
+		Schema:   openAPISchema{Type: "string"},
+	}
+	for _, constraint := range seg.Constraints {
+		switch constraint.Type {
+		case intConstraint:
+			param.Schema = openAPISchema{Type: "integer"}
+		case boolConstraint:
+			param.Schema = openAPISchema{Type: "boolean"}
+		case uuidConstraint:
+			param.Schema = openAPISchema{Type: "string", Format: "uuid"}
+		}
+	}
+	return param
+}
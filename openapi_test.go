@@ -0,0 +1,77 @@
+// ⚡️ Fiber is an Express inspired web framework written in Go with ☕️
+// 🤖 Github Repository: https://github.com/gofiber/fiber
+// 📌 API Documentation: https://docs.gofiber.io
+
+package fiber
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// go test -run Test_App_OpenAPI
+func Test_App_OpenAPI(t *testing.T) {
+	app := New()
+
+	app.Use(testEmptyHandler)
+	app.Get("/users/:id<int>", testEmptyHandler).Describe(OpenAPISpec{
+		Summary:     "Get a user",
+		OperationID: "getUser",
+		Tags:        []string{"users"},
+		Responses:   map[string]string{"200": "The user", "404": "Unknown user"},
+	})
+	api := app.Group("/api")
+	api.Post("/items/:name/:version?", testEmptyHandler).Describe(OpenAPISpec{
+		Description: "Create an item",
+	})
+	// routes without description are not documented
+	app.Get("/internal", testEmptyHandler)
+
+	utils.AssertEqual(t, `{"openapi":"3.0.3","info":{"title":"Fiber","version":"1.0.0"},"paths":{`+
+		`"/api/items/{name}":{"post":{"description":"Create an item","parameters":[`+
+		`{"name":"name","in":"path","required":true,"schema":{"type":"string"}}],`+
+		`"responses":{"default":{"description":"Default response"}}}},`+
+		`"/api/items/{name}/{version}":{"post":{"description":"Create an item","parameters":[`+
+		`{"name":"name","in":"path","required":true,"schema":{"type":"string"}},`+
+		`{"name":"version","in":"path","required":true,"schema":{"type":"string"}}],`+
+		`"responses":{"default":{"description":"Default response"}}}},`+
+		`"/users/{id}":{"get":{"summary":"Get a user","operationId":"getUser","tags":["users"],"parameters":[`+
+		`{"name":"id","in":"path","required":true,"schema":{"type":"integer"}}],`+
+		`"responses":{"200":{"description":"The user"},"404":{"description":"Unknown user"}}}}}}`, string(app.OpenAPI()))
+
+	// an empty document without descriptions
+	utils.AssertEqual(t, `{"openapi":"3.0.3","info":{"title":"Fiber","version":"1.0.0"},"paths":{}}`, string(New().OpenAPI()))
+
+	// the routes of mounted sub-apps are documented with the mount prefix
+	sub := New()
+	sub.Get("/ping", testEmptyHandler).Describe(OpenAPISpec{Summary: "Ping"})
+	app = New()
+	app.Mount("/sub", sub)
+	utils.AssertEqual(t, `{"openapi":"3.0.3","info":{"title":"Fiber","version":"1.0.0"},"paths":{`+
+		`"/sub/ping":{"get":{"summary":"Ping","responses":{"default":{"description":"Default response"}}}}}}`, string(app.OpenAPI()))
+
+	// an explicit HEAD route on the path of a GET route is documented
+	app = New()
+	app.Get("/ping", testEmptyHandler).Describe(OpenAPISpec{Summary: "Ping"})
+	app.Head("/ping", testEmptyHandler).Describe(OpenAPISpec{Summary: "Check"})
+	utils.AssertEqual(t, `{"openapi":"3.0.3","info":{"title":"Fiber","version":"1.0.0"},"paths":{`+
+		`"/ping":{"get":{"summary":"Ping","responses":{"default":{"description":"Default response"}}},`+
+		`"head":{"summary":"Check","responses":{"default":{"description":"Default response"}}}}}}`, string(app.OpenAPI()))
+
+	// optional parameters and wildcards are documented with a path for every variant
+	app = New()
+	app.Get("/files/:dir?/*", testEmptyHandler).Describe(OpenAPISpec{OperationID: "getFile"})
+	utils.AssertEqual(t, `{"openapi":"3.0.3","info":{"title":"Fiber","version":"1.0.0"},"paths":{`+
+		`"/files":{"get":{"responses":{"default":{"description":"Default response"}}}},`+
+		`"/files/{dir}":{"get":{"parameters":[`+
+		`{"name":"dir","in":"path","required":true,"schema":{"type":"string"}}],`+
+		`"responses":{"default":{"description":"Default response"}}}},`+
+		`"/files/{dir}/{wildcard1}":{"get":{"operationId":"getFile","parameters":[`+
+		`{"name":"dir","in":"path","required":true,"schema":{"type":"string"}},`+
+		`{"name":"wildcard1","in":"path","required":true,"schema":{"type":"string"}}],`+
+		`"responses":{"default":{"description":"Default response"}}}},`+
+		`"/files/{wildcard1}":{"get":{"parameters":[`+
+		`{"name":"wildcard1","in":"path","required":true,"schema":{"type":"string"}}],`+
+		`"responses":{"default":{"description":"Default response"}}}}}}`, string(app.OpenAPI()))
+}
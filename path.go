@@ -7,8 +7,11 @@
 package fiber
 
 import (
+	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2/utils"
 )
@@ -26,12 +29,13 @@ type routeSegment struct {
 	// const information
 	Const string // constant part of the route
 	// parameter information
-	IsParam     bool   // Truth value that indicates whether it is a parameter or a constant part
-	ParamName   string // name of the parameter for access to it, for wildcards and plus parameters access iterators starting with 1 are added
-	ComparePart string // search part to find the end of the parameter
-	PartCount   int    // how often is the search part contained in the non-param segments? -> necessary for greedy search
-	IsGreedy    bool   // indicates whether the parameter is greedy or not, is used with wildcard and plus
-	IsOptional  bool   // indicates whether the parameter is optional or not
+	IsParam     bool               // Truth value that indicates whether it is a parameter or a constant part
+	ParamName   string             // name of the parameter for access to it, for wildcards and plus parameters access iterators starting with 1 are added
+	ComparePart string             // search part to find the end of the parameter
+	PartCount   int                // how often is the search part contained in the non-param segments? -> necessary for greedy search
+	IsGreedy    bool               // indicates whether the parameter is greedy or not, is used with wildcard and plus
+	IsOptional  bool               // indicates whether the parameter is optional or not
+	Constraints []*routeConstraint // constraints the parameter value must fulfill, e.g. :id<int>
 	// common information
 	IsLast           bool // shows if the segment is the last one for the route
 	HasOptionalSlash bool // segment has the possibility of an optional slash
@@ -46,8 +50,37 @@ const (
 	optionalParam    byte = '?' // concludes a parameter by name and makes it optional
 	paramStarterChar byte = ':' // start character for a parameter with name
 	slashDelimiter   byte = '/' // separator for the route, unlike the other delimiters this character at the end can be optional
+	constraintStart  byte = '<' // start of the parameter constraints, e.g. :id<int>
+	constraintEnd    byte = '>' // end of the parameter constraints
+	constraintSep    byte = ';' // separator for multiple parameter constraints, e.g. :id<int;min(1)>
 )
 
+// constraintType is the identifier of a parameter constraint
+type constraintType int
+
+// supported parameter constraints
+const (
+	intConstraint      constraintType = iota // int
+	boolConstraint                           // bool
+	alphaConstraint                          // alpha
+	uuidConstraint                           // uuid
+	datetimeConstraint                       // datetime(layout)
+	minConstraint                            // min(n)
+	maxConstraint                            // max(n)
+	regexConstraint                          // regex(expression), matching the whole value
+)
+
+// defaultDatetimeLayout is used for the datetime constraint if no layout is given
+const defaultDatetimeLayout = "2006-01-02"
+
+// routeConstraint holds a parsed parameter constraint
+type routeConstraint struct {
+	Type  constraintType
+	Data  string         // argument of the constraint, e.g. the layout for datetime
+	Num   int            // numeric argument for min and max
+	Regex *regexp.Regexp // compiled expression for regex
+}
+
 // list of possible parameter and segment delimiter
 var (
 	// slash has a special role, unlike the other parameters it must not be interpreted as a parameter
@@ -175,6 +208,25 @@ func (routeParser *routeParser) analyseParameterPart(pattern string) (string, *r
 	isPlusParam := pattern[0] == plusParam
 	parameterEndPosition := findNextCharsetPosition(pattern[1:], parameterEndChars)
 
+	// handle parameters with constraints, e.g. :id<int> or :id<int>?
+	if !isWildCard && !isPlusParam {
+		if start := strings.IndexByte(pattern, constraintStart); start != -1 && (parameterEndPosition == -1 || start <= parameterEndPosition+1) {
+			if end := findConstraintEnd(pattern, start); end != -1 {
+				processedPart := pattern[:end+1]
+				isOptional := len(pattern) > end+1 && pattern[end+1] == optionalParam
+				if isOptional {
+					processedPart = pattern[:end+2]
+				}
+				return processedPart, &routeSegment{
+					ParamName:   pattern[1:start],
+					IsParam:     true,
+					IsOptional:  isOptional,
+					Constraints: parseConstraints(pattern[start+1 : end]),
+				}
+			}
+		}
+	}
+
 	// handle wildcard end
 	if isWildCard || isPlusParam {
 		parameterEndPosition = 0
@@ -204,6 +256,176 @@ func (routeParser *routeParser) analyseParameterPart(pattern string) (string, *r
 	}
 }
 
+// findConstraintEnd returns the position of the character that closes the constraints
+// starting at the given position, characters within parentheses are skipped
+func findConstraintEnd(pattern string, start int) int {
+	depth := 0
+	for i := start + 1; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case constraintEnd:
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// parseConstraints parses the constraints of a parameter, e.g. "int;min(1)"
+func parseConstraints(raw string) []*routeConstraint {
+	var constraints []*routeConstraint
+	for len(raw) > 0 {
+		// find the next separator outside of parentheses
+		end, depth := len(raw), 0
+		for i := 0; i < len(raw); i++ {
+			if raw[i] == '(' {
+				depth++
+			} else if raw[i] == ')' && depth > 0 {
+				depth--
+			} else if raw[i] == constraintSep && depth == 0 {
+				end = i
+				break
+			}
+		}
+		if part := utils.Trim(raw[:end], ' '); part != "" {
+			constraints = append(constraints, parseConstraint(part))
+		}
+		if end == len(raw) {
+			break
+		}
+		raw = raw[end+1:]
+	}
+	return constraints
+}
+
+// parseConstraint parses a single constraint, e.g. "min(1)"
+func parseConstraint(raw string) *routeConstraint {
+	name, data := raw, ""
+	if start := strings.IndexByte(raw, '('); start != -1 && raw[len(raw)-1] == ')' {
+		name, data = raw[:start], raw[start+1:len(raw)-1]
+	}
+	constraint := &routeConstraint{Data: data}
+	switch name {
+	case "int":
+		constraint.Type = intConstraint
+	case "bool":
+		constraint.Type = boolConstraint
+	case "alpha":
+		constraint.Type = alphaConstraint
+	case "uuid":
+		constraint.Type = uuidConstraint
+	case "datetime":
+		constraint.Type = datetimeConstraint
+		if constraint.Data == "" {
+			constraint.Data = defaultDatetimeLayout
+		}
+	case "min", "max":
+		constraint.Type = minConstraint
+		if name == "max" {
+			constraint.Type = maxConstraint
+		}
+		num, err := strconv.Atoi(data)
+		if err != nil {
+			panic(fmt.Sprintf("route: invalid argument for constraint %s\n", raw))
+		}
+		constraint.Num = num
+	case "regex":
+		constraint.Type = regexConstraint
+		// The expression has to match the whole parameter value
+		constraint.Regex = regexp.MustCompile("^(?:" + data + ")$")
+	default:
+		panic(fmt.Sprintf("route: unknown constraint %s\n", raw))
+	}
+	return constraint
+}
+
+// check reports whether the parameter value fulfills the constraint
+func (c *routeConstraint) check(param string) bool {
+	switch c.Type {
+	case intConstraint:
+		_, err := strconv.Atoi(param)
+		return err == nil
+	case boolConstraint:
+		_, err := strconv.ParseBool(param)
+		return err == nil
+	case alphaConstraint:
+		for i := 0; i < len(param); i++ {
+			if (param[i] < 'a' || param[i] > 'z') && (param[i] < 'A' || param[i] > 'Z') {
+				return false
+			}
+		}
+		return true
+	case uuidConstraint:
+		return isUUID(param)
+	case datetimeConstraint:
+		_, err := time.Parse(c.Data, param)
+		return err == nil
+	case minConstraint:
+		num, err := strconv.Atoi(param)
+		return err == nil && num >= c.Num
+	case maxConstraint:
+		num, err := strconv.Atoi(param)
+		return err == nil && num <= c.Num
+	case regexConstraint:
+		return c.Regex.MatchString(param)
+	}
+	return false
+}
+
+// isUUID checks if the given string has the canonical uuid form xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			if (s[i] < '0' || s[i] > '9') && (s[i] < 'a' || s[i] > 'f') && (s[i] < 'A' || s[i] > 'F') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// toLowerRoute lowercases the route for the case insensitive routing,
+// parameter constraints are kept as they are, e.g. :name<regex([A-Z]+)>
+func toLowerRoute(route string) string {
+	start := strings.IndexByte(route, constraintStart)
+	if start == -1 {
+		return utils.ToLower(route)
+	}
+	end := findConstraintEnd(route, start)
+	if end == -1 {
+		return utils.ToLower(route)
+	}
+	return utils.ToLower(route[:start]) + route[start:end+1] + toLowerRoute(route[end+1:])
+}
+
+// checkConstraints reports whether the parameter value fulfills all constraints of the segment
+func (segment *routeSegment) checkConstraints(param string) bool {
+	// empty optional parameters are not checked
+	if param == "" && segment.IsOptional {
+		return true
+	}
+	for _, constraint := range segment.Constraints {
+		if !constraint.check(param) {
+			return false
+		}
+	}
+	return true
+}
+
 // isInCharset check is the given character in the charset list
 func isInCharset(searchChar byte, charset []byte) bool {
 	for _, char := range charset {
@@ -247,6 +469,10 @@ func (routeParser *routeParser) getMatch(s, original string, params *[maxParams]
 			if !segment.IsOptional && i == 0 {
 				return false
 			}
+			// check the parameter constraints against the original value
+			if len(segment.Constraints) > 0 && !segment.checkConstraints(original[:i]) {
+				return false
+			}
 			// take over the params positions
 			params[paramsIterator] = original[:i]
 			paramsIterator++
@@ -386,6 +386,102 @@ func Test_Path_matchParams(t *testing.T) {
 	})
 }
 
+// go test -race -run Test_Path_parseRoute_Constraints
+func Test_Path_parseRoute_Constraints(t *testing.T) {
+	t.Parallel()
+	rp := parseRoute("/api/:id<int;min(1)>/:date<datetime(2006-01-02)>?")
+	utils.AssertEqual(t, []string{"id", "date"}, rp.params)
+	utils.AssertEqual(t, 4, len(rp.segs))
+	utils.AssertEqual(t, "/api/", rp.segs[0].Const)
+	utils.AssertEqual(t, []*routeConstraint{{Type: intConstraint}, {Type: minConstraint, Data: "1", Num: 1}}, rp.segs[1].Constraints)
+	utils.AssertEqual(t, "/", rp.segs[2].Const)
+	utils.AssertEqual(t, true, rp.segs[3].IsOptional)
+	utils.AssertEqual(t, []*routeConstraint{{Type: datetimeConstraint, Data: "2006-01-02"}}, rp.segs[3].Constraints)
+
+	rp = parseRoute("/:name<regex(^[a-z]{2,3}$)>.json")
+	utils.AssertEqual(t, []string{"name"}, rp.params)
+	utils.AssertEqual(t, ".json", rp.segs[2].Const)
+	utils.AssertEqual(t, regexConstraint, rp.segs[1].Constraints[0].Type)
+
+	utils.AssertEqual(t, "/users/:name<regex([A-Z]+)>/abc", toLowerRoute("/Users/:name<regex([A-Z]+)>/ABC"))
+
+	defer func() {
+		utils.AssertEqual(t, "route: unknown constraint number\n", recover())
+	}()
+	parseRoute("/:id<number>")
+}
+
+// go test -race -run Test_Path_matchParams_Constraints
+func Test_Path_matchParams_Constraints(t *testing.T) {
+	t.Parallel()
+	var ctxParams [maxParams]string
+	testCase := func(r string, cases map[string]bool) {
+		parser := parseRoute(r)
+		for url, match := range cases {
+			utils.AssertEqual(t, match, parser.getMatch(url, url, &ctxParams, false), fmt.Sprintf("route: '%s', url: '%s'", r, url))
+		}
+	}
+	testCase("/users/:id<int>", map[string]bool{
+		"/users/12":  true,
+		"/users/-12": true,
+		"/users/abc": false,
+		"/users/":    false,
+	})
+	testCase("/users/:id<int>?", map[string]bool{
+		"/users/12":  true,
+		"/users":     true,
+		"/users/abc": false,
+	})
+	testCase("/:value<alpha>", map[string]bool{
+		"/abcXYZ": true,
+		"/abc1":   false,
+	})
+	testCase("/:value<bool>", map[string]bool{
+		"/true": true,
+		"/0":    true,
+		"/yes":  false,
+	})
+	testCase("/:value<uuid>", map[string]bool{
+		"/0e0d7f4e-4f4b-4b8a-9a3e-8a3e5e9c2d1f": true,
+		"/0e0d7f4e-4f4b-4b8a-9a3e-8a3e5e9c2d1":  false,
+		"/0e0d7f4e04f4b-4b8a-9a3e-8a3e5e9c2d1f": false,
+		"/0e0d7f4e-4f4b-4b8a-9a3e-8a3e5e9c2d1g": false,
+	})
+	testCase("/:value<datetime>", map[string]bool{
+		"/2020-10-27": true,
+		"/2020-13-27": false,
+	})
+	testCase("/:value<datetime(15.04)>", map[string]bool{
+		"/13.37": true,
+		"/25.00": false,
+	})
+	testCase("/:value<int;min(5);max(10)>", map[string]bool{
+		"/4":  false,
+		"/5":  true,
+		"/10": true,
+		"/11": false,
+		"/a":  false,
+	})
+	testCase("/:value<regex(^\\d{3}-\\d{2}$)>", map[string]bool{
+		"/123-45":  true,
+		"/1234-5":  false,
+		"/abc-de":  false,
+		"/123-456": false,
+	})
+	testCase("/files/:name<alpha>.:ext<regex(^(json|xml)$)>", map[string]bool{
+		"/files/data.json": true,
+		"/files/data.xml":  true,
+		"/files/data.txt":  false,
+		"/files/d4ta.json": false,
+	})
+	// the expression has to match the whole value
+	testCase("/:id<regex(\\d+)>", map[string]bool{
+		"/123":  true,
+		"/abc1": false,
+		"/1abc": false,
+	})
+}
+
 func Test_Utils_GetTrimmedParam(t *testing.T) {
 	t.Parallel()
 	res := GetTrimmedParam("*")
@@ -6,9 +6,12 @@ import (
 	"net"
 	"os"
 	"os/exec"
+	"os/signal"
 	"runtime"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/valyala/fasthttp/reuseport"
@@ -43,6 +46,10 @@ func (app *App) prefork(addr string, tlsConfig *tls.Config) (err error) {
 			}
 			return fmt.Errorf("prefork: %v", err)
 		}
+		// the PROXY protocol header is sent before the tls handshake
+		if app.config.EnableProxyProtocol {
+			ln = newProxyProtocolListener(ln)
+		}
 		// wrap a tls config around the listener if provided
 		if tlsConfig != nil {
 			ln = tls.NewListener(ln, tlsConfig)
@@ -51,11 +58,20 @@ func (app *App) prefork(addr string, tlsConfig *tls.Config) (err error) {
 		// kill current child proc when master exits
 		go watchMaster()
 
+		// shutdown gracefully when the master shuts down
+		done := make(chan struct{})
+		defer close(done)
+		go watchShutdown(app, done)
+
 		// listen for incoming connections
 		return app.server.Serve(ln)
 	}
 
 	// 👮 master process 👮
+	// every child would listen on a different random port
+	if _, port, err := net.SplitHostPort(addr); err == nil && (port == "" || port == "0") {
+		return fmt.Errorf("prefork: a random port can't be used with prefork, got %q", addr)
+	}
 	type child struct {
 		pid int
 		err error
@@ -67,6 +83,9 @@ func (app *App) prefork(addr string, tlsConfig *tls.Config) (err error) {
 
 	// kill child procs when master exits
 	defer func() {
+		app.mutex.Lock()
+		app.childs = nil
+		app.mutex.Unlock()
 		for _, proc := range childs {
 			_ = proc.Process.Kill()
 		}
@@ -99,6 +118,13 @@ func (app *App) prefork(addr string, tlsConfig *tls.Config) (err error) {
 		// store child process
 		pid := cmd.Process.Pid
 		childs[pid] = cmd
+		app.mutex.Lock()
+		app.childs = append(app.childs, cmd.Process)
+		// app.Shutdown was called while the childs are started
+		if atomic.LoadInt32(&app.draining) == 1 {
+			signalShutdown(cmd.Process)
+		}
+		app.mutex.Unlock()
 		pids = append(pids, strconv.Itoa(pid))
 
 		// notify master if child crashes
@@ -113,7 +139,35 @@ func (app *App) prefork(addr string, tlsConfig *tls.Config) (err error) {
 	}
 
 	// return error if child crashes
-	return (<-channel).err
+	err = (<-channel).err
+	if atomic.LoadInt32(&app.draining) == 1 {
+		// wait until all childs are shut down by app.Shutdown
+		for i := 1; i < max; i++ {
+			<-channel
+		}
+		return nil
+	}
+	return err
+}
+
+// signalShutdown asks a child process to shutdown gracefully,
+// it is killed if signals are not supported like on windows
+func signalShutdown(proc *os.Process) {
+	if err := proc.Signal(syscall.SIGTERM); err != nil {
+		_ = proc.Kill()
+	}
+}
+
+// watchShutdown shuts the child proc down gracefully on SIGTERM of the master
+func watchShutdown(app *App, done <-chan struct{}) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	select {
+	case <-signals:
+		_ = app.Shutdown()
+	case <-done:
+	}
 }
 
 // watchMaster watches child procs
@@ -8,6 +8,7 @@ import (
 	"crypto/tls"
 	"io/ioutil"
 	"os"
+	"runtime"
 	"testing"
 	"time"
 
@@ -63,10 +64,54 @@ func Test_App_Prefork_Master_Process(t *testing.T) {
 
 	dummyChildCmd = "invalid"
 
-	err := app.prefork("127.0.0.1:", nil)
+	err := app.prefork("127.0.0.1:3000", nil)
 	utils.AssertEqual(t, false, err == nil)
 }
 
+// go test -run Test_App_Prefork_Random_Port
+func Test_App_Prefork_Random_Port(t *testing.T) {
+	app := New()
+
+	err := app.prefork(":0", nil)
+	utils.AssertEqual(t, `prefork: a random port can't be used with prefork, got ":0"`, err.Error())
+
+	err = app.prefork("127.0.0.1:", nil)
+	utils.AssertEqual(t, `prefork: a random port can't be used with prefork, got "127.0.0.1:"`, err.Error())
+}
+
+// go test -run Test_App_Prefork_Shutdown
+func Test_App_Prefork_Shutdown(t *testing.T) {
+	app := New(Config{DisableStartupMessage: true})
+
+	// The childs run this test binary and serve until the master shuts down
+	if IsChild() {
+		utils.AssertEqual(t, nil, app.prefork("127.0.0.1:3011", nil))
+		return
+	}
+
+	testPreforkMaster = false
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(2))
+	args := os.Args
+	os.Args = []string{args[0], "-test.run=^Test_App_Prefork_Shutdown$"}
+	defer func() { os.Args = args }()
+
+	go func() {
+		time.Sleep(2 * time.Second)
+		utils.AssertEqual(t, nil, app.Shutdown())
+	}()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- app.prefork("127.0.0.1:3011", nil)
+	}()
+	select {
+	case err := <-done:
+		utils.AssertEqual(t, nil, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("prefork: shutdown of the childs timed out")
+	}
+}
+
 func Test_App_Prefork_Child_Process_Never_Show_Startup_Message(t *testing.T) {
 	setupIsChild(t)
 	defer teardownIsChild(t)
@@ -0,0 +1,201 @@
+// ⚡️ Fiber is an Express inspired web framework written in Go with ☕️
+// 🤖 Github Repository: https://github.com/gofiber/fiber
+// 📌 API Documentation: https://docs.gofiber.io
+
+package fiber
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// PROXY protocol specification: https://www.haproxy.org/download/2.3/doc/proxy-protocol.txt
+const (
+	proxyProtocolV1MaxLength = 107 // maximum length of a v1 header including CRLF
+	proxyProtocolV2Length    = 16  // length of the fixed v2 header part
+)
+
+var (
+	proxyProtocolV1Signature = []byte("PROXY ")
+	proxyProtocolV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+	errProxyProtocolInvalid = errors.New("proxy protocol: invalid header")
+	errProxyProtocolTLS     = errors.New("proxy protocol: the listener must not be a tls listener, " +
+		"the PROXY header is sent before the tls handshake")
+)
+
+// proxyProtocolListener decodes the PROXY protocol header of accepted connections
+type proxyProtocolListener struct {
+	net.Listener
+}
+
+func newProxyProtocolListener(ln net.Listener) net.Listener {
+	return &proxyProtocolListener{Listener: ln}
+}
+
+// isTLSListener reports whether the listener was created by tls.NewListener or tls.Listen
+func isTLSListener(ln net.Listener) bool {
+	return reflect.TypeOf(ln).String() == "*tls.listener"
+}
+
+// Accept waits for and returns the next connection to the listener.
+// The PROXY header is read on the first use of the connection, so
+// a slow client does not block the accept loop.
+func (ln *proxyProtocolListener) Accept() (net.Conn, error) {
+	conn, err := ln.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &proxyProtocolConn{Conn: conn, reader: bufio.NewReader(conn)}, nil
+}
+
+// proxyProtocolConn is a net.Conn that reports the addresses of the PROXY header
+type proxyProtocolConn struct {
+	net.Conn
+	reader     *bufio.Reader
+	once       sync.Once
+	err        error
+	remoteAddr net.Addr
+	localAddr  net.Addr
+}
+
+// Read reads data from the connection after the PROXY header
+func (c *proxyProtocolConn) Read(b []byte) (int, error) {
+	c.once.Do(c.readHeader)
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.reader.Read(b)
+}
+
+// RemoteAddr returns the source address of the PROXY header
+// or the remote address of the connection if no header was sent
+func (c *proxyProtocolConn) RemoteAddr() net.Addr {
+	c.once.Do(c.readHeader)
+	if c.remoteAddr != nil {
+		return c.remoteAddr
+	}
+	return c.Conn.RemoteAddr()
+}
+
+// LocalAddr returns the destination address of the PROXY header
+// or the local address of the connection if no header was sent
+func (c *proxyProtocolConn) LocalAddr() net.Addr {
+	c.once.Do(c.readHeader)
+	if c.localAddr != nil {
+		return c.localAddr
+	}
+	return c.Conn.LocalAddr()
+}
+
+// readHeader reads the PROXY header, connections without header are passed through
+func (c *proxyProtocolConn) readHeader() {
+	// Both signatures have a distinct prefix of 5 bytes
+	prefix, err := c.reader.Peek(5)
+	if err != nil {
+		if err != io.EOF {
+			c.err = err
+		}
+		return
+	}
+	if bytes.Equal(prefix, proxyProtocolV1Signature[:5]) {
+		c.remoteAddr, c.localAddr, c.err = readProxyProtocolV1(c.reader)
+	} else if bytes.Equal(prefix, proxyProtocolV2Signature[:5]) {
+		c.remoteAddr, c.localAddr, c.err = readProxyProtocolV2(c.reader)
+	}
+}
+
+// readProxyProtocolV1 parses a human-readable header
+//  PROXY TCP4 192.168.0.1 192.168.0.11 56324 443\r\n
+func readProxyProtocolV1(r *bufio.Reader) (src, dst net.Addr, err error) {
+	var line []byte
+	for len(line) < proxyProtocolV1MaxLength {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, nil, err
+		}
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+	}
+	if !bytes.HasPrefix(line, proxyProtocolV1Signature) || !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, nil, errProxyProtocolInvalid
+	}
+	fields := strings.Split(string(line[len(proxyProtocolV1Signature):len(line)-2]), " ")
+	switch fields[0] {
+	case "UNKNOWN":
+		// The addresses of the connection are used
+		return nil, nil, nil
+	case "TCP4", "TCP6":
+		if len(fields) != 5 {
+			return nil, nil, errProxyProtocolInvalid
+		}
+	default:
+		return nil, nil, errProxyProtocolInvalid
+	}
+	srcIP, dstIP := net.ParseIP(fields[1]), net.ParseIP(fields[2])
+	if srcIP == nil || dstIP == nil {
+		return nil, nil, errProxyProtocolInvalid
+	}
+	srcPort, err := strconv.ParseUint(fields[3], 10, 16)
+	if err != nil {
+		return nil, nil, errProxyProtocolInvalid
+	}
+	dstPort, err := strconv.ParseUint(fields[4], 10, 16)
+	if err != nil {
+		return nil, nil, errProxyProtocolInvalid
+	}
+	return &net.TCPAddr{IP: srcIP, Port: int(srcPort)}, &net.TCPAddr{IP: dstIP, Port: int(dstPort)}, nil
+}
+
+// readProxyProtocolV2 parses a binary header
+func readProxyProtocolV2(r *bufio.Reader) (src, dst net.Addr, err error) {
+	header := make([]byte, proxyProtocolV2Length)
+	if _, err = io.ReadFull(r, header); err != nil {
+		return nil, nil, err
+	}
+	if !bytes.Equal(header[:12], proxyProtocolV2Signature) || header[12]>>4 != 2 {
+		return nil, nil, errProxyProtocolInvalid
+	}
+	payload := make([]byte, binary.BigEndian.Uint16(header[14:16]))
+	if _, err = io.ReadFull(r, payload); err != nil {
+		return nil, nil, err
+	}
+	switch header[12] & 0x0f {
+	case 0x00:
+		// LOCAL command, e.g. health checks of the proxy
+		return nil, nil, nil
+	case 0x01:
+		// PROXY command
+	default:
+		return nil, nil, errProxyProtocolInvalid
+	}
+	// Only IPv4 and IPv6 carry addresses we can use,
+	// unix sockets are served with the connection addresses
+	var ipLength int
+	switch header[13] >> 4 {
+	case 0x1:
+		ipLength = net.IPv4len
+	case 0x2:
+		ipLength = net.IPv6len
+	default:
+		return nil, nil, nil
+	}
+	if len(payload) < 2*ipLength+4 {
+		return nil, nil, errProxyProtocolInvalid
+	}
+	srcIP := net.IP(payload[:ipLength])
+	dstIP := net.IP(payload[ipLength : 2*ipLength])
+	srcPort := int(binary.BigEndian.Uint16(payload[2*ipLength:]))
+	dstPort := int(binary.BigEndian.Uint16(payload[2*ipLength+2:]))
+	return &net.TCPAddr{IP: srcIP, Port: srcPort}, &net.TCPAddr{IP: dstIP, Port: dstPort}, nil
+}
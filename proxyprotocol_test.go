@@ -0,0 +1,107 @@
+// ⚡️ Fiber is an Express inspired web framework written in Go with ☕️
+// 🤖 Github Repository: https://github.com/gofiber/fiber
+// 📌 API Documentation: https://docs.gofiber.io
+
+package fiber
+
+import (
+	"bufio"
+	"crypto/tls"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+func testProxyProtocolRequest(t *testing.T, ln *fasthttputil.InmemoryListener, header string) *http.Response {
+	conn, err := ln.Dial()
+	utils.AssertEqual(t, nil, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(header + "GET / HTTP/1.1\r\nHost: example.com\r\n\r\n"))
+	utils.AssertEqual(t, nil, err)
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	utils.AssertEqual(t, nil, err)
+	return resp
+}
+
+// go test -run Test_App_ProxyProtocol
+func Test_App_ProxyProtocol(t *testing.T) {
+	app := New(Config{
+		DisableStartupMessage: true,
+		EnableProxyProtocol:   true,
+	})
+	app.Get("/", func(c *Ctx) error {
+		return c.SendString(c.IP())
+	})
+
+	ln := fasthttputil.NewInmemoryListener()
+	go func() {
+		utils.AssertEqual(t, nil, app.Listener(ln))
+	}()
+	defer func() {
+		utils.AssertEqual(t, nil, app.Shutdown())
+	}()
+
+	testCases := []struct {
+		header string
+		ip     string
+	}{
+		{"PROXY TCP4 203.0.113.7 10.0.0.1 56324 443\r\n", "203.0.113.7"},
+		{"PROXY TCP6 2001:db8::1 2001:db8::2 56324 443\r\n", "2001:db8::1"},
+		{"\r\n\r\n\x00\r\nQUIT\n\x21\x11\x00\x0c\xcb\x00\x71\x08\x0a\x00\x00\x01\xdc\x04\x01\xbb", "203.0.113.8"},
+		// the in-memory listener has no ip address
+		{"PROXY UNKNOWN\r\n", "0.0.0.0"},
+		{"", "0.0.0.0"},
+	}
+	for _, tc := range testCases {
+		resp := testProxyProtocolRequest(t, ln, tc.header)
+		utils.AssertEqual(t, StatusOK, resp.StatusCode, tc.header)
+		body, err := ioutil.ReadAll(resp.Body)
+		utils.AssertEqual(t, nil, err)
+		utils.AssertEqual(t, tc.ip, string(body), tc.header)
+	}
+}
+
+// go test -run Test_App_ProxyProtocol_TLS
+func Test_App_ProxyProtocol_TLS(t *testing.T) {
+	t.Parallel()
+	app := New(Config{
+		DisableStartupMessage: true,
+		EnableProxyProtocol:   true,
+	})
+	ln := tls.NewListener(fasthttputil.NewInmemoryListener(), &tls.Config{})
+	defer ln.Close()
+	utils.AssertEqual(t, errProxyProtocolTLS, app.Listener(ln))
+	utils.AssertEqual(t, false, isTLSListener(fasthttputil.NewInmemoryListener()))
+}
+
+// go test -run Test_ProxyProtocol_Invalid
+func Test_ProxyProtocol_Invalid(t *testing.T) {
+	t.Parallel()
+	testCases := []string{
+		"PROXY TCP4 203.0.113.7 10.0.0.1 56324\r\n",
+		"PROXY TCP4 invalid 10.0.0.1 56324 443\r\n",
+		"PROXY TCP4 203.0.113.7 10.0.0.1 56324 99999\r\n",
+		"PROXY UDP4 203.0.113.7 10.0.0.1 56324 443\r\n",
+		"PROXY TCP4 " + strings.Repeat("1", 120) + "\r\n",
+		"\r\n\r\n\x00\r\nQUIT\n\x11\x11\x00\x00",
+		"\r\n\r\n\x00\r\nQUIT\n\x21\x11\x00\x02\x00\x00",
+	}
+	for _, header := range testCases {
+		client, server := net.Pipe()
+		go func(header string) {
+			_, _ = client.Write([]byte(header))
+			_ = client.Close()
+		}(header)
+		conn := &proxyProtocolConn{Conn: server, reader: bufio.NewReader(server)}
+		_, err := conn.Read(make([]byte, 1))
+		utils.AssertEqual(t, true, err != nil, header)
+		_ = server.Close()
+	}
+}
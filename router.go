@@ -9,6 +9,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/gofiber/fiber/v2/utils"
@@ -36,23 +37,42 @@ type Router interface {
 	Group(prefix string, handlers ...Handler) Router
 
 	Mount(prefix string, fiber *App) Router
+
+	Set(key string, value interface{}) Router
+
+	Name(name string) Router
+
+	Timeout(timeout time.Duration) Router
+	Limit(max int, duration time.Duration) Router
+
+	Describe(spec OpenAPISpec) Router
+
+	ErrorHandler(handler ErrorHandler) Router
 }
 
 // Route is a struct that holds all metadata for each registered handler
 type Route struct {
 	// Data for routing
-	pos         int         // Position in stack -> important for the sort of the matched routes
-	use         bool        // USE matches path prefixes
-	star        bool        // Path equals '*'
-	root        bool        // Path equals '/'
-	path        string      // Prettified path
-	routeParser routeParser // Parameter parser
+	pos         int          // Position in stack -> important for the sort of the matched routes
+	use         bool         // USE matches path prefixes
+	star        bool         // Path equals '*'
+	root        bool         // Path equals '/'
+	path        string       // Prettified path
+	routeParser routeParser  // Parameter parser
+	openAPI     *OpenAPISpec // Description of the route, set with Describe
+	autoHead    bool         // HEAD route registered for a GET route
+	group       *Group       // Group of the route, provides the error handler
+	latest      int          // Index of the first handler of the latest registration, see App.wrapLatestRoutes
 
 	// Public fields
 	Method   string    `json:"method"` // HTTP method
+	Name     string    `json:"name"`   // Route name
 	Path     string    `json:"path"`   // Original registered route path
 	Params   []string  `json:"params"` // Case sensitive param keys
 	Handlers []Handler `json:"-"`      // Ctx handlers
+
+	// Meta holds arbitrary data of the route, e.g. tags or auth scopes
+	Meta map[string]interface{} `json:"meta,omitempty"`
 }
 
 func (r *Route) match(path, original string, params *[maxParams]string) (match bool) {
@@ -127,19 +147,33 @@ func (app *App) next(c *Ctx) (match bool, err error) {
 		return match, err // Stop scanning the stack
 	}
 
-	// If c.Next() does not match, return 404
-	_ = c.SendStatus(StatusNotFound)
-	_ = c.SendString("Cannot " + c.method + " " + c.pathOriginal)
-
 	// If no match, scan stack again if other methods match the request
 	// Moved from app.handler because middleware may break the route chain
 	if !c.matched && methodExist(c) {
 		err = ErrMethodNotAllowed
+	} else if !c.matched && c.mountDepth > 0 && app.config.NotFoundHandler == nil {
+		// Continue in the route stack of the parent app, see app.mount
+		c.unmatched = true
+		return
+	}
+
+	// If c.Next() does not match, return 404
+	_ = c.SendStatus(StatusNotFound)
+	_ = c.SendString("Cannot " + c.method + " " + c.pathOriginal)
+
+	if err == nil && app.config.NotFoundHandler != nil {
+		c.fasthttp.Response.ResetBody()
+		err = app.config.NotFoundHandler(c)
+	} else if err == nil {
+		// The built-in response is plain text regardless of the DefaultContentType
+		c.fasthttp.Response.Header.SetContentType(MIMETextPlainCharsetUTF8)
 	}
 	return
 }
 
 func (app *App) handler(rctx *fasthttp.RequestCtx) {
+	atomic.AddInt32(&app.inFlight, 1)
+
 	// Acquire Ctx with fasthttp request from pool
 	c := app.AcquireCtx(rctx)
 
@@ -147,15 +181,29 @@ func (app *App) handler(rctx *fasthttp.RequestCtx) {
 	if c.methodINT == -1 {
 		_ = c.Status(StatusBadRequest).SendString("Invalid http method")
 		app.ReleaseCtx(c)
+		atomic.AddInt32(&app.inFlight, -1)
 		return
 	}
 
 	// Find match in stack
-	match, err := app.next(c)
+	var match bool
+	var err error
+	if app.config.DisablePanicRecovery {
+		match, err = app.next(c)
+	} else {
+		match, err = app.nextRecover(c)
+	}
+	// Set default content type if configured, error responses without one are plain text
+	setDefaultContentType := app.config.DefaultContentType != "" && !app.config.DisableDefaultContentType
 	if err != nil {
-		if catch := c.app.config.ErrorHandler(c, err); catch != nil {
+		if catch := c.app.errorHandler(c)(c, err); catch != nil {
 			_ = c.SendStatus(StatusInternalServerError)
 		}
+		if setDefaultContentType && len(c.fasthttp.Response.Header.ContentType()) == 0 {
+			c.fasthttp.Response.Header.SetContentType(MIMETextPlainCharsetUTF8)
+		}
+	} else if setDefaultContentType && len(c.fasthttp.Response.Header.ContentType()) == 0 {
+		c.fasthttp.Response.Header.SetContentType(app.config.DefaultContentType)
 	}
 	// Generate ETag if enabled
 	if match && app.config.ETag {
@@ -163,49 +211,95 @@ func (app *App) handler(rctx *fasthttp.RequestCtx) {
 	}
 	// Release Ctx
 	app.ReleaseCtx(c)
+	atomic.AddInt32(&app.inFlight, -1)
 }
 
-func (app *App) addPrefixToRoute(prefix string, route *Route) *Route {
-	prefixedPath := getGroupPath(prefix, route.Path)
-	prettyPath := prefixedPath
-	// Case sensitive routing, all to lowercase
-	if !app.config.CaseSensitive {
-		prettyPath = utils.ToLower(prettyPath)
-	}
-	// Strict routing, remove trailing slashes
-	if !app.config.StrictRouting && len(prettyPath) > 1 {
-		prettyPath = utils.TrimRight(prettyPath, '/')
+// errorHandler returns the error handler of the group of the matched route,
+// or the error handler of the app
+func (app *App) errorHandler(c *Ctx) ErrorHandler {
+	if c.route != nil {
+		for grp := c.route.group; grp != nil; grp = grp.parent {
+			if grp.errorHandler != nil {
+				return grp.errorHandler
+			}
+		}
 	}
+	return app.config.ErrorHandler
+}
 
-	route.Path = prefixedPath
-	route.path = prettyPath
-	route.routeParser = parseRoute(prettyPath)
-	route.root = false
-	route.star = false
-
-	return route
+// nextRecover calls next and converts a panic of a handler into an error
+func (app *App) nextRecover(c *Ctx) (match bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			var ok bool
+			if err, ok = r.(error); !ok {
+				err = fmt.Errorf("%v", r)
+			}
+		}
+	}()
+	return app.next(c)
 }
 
-func (app *App) copyRoute(route *Route) *Route {
-	return &Route{
-		// Router booleans
-		use:  route.use,
-		star: route.star,
-		root: route.root,
+// mount returns the handler that forwards requests matching the prefix to the sub-app
+func (app *App) mount(prefix string, sub *App) Handler {
+	sub.parent = app
+	sub.mountPrefix = prefix
+	app.mounts = append(app.mounts, sub)
+	// amount of path segments that will be stripped for the sub-app
+	segments := strings.Count(utils.TrimRight(prefix, '/'), "/")
+	return func(c *Ctx) error {
+		// "/admin" must not match "/administrator"
+		route := c.route
+		if len(route.Params) == 0 && !route.root && len(c.path) > len(route.path) && c.path[len(route.path)] != '/' {
+			return c.Next()
+		}
+		// Save the state of the parent app
+		parent, indexRoute, indexHandler, matched := c.app, c.indexRoute, c.indexHandler, c.matched
+		pathOriginal, values := c.pathOriginal, c.values
 
-		// Path data
-		path:        route.path,
-		routeParser: route.routeParser,
-		Params:      route.Params,
+		// Continue in the route stack of the sub-app with the stripped path
+		c.app = sub
+		c.indexRoute = -1
+		c.indexHandler = 0
+		c.matched = false
+		c.pathOriginal = trimPathSegments(pathOriginal, segments)
+		c.pathBuffer = append(c.pathBuffer[0:0], c.pathOriginal...)
+		c.prettifyPath()
+
+		// Errors are processed by the error handler of the sub-app
+		c.mountDepth++
+		_, err := sub.next(c)
+		c.mountDepth--
+		unmatched := c.unmatched
+		c.unmatched = false
+		if err != nil {
+			if catch := sub.errorHandler(c)(c, err); catch != nil {
+				_ = c.SendStatus(StatusInternalServerError)
+			}
+		}
 
-		// Public data
-		Path:     route.path,
-		Method:   route.Method,
-		Handlers: route.Handlers,
+		// Restore the state of the parent app
+		c.app = parent
+		c.route = route
+		c.indexRoute = indexRoute
+		c.indexHandler = indexHandler
+		c.matched = matched || c.matched
+		c.values = values
+		c.pathOriginal = pathOriginal
+		c.pathBuffer = append(c.pathBuffer[0:0], pathOriginal...)
+		c.prettifyPath()
+
+		// The sub-app has no route for the request
+		if unmatched {
+			return c.Next()
+		}
+		return nil
 	}
 }
 
 func (app *App) register(method, pathRaw string, handlers ...Handler) Router {
+	// Metadata is set on the routes of this registration
+	app.latestRoutes = nil
 	// Uppercase HTTP methods
 	method = utils.ToUpper(method)
 	// Check if the HTTP method is valid unless it's USE
@@ -228,7 +322,7 @@ func (app *App) register(method, pathRaw string, handlers ...Handler) Router {
 	pathPretty := pathRaw
 	// Case sensitive routing, all to lowercase
 	if !app.config.CaseSensitive {
-		pathPretty = utils.ToLower(pathPretty)
+		pathPretty = toLowerRoute(pathPretty)
 	}
 	// Strict routing, remove trailing slashes
 	if !app.config.StrictRouting && len(pathPretty) > 1 {
@@ -275,13 +369,30 @@ func (app *App) register(method, pathRaw string, handlers ...Handler) Router {
 			app.addRoute(m, &r)
 		}
 	} else {
+		// GET routes also answer HEAD requests, unless the path has an explicit HEAD route
+		if method == MethodGet && !app.config.DisableHeadAutoRegister && !app.hasHeadRoute(route.Path) {
+			headRoute := route
+			headRoute.Method = MethodHead
+			headRoute.autoHead = true
+			app.addRoute(MethodHead, &headRoute)
+		}
 		// Add route to stack
 		app.addRoute(method, &route)
 	}
 	return app
 }
 
+// equalPrefix compares the path prefix with the prettified route prefix
+func (app *App) equalPrefix(path []byte, prefix string) bool {
+	if app.config.CaseSensitive {
+		return getString(path) == prefix
+	}
+	return utils.EqualsFold(path, getBytes(prefix))
+}
+
 func (app *App) registerStatic(prefix, root string, config ...Static) Router {
+	// Metadata is set on the routes of this registration
+	app.latestRoutes = nil
 	// For security we want to restrict to the current work directory.
 	if len(root) == 0 {
 		root = "."
@@ -298,6 +409,10 @@ func (app *App) registerStatic(prefix, root string, config ...Static) Router {
 	if !app.config.CaseSensitive {
 		prefix = utils.ToLower(prefix)
 	}
+	// Strict routing, remove trailing slashes like for all other routes
+	if !app.config.StrictRouting && len(prefix) > 1 {
+		prefix = utils.TrimRight(prefix, '/')
+	}
 	// Strip trailing slashes from the root path
 	if len(root) > 0 && root[len(root)-1] == '/' {
 		root = root[:len(root)-1]
@@ -326,7 +441,7 @@ func (app *App) registerStatic(prefix, root string, config ...Static) Router {
 		PathRewrite: func(fctx *fasthttp.RequestCtx) []byte {
 			path := fctx.Path()
 			if len(path) >= prefixLen {
-				if isStar && getString(path[0:prefixLen]) == prefix {
+				if isStar && app.equalPrefix(path[0:prefixLen], prefix) {
 					path = append(path[0:0], '/')
 				} else if len(path) > 0 && path[len(path)-1] != '/' {
 					path = append(path[prefixLen:], '/')
@@ -395,7 +510,10 @@ func (app *App) registerStatic(prefix, root string, config ...Static) Router {
 	// Add route to stack
 	app.addRoute(MethodGet, &route)
 	// Add HEAD route
-	app.addRoute(MethodHead, &route)
+	headRoute := route
+	headRoute.Method = MethodHead
+	headRoute.autoHead = true
+	app.addRoute(MethodHead, &headRoute)
 	return app
 }
 
@@ -403,11 +521,27 @@ func (app *App) addRoute(method string, route *Route) {
 	// Get unique HTTP method indentifier
 	m := methodInt(method)
 
+	// An explicit HEAD route replaces the HEAD route registered for a GET route
+	if method == MethodHead && !route.autoHead && !route.use {
+		for i, preRoute := range app.stack[m] {
+			if preRoute.autoHead && !preRoute.use && preRoute.Path == route.Path {
+				route.pos = preRoute.pos
+				route.Method = method
+				app.stack[m][i] = route
+				app.latestRoutes = append(app.latestRoutes, route)
+				app.buildTree()
+				return
+			}
+		}
+	}
+
 	// prevent identically route registration
 	l := len(app.stack[m])
 	if l > 0 && app.stack[m][l-1].Path == route.Path && route.use == app.stack[m][l-1].use {
 		preRoute := app.stack[m][l-1]
+		preRoute.latest = len(preRoute.Handlers)
 		preRoute.Handlers = append(preRoute.Handlers, route.Handlers...)
+		route = preRoute
 	} else {
 		// Increment global route position
 		app.mutex.Lock()
@@ -418,10 +552,21 @@ func (app *App) addRoute(method string, route *Route) {
 		// Add route to the stack
 		app.stack[m] = append(app.stack[m], route)
 	}
+	app.latestRoutes = append(app.latestRoutes, route)
 	// Build router tree
 	app.buildTree()
 }
 
+// hasHeadRoute reports whether an explicit HEAD route is registered for the path
+func (app *App) hasHeadRoute(path string) bool {
+	for _, route := range app.stack[methodInt(MethodHead)] {
+		if !route.autoHead && !route.use && route.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
 // buildTree build the prefix tree from the previously registered routes
 func (app *App) buildTree() *App {
 	// loop all the methods and stacks and create the prefix tree
@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gofiber/fiber/v2/utils"
@@ -227,6 +228,132 @@ func Test_Route_Match_Middleware_Root(t *testing.T) {
 	utils.AssertEqual(t, "middleware", getString(body))
 }
 
+func Test_Route_Match_Constraints(t *testing.T) {
+	app := New()
+
+	app.Get("/users/:id<int>", func(c *Ctx) error {
+		return c.SendString(c.Params("id"))
+	})
+	app.Get("/codes/:code<regex(^[A-Z]{3}$)>", func(c *Ctx) error {
+		return c.SendString(c.Params("code"))
+	})
+
+	resp, err := app.Test(httptest.NewRequest(MethodGet, "/users/12", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusOK, resp.StatusCode, "Status code")
+
+	body, err := ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, "12", getString(body))
+
+	resp, err = app.Test(httptest.NewRequest(MethodGet, "/users/abc", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusNotFound, resp.StatusCode, "Status code")
+
+	// regex arguments are not lowercased for case-insensitive routing
+	resp, err = app.Test(httptest.NewRequest(MethodGet, "/Codes/ABC", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusOK, resp.StatusCode, "Status code")
+
+	body, err = ioutil.ReadAll(resp.Body)
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, "ABC", getString(body))
+
+	resp, err = app.Test(httptest.NewRequest(MethodGet, "/codes/ABCD", nil))
+	utils.AssertEqual(t, nil, err, "app.Test(req)")
+	utils.AssertEqual(t, StatusNotFound, resp.StatusCode, "Status code")
+}
+
+func Test_Route_Match_CaseInsensitive(t *testing.T) {
+	testCases := []struct {
+		config Config
+		url    string
+		status int
+		body   string
+	}{
+		{Config{}, "/foo", StatusOK, "foo"},
+		{Config{}, "/Foo", StatusOK, "foo"},
+		{Config{}, "/FOO/", StatusOK, "foo"},
+		{Config{}, "/Users/JohnDoe", StatusOK, "JohnDoe"},
+		{Config{}, "/users/JohnDoe/", StatusOK, "JohnDoe"},
+		{Config{}, "/Static/anything", StatusOK, "gofiber.io/support"},
+		{Config{}, "/static/", StatusOK, "gofiber.io/support"},
+		{Config{StrictRouting: true}, "/Foo", StatusOK, "foo"},
+		{Config{StrictRouting: true}, "/Foo/", StatusNotFound, ""},
+		{Config{StrictRouting: true}, "/USERS/JohnDoe", StatusOK, "JohnDoe"},
+		{Config{StrictRouting: true}, "/STATIC/anything", StatusOK, "gofiber.io/support"},
+		{Config{CaseSensitive: true}, "/foo/", StatusOK, "foo"},
+		{Config{CaseSensitive: true}, "/Foo", StatusNotFound, ""},
+		{Config{CaseSensitive: true}, "/users/JohnDoe", StatusOK, "JohnDoe"},
+		{Config{CaseSensitive: true}, "/static/anything", StatusNotFound, ""},
+		{Config{CaseSensitive: true, StrictRouting: true}, "/foo/", StatusNotFound, ""},
+	}
+	for _, tc := range testCases {
+		app := New(tc.config)
+		app.Get("/foo", func(c *Ctx) error {
+			return c.SendString("foo")
+		})
+		app.Get("/users/:name", func(c *Ctx) error {
+			return c.SendString(c.Params("name"))
+		})
+		app.Static("/Static*", "./.github/FUNDING.yml")
+
+		msg := fmt.Sprintf("%s %+v", tc.url, tc.config)
+		resp, err := app.Test(httptest.NewRequest(MethodGet, tc.url, nil))
+		utils.AssertEqual(t, nil, err, "app.Test(req)")
+		utils.AssertEqual(t, tc.status, resp.StatusCode, msg)
+		if tc.status == StatusOK {
+			body, err := ioutil.ReadAll(resp.Body)
+			utils.AssertEqual(t, nil, err, "app.Test(req)")
+			utils.AssertEqual(t, true, strings.Contains(getString(body), tc.body), msg)
+		}
+	}
+}
+
+// go test -run Test_Route_Meta
+func Test_Route_Meta(t *testing.T) {
+	app := New()
+
+	// enforces the scope of the matched route
+	app.Use(func(c *Ctx) error {
+		if err := c.Next(); err != nil {
+			return err
+		}
+		if scope, ok := c.Route().Meta["scope"]; ok {
+			c.Set("X-Scope", scope.(string))
+		}
+		return nil
+	})
+	handler := func(c *Ctx) error {
+		return c.SendString(fmt.Sprintf("%v", c.Route().Meta["tags"]))
+	}
+	app.Get("/admin", handler).Set("scope", "admin").Set("tags", []string{"internal"})
+	app.Get("/public", handler)
+	app.Group("/api").All("/users", handler).Set("scope", "users")
+
+	testCases := []struct {
+		method string
+		url    string
+		scope  string
+		body   string
+	}{
+		{MethodGet, "/admin", "admin", "[internal]"},
+		{MethodHead, "/admin", "admin", ""},
+		{MethodGet, "/public", "", "<nil>"},
+		{MethodGet, "/api/users", "users", "<nil>"},
+		{MethodPost, "/api/users", "users", "<nil>"},
+	}
+	for _, tc := range testCases {
+		resp, err := app.Test(httptest.NewRequest(tc.method, tc.url, nil))
+		utils.AssertEqual(t, nil, err, "app.Test(req)")
+		utils.AssertEqual(t, StatusOK, resp.StatusCode, tc.url)
+		utils.AssertEqual(t, tc.scope, resp.Header.Get("X-Scope"), tc.method+" "+tc.url)
+		body, err := ioutil.ReadAll(resp.Body)
+		utils.AssertEqual(t, nil, err, "app.Test(req)")
+		utils.AssertEqual(t, tc.body, string(body), tc.method+" "+tc.url)
+	}
+}
+
 func Test_Router_Register_Missing_Handler(t *testing.T) {
 	app := New()
 	defer func() {